@@ -0,0 +1,216 @@
+package mcpserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolRegistry keeps the API tools of every MCP server of the process so they
+// can be switched off and on again at runtime.
+type toolRegistry struct {
+	mu       sync.Mutex
+	servers  map[*server.MCPServer]map[string]server.ServerTool
+	disabled map[*server.MCPServer]map[string]bool
+}
+
+var apiTools = &toolRegistry{
+	servers:  map[*server.MCPServer]map[string]server.ServerTool{},
+	disabled: map[*server.MCPServer]map[string]bool{},
+}
+
+// add registers an API tool on mcpServer.
+func (r *toolRegistry) add(mcpServer *server.MCPServer, tool server.ServerTool) {
+	r.mu.Lock()
+	if r.servers[mcpServer] == nil {
+		r.servers[mcpServer] = map[string]server.ServerTool{}
+		r.disabled[mcpServer] = map[string]bool{}
+	}
+	r.servers[mcpServer][tool.Tool.Name] = tool
+	// a tool registered again, as on a spec reload, stays disabled
+	disabled := r.disabled[mcpServer][tool.Tool.Name]
+	r.mu.Unlock()
+	if !disabled {
+		mcpServer.AddTools(tool)
+	}
+}
+
+// remove unregisters API tools of mcpServer.
+func (r *toolRegistry) remove(mcpServer *server.MCPServer, names []string) {
+	r.mu.Lock()
+	for _, name := range names {
+		delete(r.servers[mcpServer], name)
+		delete(r.disabled[mcpServer], name)
+	}
+	r.mu.Unlock()
+	mcpServer.DeleteTools(names...)
+}
+
+// lookup returns the API tool name of mcpServer, unless it is disabled.
+func (r *toolRegistry) lookup(mcpServer *server.MCPServer, name string) (server.ServerTool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tool, ok := r.servers[mcpServer][name]
+	return tool, ok && !r.disabled[mcpServer][name]
+}
+
+// toolSelection names the tools an admin request applies to.
+type toolSelection struct {
+	Tools   []string `json:"tools"`
+	Pattern string   `json:"pattern"` // regex matched against tool names
+}
+
+// setEnabled switches the selected tools on or off and returns the names of
+// the tools that changed. Connected clients get notifications/tools/list_changed.
+func (r *toolRegistry) setEnabled(selection toolSelection, enabled bool) ([]string, error) {
+	var pattern *regexp.Regexp
+	if selection.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(selection.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := []string{}
+	for mcpServer, tools := range r.servers {
+		names := []string{}
+		for name := range tools {
+			selected := slices.Contains(selection.Tools, name) || (pattern != nil && pattern.MatchString(name))
+			if selected && r.disabled[mcpServer][name] == enabled {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		if enabled {
+			entries := make([]server.ServerTool, len(names))
+			for i, name := range names {
+				entries[i] = tools[name]
+				delete(r.disabled[mcpServer], name)
+			}
+			mcpServer.AddTools(entries...)
+		} else {
+			for _, name := range names {
+				r.disabled[mcpServer][name] = true
+			}
+			mcpServer.DeleteTools(names...)
+		}
+		changed = append(changed, names...)
+	}
+	sort.Strings(changed)
+	return slices.Compact(changed), nil
+}
+
+// status lists the API tools and whether they are enabled.
+func (r *toolRegistry) status() map[string]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := map[string]bool{}
+	for mcpServer, tools := range r.servers {
+		for name := range tools {
+			status[name] = !r.disabled[mcpServer][name]
+		}
+	}
+	return status
+}
+
+// newAdminHandler serves the admin API on /admin, authenticated with the
+// bearer token:
+//
+//	GET  /admin/tools          lists the tools and whether they are enabled
+//	POST /admin/tools/disable  {"tools": [...], "pattern": "regex"}
+//	POST /admin/tools/enable   {"tools": [...], "pattern": "regex"}
+//	GET  /admin/latency        p95 latency of the tools against their SLO
+//	GET  /admin/sessions       lists the connected sessions and their elevation
+//	POST /admin/sessions/elevate  {"session": "id", "minutes": 15, "reason": "..."}
+//	POST /admin/sessions/revoke   {"session": "id"}
+func newAdminHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/tools", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, apiTools.status())
+	})
+	mux.HandleFunc("GET /admin/latency", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, toolLatencies.stats())
+	})
+	mux.HandleFunc("GET /admin/sessions", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, elevatedTools.list())
+	})
+	mux.HandleFunc("POST /admin/sessions/elevate", func(w http.ResponseWriter, r *http.Request) {
+		var elevation struct {
+			Session string `json:"session"`
+			Minutes int    `json:"minutes"`
+			Reason  string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&elevation); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+			return
+		}
+		if elevation.Session == "" || elevation.Minutes <= 0 {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": "session and a positive number of minutes are required"})
+			return
+		}
+		grant, err := elevatedTools.grant(elevation.Session, time.Duration(elevation.Minutes)*time.Minute, elevation.Reason)
+		if err != nil {
+			writeAdminJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, grant)
+	})
+	mux.HandleFunc("POST /admin/sessions/revoke", func(w http.ResponseWriter, r *http.Request) {
+		var revocation struct {
+			Session string `json:"session"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&revocation); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+			return
+		}
+		if !elevatedTools.revoke(revocation.Session, "revoked by an admin") {
+			writeAdminJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("session %s has no elevation", revocation.Session)})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]string{"revoked": revocation.Session})
+	})
+	for _, action := range []string{"enable", "disable"} {
+		enabled := action == "enable"
+		mux.HandleFunc("POST /admin/tools/"+action, func(w http.ResponseWriter, r *http.Request) {
+			var selection toolSelection
+			if err := json.NewDecoder(r.Body).Decode(&selection); err != nil {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+				return
+			}
+			changed, err := apiTools.setEnabled(selection, enabled)
+			if err != nil {
+				writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			log.Printf("Admin %sd tools: %s", action, strings.Join(changed, ", "))
+			writeAdminJSON(w, http.StatusOK, map[string][]string{action + "d": changed})
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeAdminJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid admin token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,96 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var unsafeArgumentChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// parseParamAliases parses wireName=argument pairs, separated by commas.
+func parseParamAliases(aliases string) map[string]string {
+	parsed := map[string]string{}
+	for _, pair := range splitList(aliases) {
+		wire, alias, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(wire) == "" || strings.TrimSpace(alias) == "" {
+			fmt.Printf("Invalid parameter alias: %s\n", pair)
+			continue
+		}
+		parsed[strings.TrimSpace(wire)] = strings.TrimSpace(alias)
+	}
+	return parsed
+}
+
+// sanitizeArgumentName turns a wire name such as X-Correlation-ID or
+// filter[created_at][gte] into X_Correlation_ID or filter_created_at_gte.
+func sanitizeArgumentName(name string) string {
+	if sanitized := strings.Trim(unsafeArgumentChars.ReplaceAllString(name, "_"), "_"); sanitized != "" {
+		return sanitized
+	}
+	return name
+}
+
+// argumentAliases maps the clean argument names of one tool back to the
+// exact parameter names sent on the wire.
+type argumentAliases struct {
+	overrides map[string]string // wire name => argument name
+	wireNames map[string]string // argument name => wire name
+}
+
+func newArgumentAliases(overrides map[string]string) *argumentAliases {
+	return &argumentAliases{overrides: overrides, wireNames: map[string]string{}}
+}
+
+// name returns the argument name to expose for a wire name.
+func (a *argumentAliases) name(wire string) string {
+	alias, ok := a.overrides[wire]
+	if !ok {
+		alias = sanitizeArgumentName(wire)
+	}
+	if alias == wire {
+		return wire
+	}
+	if other, taken := a.wireNames[alias]; taken && other != wire {
+		// two wire names sanitize to the same argument, keep the second one as is
+		return wire
+	}
+	a.wireNames[alias] = wire
+	return alias
+}
+
+// argument returns the argument name a wire name is exposed as, without
+// registering a new alias, so it is safe to use while serving calls.
+func (a *argumentAliases) argument(wire string) string {
+	if a == nil {
+		return wire
+	}
+	for alias, name := range a.wireNames {
+		if name == wire {
+			return alias
+		}
+	}
+	return wire
+}
+
+// wrap renames the aliased arguments back to their wire names before calling handler.
+func (a *argumentAliases) wrap(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if len(a.wireNames) == 0 {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := make(map[string]interface{}, len(request.GetArguments()))
+		for name, value := range request.GetArguments() {
+			if wire, ok := a.wireNames[name]; ok {
+				name = wire
+			}
+			args[name] = value
+		}
+		request.Params.Arguments = args
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,152 @@
+package mcpserver
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-ntlmssp"
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// httpDoer sends outbound API requests. Connection-level authentication
+// schemes wrap the plain *http.Client behind it.
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// newAuthClient wraps client with the authentication scheme that needs to take
+// part in the HTTP exchange itself rather than just set a header.
+func newAuthClient(httpClient *http.Client, apiCfg models.ApiConfig) (httpDoer, error) {
+	switch {
+	case hasSecurity(apiCfg.Security, "negotiate"):
+		return newNegotiateClient(httpClient, apiCfg.NegotiateSpn)
+	case hasSecurity(apiCfg.Security, "ntlm"):
+		return newNTLMClient(httpClient, apiCfg.NtlmAuth)
+	}
+	return httpClient, nil
+}
+
+// negotiateCredentials keeps the Kerberos config and the client built from the
+// host's credential cache, loaded once rather than on every call. The client is
+// rebuilt when the cache file changes, such as after kinit renewed the tickets,
+// or when its ticket expired.
+type negotiateCredentials struct {
+	mu         sync.Mutex
+	krb5Conf   *config.Config
+	ccachePath string
+	modTime    time.Time
+	expires    time.Time
+	krb5Client *client.Client
+}
+
+var kerberos = &negotiateCredentials{}
+
+// load returns the Kerberos client, reloading the credential cache when needed.
+func (k *negotiateCredentials) load() (*client.Client, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.krb5Conf == nil {
+		confPath := os.Getenv("KRB5_CONFIG")
+		if confPath == "" {
+			confPath = "/etc/krb5.conf"
+		}
+		krb5Conf, err := config.Load(confPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kerberos config %s: %v", confPath, err)
+		}
+		k.krb5Conf = krb5Conf
+	}
+	if k.ccachePath == "" {
+		ccachePath := strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:")
+		if ccachePath == "" {
+			u, err := user.Current()
+			if err != nil {
+				return nil, fmt.Errorf("failed to locate kerberos credential cache: %v", err)
+			}
+			ccachePath = "/tmp/krb5cc_" + u.Uid
+		}
+		k.ccachePath = ccachePath
+	}
+
+	info, err := os.Stat(k.ccachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kerberos credential cache %s: %v", k.ccachePath, err)
+	}
+	if k.krb5Client != nil && info.ModTime().Equal(k.modTime) && time.Now().Before(k.expires) {
+		return k.krb5Client, nil
+	}
+	ccache, err := credentials.LoadCCache(k.ccachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kerberos credential cache %s: %v", k.ccachePath, err)
+	}
+	krb5Client, err := client.NewFromCCache(ccache, k.krb5Conf, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kerberos client: %v", err)
+	}
+	k.krb5Client, k.modTime, k.expires = krb5Client, info.ModTime(), ticketExpiry(ccache)
+	return krb5Client, nil
+}
+
+// ticketExpiry returns when the ticket granting ticket of ccache expires, or
+// the earliest expiry of its tickets when it holds none.
+func ticketExpiry(ccache *credentials.CCache) time.Time {
+	var tgt, earliest time.Time
+	for _, entry := range ccache.GetEntries() {
+		if len(entry.Server.PrincipalName.NameString) > 0 && entry.Server.PrincipalName.NameString[0] == "krbtgt" && (tgt.IsZero() || entry.EndTime.Before(tgt)) {
+			tgt = entry.EndTime
+		}
+		if earliest.IsZero() || entry.EndTime.Before(earliest) {
+			earliest = entry.EndTime
+		}
+	}
+	if !tgt.IsZero() {
+		return tgt
+	}
+	return earliest
+}
+
+// newNegotiateClient returns a SPNEGO client authenticating with the tickets in
+// the host's Kerberos credential cache.
+func newNegotiateClient(httpClient *http.Client, spn string) (httpDoer, error) {
+	krb5Client, err := kerberos.load()
+	if err != nil {
+		return nil, err
+	}
+
+	// spnego.NewClient installs its own redirect handling, keep the caller's client untouched
+	cl := *httpClient
+	return spnego.NewClient(krb5Client, &cl, spn), nil
+}
+
+// ntlmClient authenticates each request with NTLM. The negotiator picks the
+// credentials up from the basic auth header and runs the challenge exchange.
+type ntlmClient struct {
+	client   *http.Client
+	user     string
+	password string
+}
+
+// newNTLMClient parses credentials in DOMAIN\user:password format.
+func newNTLMClient(httpClient *http.Client, ntlmAuth string) (httpDoer, error) {
+	user, password, ok := strings.Cut(ntlmAuth, ":")
+	if !ok || user == "" {
+		return nil, fmt.Errorf("ntlm credentials must be in DOMAIN\\user:password format")
+	}
+	cl := *httpClient
+	cl.Transport = ntlmssp.Negotiator{RoundTripper: http.DefaultTransport}
+	return &ntlmClient{client: &cl, user: user, password: password}, nil
+}
+
+func (c *ntlmClient) Do(req *http.Request) (*http.Response, error) {
+	req.SetBasicAuth(c.user, c.password)
+	return c.client.Do(req)
+}
@@ -0,0 +1,35 @@
+package mcpserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+func TestTicketExpiry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	entry := func(service string, end time.Time) *credentials.Credential {
+		cred := &credentials.Credential{EndTime: end}
+		cred.Server.Realm = "EXAMPLE.COM"
+		cred.Server.PrincipalName = types.NewPrincipalName(2, service)
+		return cred
+	}
+	tests := []struct {
+		name    string
+		entries []*credentials.Credential
+		want    time.Time
+	}{
+		{"no tickets", nil, time.Time{}},
+		{"ticket granting ticket", []*credentials.Credential{entry("HTTP/api.example.com", now.Add(time.Hour)), entry("krbtgt/EXAMPLE.COM", now.Add(8*time.Hour))}, now.Add(8 * time.Hour)},
+		{"service tickets only", []*credentials.Credential{entry("HTTP/a.example.com", now.Add(2*time.Hour)), entry("HTTP/b.example.com", now.Add(time.Hour))}, now.Add(time.Hour)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ticketExpiry(&credentials.CCache{Credentials: test.entries}); !got.Equal(test.want) {
+				t.Errorf("ticketExpiry = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package mcpserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bodySizeRule caps the request bodies of the operations on Path, of every
+// method when Method is empty.
+type bodySizeRule struct {
+	Method string
+	Path   string
+	Limit  int64
+}
+
+// parseBodySize parses a byte count with an optional KB, MB or GB suffix
+// (powers of 1024), e.g. 512, 64KB or 10MB.
+func parseBodySize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for suffix, factor := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(size, suffix) {
+			size, multiplier = strings.TrimSpace(strings.TrimSuffix(size, suffix)), factor
+			break
+		}
+	}
+	size = strings.TrimSuffix(size, "B")
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return value * multiplier, nil
+}
+
+// parseBodySizeRules parses rules in [METHOD ]path=size format, separated by
+// commas. A size of 0 lifts the global limit for the operation.
+func parseBodySizeRules(rules string) ([]bodySizeRule, error) {
+	parsed := []bodySizeRule{}
+	for _, rule := range splitList(rules) {
+		expr, size, found := strings.Cut(rule, "=")
+		fields := strings.Fields(expr)
+		var parsedRule bodySizeRule
+		switch {
+		case !found:
+			return nil, fmt.Errorf("body size rule %q has no size", rule)
+		case len(fields) == 1:
+			parsedRule.Path = fields[0]
+		case len(fields) == 2:
+			parsedRule.Method, parsedRule.Path = strings.ToLower(fields[0]), fields[1]
+		default:
+			return nil, fmt.Errorf("invalid body size rule %q", rule)
+		}
+		limit, err := parseBodySize(size)
+		if err != nil {
+			return nil, fmt.Errorf("body size rule %q: %v", rule, err)
+		}
+		parsedRule.Limit = limit
+		parsed = append(parsed, parsedRule)
+	}
+	return parsed, nil
+}
+
+// bodySizeLimit returns the body size limit of an operation, from the first
+// matching rule or the global limit, 0 when bodies of any size are sent.
+func bodySizeLimit(rules []bodySizeRule, path, method string, global int64) int64 {
+	for _, rule := range rules {
+		if rule.Path == path && (rule.Method == "" || strings.EqualFold(rule.Method, method)) {
+			return rule.Limit
+		}
+	}
+	return global
+}
+
+// checkBodySize refuses a request body larger than limit, telling the agent
+// how far over it is so it can trim the payload or split the call.
+func checkBodySize(size, limit int64) error {
+	if limit <= 0 || size <= limit {
+		return nil
+	}
+	return fmt.Errorf("request body is %d bytes, over the %d bytes limit of this operation by %d bytes; send less data, e.g. fewer items per call", size, limit, size-limit)
+}
@@ -0,0 +1,56 @@
+package mcpserver
+
+import "testing"
+
+func TestParseBodySize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"64KB", 64 << 10, false},
+		{"10mb", 10 << 20, false},
+		{" 2 GB ", 2 << 30, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"-1", 0, true},
+		{"1.5MB", 0, true},
+		{"10TB", 0, true},
+	}
+	for _, test := range tests {
+		got, err := parseBodySize(test.size)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseBodySize(%q) error = %v, want error %v", test.size, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseBodySize(%q) = %d, want %d", test.size, got, test.want)
+		}
+	}
+}
+
+func TestBodySizeLimit(t *testing.T) {
+	rules, err := parseBodySizeRules("POST /uploads=10MB, /bulk=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path, method string
+		want         int64
+	}{
+		{"/uploads", "post", 10 << 20},
+		{"/uploads", "put", 1 << 20},
+		{"/bulk", "put", 0},
+		{"/users", "post", 1 << 20},
+	}
+	for _, test := range tests {
+		if got := bodySizeLimit(rules, test.path, test.method, 1<<20); got != test.want {
+			t.Errorf("bodySizeLimit(%s %s) = %d, want %d", test.method, test.path, got, test.want)
+		}
+	}
+	if _, err := parseBodySizeRules("GET PUT /x=1KB"); err == nil {
+		t.Error("expected an error for a rule with two methods")
+	}
+}
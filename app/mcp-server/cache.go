@@ -0,0 +1,104 @@
+package mcpserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const maxCachedResponses = 1000
+
+// cachedResponse is a GET response kept with its validators for revalidation.
+type cachedResponse struct {
+	statusCode   int
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified string
+}
+
+// responseCache revalidates repeated GET requests with If-None-Match and
+// If-Modified-Since, and serves the cached body when the backend answers 304.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: map[string]*cachedResponse{}}
+}
+
+// cacheKey identifies a request by its URL and headers, so responses are never
+// shared between different credentials.
+func cacheKey(req *http.Request) string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if name != "If-None-Match" && name != "If-Modified-Since" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	hash.Write([]byte(req.URL.String()))
+	for _, name := range names {
+		hash.Write([]byte("\n" + name + ":" + strings.Join(req.Header.Values(name), ",")))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// prepare adds the validators of the cached response to req and returns its cache key.
+func (c *responseCache) prepare(req *http.Request) string {
+	key := cacheKey(req)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		if entry.etag != "" && req.Header.Get("If-None-Match") == "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+	return key
+}
+
+// update stores a response carrying validators, or turns a 304 back into the
+// cached response. It returns the body to use.
+func (c *responseCache) update(key string, resp *http.Response, body []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if resp.StatusCode == http.StatusNotModified {
+		if entry, ok := c.entries[key]; ok {
+			resp.StatusCode = entry.statusCode
+			resp.Header = entry.header.Clone()
+			return entry.body
+		}
+		return body
+	}
+	if resp.StatusCode != http.StatusOK {
+		return body
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if (etag == "" && lastModified == "") || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		delete(c.entries, key)
+		return body
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCachedResponses {
+		// drop an arbitrary entry to stay bounded
+		for old := range c.entries {
+			delete(c.entries, old)
+			break
+		}
+	}
+	c.entries[key] = &cachedResponse{
+		statusCode:   resp.StatusCode,
+		header:       resp.Header.Clone(),
+		body:         body,
+		etag:         etag,
+		lastModified: lastModified,
+	}
+	return body
+}
@@ -0,0 +1,134 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// requestIDArgument carries the JSON-RPC id of a tool call from the server hook
+// to the handler, which has no other way to learn it.
+const requestIDArgument = "__mcpRequestId"
+
+// inFlightCalls keeps the cancel function of every running tool call by session and request id.
+type inFlightCalls struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+var inFlight = &inFlightCalls{cancels: map[string]context.CancelFunc{}}
+
+// callKey identifies a call by session and request id. The hook gets the id
+// as an mcp.RequestId and notifications/cancelled as a decoded JSON value, both
+// are unwrapped and whole numbers formatted the same way so the keys match.
+func callKey(session string, id interface{}) string {
+	switch value := id.(type) {
+	case mcp.RequestId:
+		id = value.Value()
+	case *mcp.RequestId:
+		if value != nil {
+			id = value.Value()
+		}
+	}
+	switch value := id.(type) {
+	case float64:
+		if value == math.Trunc(value) {
+			id = int64(value)
+		}
+	case int:
+		id = int64(value)
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			id = n
+		}
+	}
+	return fmt.Sprintf("%s/%v", session, id)
+}
+
+func (c *inFlightCalls) add(key string, cancel context.CancelFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancels[key] = cancel
+}
+
+func (c *inFlightCalls) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cancels, key)
+}
+
+func (c *inFlightCalls) cancel(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cancel, ok := c.cancels[key]
+	if ok {
+		cancel()
+		delete(c.cancels, key)
+	}
+	return ok
+}
+
+// newMCPServer creates an MCP server whose tool calls are cancelled when the
+// client sends notifications/cancelled for them.
+func newMCPServer(name string) *server.MCPServer {
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		args := message.GetArguments()
+		if args == nil {
+			args = map[string]interface{}{}
+			message.Params.Arguments = args
+		}
+		args[requestIDArgument] = id
+	})
+	elevatedTools.addSessionHooks(hooks)
+	// tools can be switched off and on again through the admin API
+	mcpServer := server.NewMCPServer(name, "1.0.0", server.WithHooks(hooks), server.WithToolCapabilities(true))
+	// the server keeps hooks, the session hooks can still be added
+	sessionEnds.addSessionHooks(hooks, mcpServer)
+	mcpServer.AddNotificationHandler("notifications/cancelled", func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		id, ok := notification.Params.AdditionalFields["requestId"]
+		if !ok {
+			return
+		}
+		if inFlight.cancel(callKey(sessionID(ctx), id)) {
+			fmt.Printf("Cancelled tool call %v\n", id)
+		}
+	})
+	return mcpServer
+}
+
+// cancellable runs handler with a context that is cancelled along with the MCP request,
+// which aborts the upstream HTTP request.
+func cancellable(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, ok := request.GetArguments()[requestIDArgument]
+		if !ok {
+			return handler(ctx, request)
+		}
+		args := make(map[string]interface{}, len(request.GetArguments()))
+		for name, value := range request.GetArguments() {
+			if name != requestIDArgument {
+				args[name] = value
+			}
+		}
+		request.Params.Arguments = args
+
+		key := callKey(sessionID(ctx), id)
+		ctx, cancel := context.WithCancel(ctx)
+		inFlight.add(key, cancel)
+		defer func() {
+			inFlight.remove(key)
+			cancel()
+		}()
+		result, err := handler(ctx, request)
+		if ctx.Err() == context.Canceled {
+			return mcp.NewToolResultError("[Error] the call was cancelled"), nil
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,75 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCancelledNotificationCancelsInFlightCall(t *testing.T) {
+	mcpServer := newMCPServer("test")
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	mcpServer.AddTool(mcp.NewTool("slow"), cancellable(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+		return mcp.NewToolResultText("done"), nil
+	}))
+
+	done := make(chan mcp.JSONRPCMessage)
+	go func() {
+		done <- mcpServer.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"slow","arguments":{}}}`))
+	}()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tool handler was not called")
+	}
+
+	mcpServer.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":5,"reason":"test"}}`))
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler context was not cancelled")
+	}
+
+	response, ok := (<-done).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("expected a JSON-RPC response")
+	}
+	result, ok := response.Result.(mcp.CallToolResult)
+	if !ok || !result.IsError {
+		t.Fatalf("expected an error result, got %#v", response.Result)
+	}
+}
+
+func TestCallKey(t *testing.T) {
+	tests := []struct {
+		name string
+		id   interface{}
+		want string
+	}{
+		{"request id", mcp.NewRequestId(int64(5)), "s/5"},
+		{"request id pointer", func() *mcp.RequestId { id := mcp.NewRequestId(int64(5)); return &id }(), "s/5"},
+		{"decoded number", float64(5), "s/5"},
+		{"int", 5, "s/5"},
+		{"json number", json.Number("5"), "s/5"},
+		{"fraction", 5.5, "s/5.5"},
+		{"string request id", mcp.NewRequestId("abc"), "s/abc"},
+		{"string", "abc", "s/abc"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := callKey("s", test.id); got != test.want {
+				t.Errorf("callKey(%v) = %q, want %q", test.id, got, test.want)
+			}
+		})
+	}
+}
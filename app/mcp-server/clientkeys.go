@@ -0,0 +1,197 @@
+package mcpserver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const clientKeyPrefix = "smcp_"
+
+// ClientKey is an inbound API key of one client of a shared SSE server. Only
+// the SHA-256 of the key is stored, the key itself is shown once when issued.
+type ClientKey struct {
+	ID      string     `json:"id"`
+	Client  string     `json:"client"`
+	Hash    string     `json:"hash"`
+	Created time.Time  `json:"created"`
+	Revoked *time.Time `json:"revoked,omitempty"`
+}
+
+// readClientKeys reads the key file at path, a missing file holds no keys.
+func readClientKeys(path string) ([]ClientKey, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []ClientKey{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client keys: %v", err)
+	}
+	keys := []ClientKey{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid client key file %s: %v", path, err)
+	}
+	return keys, nil
+}
+
+func writeClientKeys(path string, keys []ClientKey) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write next to the file and rename, a running server never reads half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write client keys: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func hashClientKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// IssueClientKey adds a key for client to the key file at path and returns it.
+// The key cannot be recovered later, only revoked.
+func IssueClientKey(path, client string) (ClientKey, string, error) {
+	if strings.TrimSpace(client) == "" {
+		return ClientKey{}, "", fmt.Errorf("a client name is required")
+	}
+	keys, err := readClientKeys(path)
+	if err != nil {
+		return ClientKey{}, "", err
+	}
+	id, err := randomHex(4)
+	if err != nil {
+		return ClientKey{}, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return ClientKey{}, "", err
+	}
+	key := clientKeyPrefix + id + "_" + secret
+	issued := ClientKey{ID: id, Client: strings.TrimSpace(client), Hash: hashClientKey(key), Created: time.Now().UTC()}
+	if err := writeClientKeys(path, append(keys, issued)); err != nil {
+		return ClientKey{}, "", err
+	}
+	return issued, key, nil
+}
+
+// RevokeClientKeys revokes the key with the id, or every key of the client of
+// that name, and returns the revoked keys.
+func RevokeClientKeys(path, idOrClient string) ([]ClientKey, error) {
+	keys, err := readClientKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	revoked := []ClientKey{}
+	for i, key := range keys {
+		if key.Revoked == nil && (key.ID == idOrClient || key.Client == idOrClient) {
+			keys[i].Revoked = &now
+			revoked = append(revoked, keys[i])
+		}
+	}
+	if len(revoked) == 0 {
+		return nil, fmt.Errorf("no active key with id or client %s", idOrClient)
+	}
+	return revoked, writeClientKeys(path, keys)
+}
+
+// ListClientKeys returns the keys of the key file at path.
+func ListClientKeys(path string) ([]ClientKey, error) {
+	return readClientKeys(path)
+}
+
+// clientKeyStore checks the inbound keys of SSE requests against the key file.
+// The file is read again when it changes, so keys issued or revoked with the
+// keys command apply without a restart.
+type clientKeyStore struct {
+	path     string
+	mu       sync.Mutex
+	modified time.Time
+	keys     []ClientKey
+}
+
+func newClientKeyStore(path string) (*clientKeyStore, error) {
+	store := &clientKeyStore{path: path}
+	if err := store.reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *clientKeyStore) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var modified time.Time
+	if info != nil {
+		modified = info.ModTime()
+	}
+	if s.keys != nil && modified.Equal(s.modified) {
+		return nil
+	}
+	keys, err := readClientKeys(s.path)
+	if err != nil {
+		return err
+	}
+	s.keys, s.modified = keys, modified
+	return nil
+}
+
+// authenticate returns the active key matching key.
+func (s *clientKeyStore) authenticate(key string) (ClientKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		// keep serving with the keys read last
+		log.Printf("Failed to reload client keys: %v", err)
+	}
+	hash := hashClientKey(key)
+	for _, candidate := range s.keys {
+		if candidate.Revoked == nil && subtle.ConstantTimeCompare([]byte(candidate.Hash), []byte(hash)) == 1 {
+			return candidate, true
+		}
+	}
+	return ClientKey{}, false
+}
+
+// require only lets requests carrying an active key through, in the
+// Authorization header as a bearer token or in X-API-Key.
+func (s *clientKeyStore) require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && provided == "" {
+			provided = bearer
+		}
+		key, ok := s.authenticate(strings.TrimSpace(provided))
+		if provided == "" || !ok {
+			writeAdminJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing, invalid or revoked client key"})
+			return
+		}
+		if r.Method == http.MethodGet {
+			log.Printf("Client %s connected with key %s", key.Client, key.ID)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
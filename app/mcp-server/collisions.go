@@ -0,0 +1,97 @@
+package mcpserver
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+func isTemplateSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// templatesOverlap reports whether two different path templates match some
+// of the same URLs, e.g. /users/{id} and /users/me.
+func templatesOverlap(a, b string) bool {
+	if a == b {
+		return false
+	}
+	aSegments := strings.Split(strings.Trim(a, "/"), "/")
+	bSegments := strings.Split(strings.Trim(b, "/"), "/")
+	if len(aSegments) != len(bSegments) {
+		return false
+	}
+	for i := range aSegments {
+		if aSegments[i] != bSegments[i] && !isTemplateSegment(aSegments[i]) && !isTemplateSegment(bSegments[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific reports whether a wins over b for the URLs both match: the
+// first segment where one has a fixed value and the other a parameter decides,
+// the way routers resolve /users/me before /users/{id}.
+func moreSpecific(a, b string) bool {
+	aSegments := strings.Split(strings.Trim(a, "/"), "/")
+	bSegments := strings.Split(strings.Trim(b, "/"), "/")
+	for i := range aSegments {
+		aTemplate, bTemplate := isTemplateSegment(aSegments[i]), isTemplateSegment(bSegments[i])
+		if aTemplate != bTemplate {
+			return bTemplate
+		}
+	}
+	return false
+}
+
+// describePathOverlaps tells which of the overlapping operations of the same
+// method handles which URLs, for the description of the tool of path.
+func describePathOverlaps(path, method string, toolNames map[string]map[string]string) string {
+	specific, general, ambiguous := []string{}, []string{}, []string{}
+	for otherPath, methods := range toolNames {
+		name, ok := methods[method]
+		if !ok || !templatesOverlap(path, otherPath) {
+			continue
+		}
+		other := fmt.Sprintf("%s (%s)", otherPath, name)
+		switch {
+		case moreSpecific(otherPath, path):
+			specific = append(specific, other)
+		case moreSpecific(path, otherPath):
+			general = append(general, other)
+		default:
+			ambiguous = append(ambiguous, other)
+		}
+	}
+	sort.Strings(specific)
+	sort.Strings(general)
+	sort.Strings(ambiguous)
+	description := ""
+	if len(specific) > 0 {
+		description += fmt.Sprintf(" Paths with fixed values are separate operations that take precedence: %s, use those tools instead of passing their values as parameters here.", strings.Join(specific, ", "))
+	}
+	if len(general) > 0 {
+		description += fmt.Sprintf(" This operation takes precedence over %s for the URLs it matches.", strings.Join(general, ", "))
+	}
+	if len(ambiguous) > 0 {
+		description += fmt.Sprintf(" %s matches exactly the same URLs and the API serves only one of them, prefer the tool whose description fits the request.", strings.Join(ambiguous, ", "))
+	}
+	return description
+}
+
+// warnPathOverlaps logs the path templates matching the same URLs.
+func warnPathOverlaps(toolNames map[string]map[string]string) {
+	paths := make([]string, 0, len(toolNames))
+	for path := range toolNames {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for i, path := range paths {
+		for _, other := range paths[i+1:] {
+			if templatesOverlap(path, other) {
+				log.Printf("Warning: paths %s and %s match the same URLs, tool descriptions tell which one takes precedence", path, other)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// createdResource identifies the resource created by a POST.
+type createdResource struct {
+	ID               string `json:"id,omitempty"`
+	URL              string `json:"url,omitempty"`
+	SuggestedGetTool string `json:"suggested_get_tool,omitempty"`
+}
+
+var (
+	idFields       = []string{"id", "uuid", "_id", "ID", "Id", "key"}
+	idSuffixFields = regexp.MustCompile(`^[a-z][A-Za-z0-9]*(Id|_id|ID)$`)
+	pathParamRegex = regexp.MustCompile(`\{[^}]+\}$`)
+)
+
+// itemGetTool returns the GET tool of the single item below a collection path,
+// e.g. get_users_id for /users.
+func itemGetTool(path string, toolNames map[string]map[string]string) string {
+	for otherPath, methods := range toolNames {
+		if parentPath(otherPath) == strings.TrimSuffix(path, "/") && pathParamRegex.MatchString(otherPath) {
+			if name, ok := methods["get"]; ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// detectCreatedResource reads the identifier of a created resource from the
+// Location header or the id field of a JSON body. It returns nil when nothing
+// was found.
+func detectCreatedResource(reqURL string, statusCode int, header http.Header, body []byte, getTool string) *createdResource {
+	if statusCode < 200 || statusCode >= 300 {
+		return nil
+	}
+	created := &createdResource{SuggestedGetTool: getTool}
+
+	if location := header.Get("Location"); location != "" {
+		if base, err := url.Parse(reqURL); err == nil {
+			if ref, err := url.Parse(location); err == nil {
+				created.URL = base.ResolveReference(ref).String()
+				created.ID = lastSegment(ref.Path)
+			}
+		}
+	}
+
+	var data map[string]interface{}
+	if json.Unmarshal(body, &data) == nil {
+		if id, ok := findID(data); ok {
+			created.ID = id
+		} else if nested, ok := data["data"].(map[string]interface{}); ok {
+			if id, ok := findID(nested); ok {
+				created.ID = id
+			}
+		}
+	}
+
+	if created.ID == "" && created.URL == "" {
+		return nil
+	}
+	if created.URL == "" {
+		if u, err := url.Parse(reqURL); err == nil {
+			u.RawQuery = ""
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + url.PathEscape(created.ID)
+			created.URL = u.String()
+		}
+	}
+	return created
+}
+
+func findID(data map[string]interface{}) (string, bool) {
+	for _, name := range idFields {
+		if id, ok := idString(data[name]); ok {
+			return id, true
+		}
+	}
+	for name, value := range data {
+		if idSuffixFields.MatchString(name) {
+			if id, ok := idString(value); ok {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+func idString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+func lastSegment(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,188 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+const defaultCsrfHeader = "X-CSRF-Token"
+
+// csrfManager fetches and caches anti-CSRF tokens for each MCP session. Every
+// session owns a cookie jar so the cookies set alongside its token are sent
+// back with its mutating calls, and never with the calls of another client.
+type csrfManager struct {
+	mu       sync.Mutex
+	sessions map[string]*csrfSession
+}
+
+// csrfSession is the client and the cached tokens of one MCP session.
+type csrfSession struct {
+	client *http.Client
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func newCsrfManager(apiCfg models.ApiConfig) *csrfManager {
+	if apiCfg.CsrfTokenUrl == "" && apiCfg.CsrfCookie == "" {
+		return nil
+	}
+	return &csrfManager{sessions: map[string]*csrfSession{}}
+}
+
+// session returns the state of the MCP session of ctx, created on its first call.
+func (m *csrfManager) session(ctx context.Context) *csrfSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := sessionID(ctx)
+	session, ok := m.sessions[id]
+	if !ok {
+		jar, _ := cookiejar.New(nil)
+		session = &csrfSession{client: &http.Client{Jar: jar}, tokens: map[string]string{}}
+		m.sessions[id] = session
+	}
+	return session
+}
+
+// client returns the HTTP client of the MCP session of ctx, which keeps its cookies.
+func (m *csrfManager) client(ctx context.Context) *http.Client {
+	return m.session(ctx).client
+}
+
+// forget drops the cookies and tokens of a session that ended.
+func (m *csrfManager) forget(session string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, session)
+}
+
+func isMutatingMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func csrfHeaderName(apiCfg models.ApiConfig) string {
+	if apiCfg.CsrfHeader != "" {
+		return apiCfg.CsrfHeader
+	}
+	return defaultCsrfHeader
+}
+
+// csrfTokenURL resolves the configured token endpoint against the operation base URL.
+func csrfTokenURL(baseURL, tokenURL string) string {
+	if tokenURL == "" || strings.HasPrefix(tokenURL, "http://") || strings.HasPrefix(tokenURL, "https://") {
+		return tokenURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(tokenURL, "/")
+}
+
+// token returns the CSRF token of the session of ctx for a request to reqURL.
+// Tokens fetched from tokenURL are cached until refresh is set; without a token
+// endpoint the token is read from the cookie jar.
+func (m *csrfManager) token(ctx context.Context, apiCfg models.ApiConfig, tokenURL string, reqURL *url.URL, refresh bool) (string, error) {
+	session := m.session(ctx)
+	if tokenURL == "" {
+		if token := session.cookie(reqURL, apiCfg.CsrfCookie); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("csrf cookie %s is not set", apiCfg.CsrfCookie)
+	}
+
+	session.mu.Lock()
+	token, ok := session.tokens[tokenURL]
+	session.mu.Unlock()
+	if ok && !refresh {
+		return token, nil
+	}
+
+	// the lock is not held during the fetch, a slow token endpoint only
+	// delays the calls that need a new token
+	token, err := session.fetch(ctx, apiCfg, tokenURL)
+	if err != nil {
+		return "", err
+	}
+	session.mu.Lock()
+	session.tokens[tokenURL] = token
+	session.mu.Unlock()
+	return token, nil
+}
+
+// fetch gets a new token from tokenURL with the cookies of the session.
+func (s *csrfSession) fetch(ctx context.Context, apiCfg models.ApiConfig, tokenURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create csrf token request: %v", err)
+	}
+	setRequestSecurity(req, apiCfg.Security, apiCfg.BasicAuth, apiCfg.ApiKeyAuth, apiCfg.BearerAuth)
+	client, err := newAuthClient(s.client, apiCfg)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch csrf token: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read csrf token response: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("csrf token endpoint returned status %d", resp.StatusCode)
+	}
+
+	token := ""
+	if apiCfg.CsrfCookie != "" {
+		token = s.cookie(req.URL, apiCfg.CsrfCookie)
+	}
+	if token == "" {
+		token = resp.Header.Get(csrfHeaderName(apiCfg))
+	}
+	if token == "" {
+		token = tokenFromBody(body, apiCfg.CsrfBodyField)
+	}
+	if token == "" {
+		return "", fmt.Errorf("no csrf token found in response from %s", tokenURL)
+	}
+	return token, nil
+}
+
+func (s *csrfSession) cookie(u *url.URL, name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, c := range s.client.Jar.Cookies(u) {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// tokenFromBody looks for the token in a JSON response, under field or one of the usual names.
+func tokenFromBody(body []byte, field string) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return ""
+	}
+	for _, name := range []string{field, "csrfToken", "csrf_token", "_csrf", "token"} {
+		if name == "" {
+			continue
+		}
+		if token, ok := data[name].(string); ok && token != "" {
+			return token
+		}
+	}
+	return ""
+}
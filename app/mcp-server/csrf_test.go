@@ -0,0 +1,80 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestCsrfTokensAndCookiesArePerSession(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			<-release
+		}
+		n := fetches.Add(1)
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: fmt.Sprint(n), Path: "/"})
+		w.Header().Set(defaultCsrfHeader, fmt.Sprintf("token-%d", n))
+	}))
+	defer backend.Close()
+
+	apiCfg := models.ApiConfig{CsrfTokenUrl: backend.URL}
+	csrf := newCsrfManager(apiCfg)
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	ctxA := mcpServer.WithContext(context.Background(), newTestSession("a"))
+	ctxB := mcpServer.WithContext(context.Background(), newTestSession("b"))
+	reqURL, _ := url.Parse(backend.URL)
+
+	// a slow fetch of session a does not hold up session b
+	slowDone := make(chan string)
+	go func() {
+		token, _ := csrf.token(ctxA, apiCfg, backend.URL+"?slow=1", reqURL, false)
+		slowDone <- token
+	}()
+	tokenB := make(chan string)
+	go func() {
+		token, err := csrf.token(ctxB, apiCfg, backend.URL, reqURL, false)
+		if err != nil {
+			t.Error(err)
+		}
+		tokenB <- token
+	}()
+	select {
+	case token := <-tokenB:
+		if token != "token-1" {
+			t.Errorf("session b token = %q, want token-1", token)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session b waited for the token fetch of session a")
+	}
+	close(release)
+	if token := <-slowDone; token != "token-2" {
+		t.Errorf("session a token = %q, want token-2", token)
+	}
+
+	cookies := func(ctx context.Context) string {
+		for _, cookie := range csrf.client(ctx).Jar.Cookies(reqURL) {
+			if cookie.Name == "sid" {
+				return cookie.Value
+			}
+		}
+		return ""
+	}
+	if a, b := cookies(ctxA), cookies(ctxB); a != "2" || b != "1" {
+		t.Errorf("session cookies a=%q b=%q, want a=2 b=1", a, b)
+	}
+
+	csrf.forget("a")
+	if _, ok := csrf.sessions["a"]; ok {
+		t.Error("the ended session is kept")
+	}
+}
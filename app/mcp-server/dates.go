@@ -0,0 +1,199 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// zonedLayouts are the date-time forms carrying their offset, after
+// normalizeDateText. Fractional seconds are accepted by time.Parse anyway.
+var zonedLayouts = []string{
+	"2006-1-2T15:04:05Z07:00", "2006-1-2T15:04:05Z0700", "2006-1-2T15:04:05Z07",
+	"2006-1-2T15:04Z07:00", "2006-1-2T15:04Z0700", "2006-1-2T15:04Z07",
+}
+
+// localLayouts are read in the default timezone.
+var localLayouts = []string{"2006-1-2T15:04:05", "2006-1-2T15:04", "2006-1-2T15", "2006-1-2", "20060102"}
+
+var (
+	epochPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	datePrefix   = regexp.MustCompile(`^\d{4}[-/]\d{1,2}[-/]\d{1,2}`)
+)
+
+// dateParams returns the format of the parameters and top level body
+// properties of an operation declared as date or date-time, by name.
+func dateParams(details models.Endpoint, swaggerSpec models.SwaggerSpec) map[string]string {
+	formats := map[string]string{}
+	add := func(name, format string) {
+		if format == "date" || format == "date-time" {
+			formats[name] = format
+		}
+	}
+	addDefinition := func(schema *models.SchemaRef) {
+		definition, _ := bodyDefinition(swaggerSpec, schema)
+		for propName, prop := range definition.Properties {
+			if prop.Type == "array" && prop.Items != nil {
+				add(propName, prop.Items.Format)
+			} else {
+				add(propName, prop.Format)
+			}
+		}
+	}
+	for _, param := range details.Parameters {
+		if param.In == "body" {
+			if param.Schema != nil {
+				addDefinition(param.Schema)
+			}
+			continue
+		}
+		format := param.Format
+		if param.Schema != nil && format == "" {
+			format = param.Schema.Format
+		}
+		if paramType(param) == "array" {
+			items := param.Items
+			if items == nil && param.Schema != nil {
+				items = param.Schema.Items
+			}
+			format = ""
+			if items != nil {
+				format = items.Format
+			}
+		}
+		add(param.Name, format)
+	}
+	if details.RequestBody != nil {
+		for _, mediaType := range details.RequestBody.Content {
+			if mediaType.Schema != nil {
+				addDefinition(mediaType.Schema)
+			}
+		}
+	}
+	return formats
+}
+
+// normalizeDateText fixes the small deviations models make in dates: slashes
+// in the date, a space instead of the T, lowercase t and z.
+func normalizeDateText(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasSuffix(text, "z") {
+		text = strings.TrimSuffix(text, "z") + "Z"
+	}
+	date := datePrefix.FindString(text)
+	if date == "" {
+		return text
+	}
+	// "2024-06-01 10:00:00 +02:00"
+	clock := strings.ReplaceAll(strings.TrimLeft(text[len(date):], " Tt"), " ", "")
+	text = strings.ReplaceAll(date, "/", "-")
+	if clock != "" {
+		text += "T" + clock
+	}
+	return text
+}
+
+// parseDate reads a date or date-time in the ISO 8601 forms, with or without
+// offset, or epoch seconds or milliseconds. Values without an offset are in loc.
+func parseDate(value interface{}, loc *time.Location) (time.Time, error) {
+	var text string
+	switch v := value.(type) {
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		text = normalizeDateText(v)
+	default:
+		return time.Time{}, fmt.Errorf("expected a date, got %v", value)
+	}
+	if epochPattern.MatchString(text) && len(text) != 8 {
+		seconds, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if seconds > 1e11 || seconds < -1e11 {
+			// milliseconds
+			seconds /= 1000
+		}
+		whole := int64(seconds)
+		return time.Unix(whole, int64((seconds-float64(whole))*1e9)).In(loc), nil
+	}
+	for _, layout := range zonedLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, text, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", text)
+}
+
+// normalizeDate returns value in the form its format declares: YYYY-MM-DD
+// for date, RFC 3339 for date-time, keeping the offset it was given with.
+func normalizeDate(value interface{}, format string, loc *time.Location) (string, error) {
+	t, err := parseDate(value, loc)
+	if err != nil {
+		if format == "date" {
+			return "", fmt.Errorf("%v, use YYYY-MM-DD", err)
+		}
+		return "", fmt.Errorf("%v, use an ISO 8601 date-time such as 2024-06-01T10:00:00Z", err)
+	}
+	if format == "date" {
+		return t.In(loc).Format(time.DateOnly), nil
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
+
+// wrapDates rewrites the date and date-time arguments of handler to the form
+// the spec declares before the call, e.g. "2024/06/01 10:00" to
+// 2024-06-01T10:00:00+02:00 in Europe/Paris, and refuses the ones that are
+// not dates. Arrays are rewritten item by item.
+func wrapDates(formats map[string]string, loc *time.Location, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := make(map[string]interface{}, len(request.GetArguments()))
+		for name, value := range request.GetArguments() {
+			arguments[name] = value
+		}
+		request.Params.Arguments = arguments
+		names := make([]string, 0, len(formats))
+		for name := range formats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			format := formats[name]
+			value, ok := request.GetArguments()[name]
+			if !ok || value == nil {
+				continue
+			}
+			if items, isArray := value.([]interface{}); isArray {
+				normalized := make([]interface{}, len(items))
+				for i, item := range items {
+					text, err := normalizeDate(item, format, loc)
+					if err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid %s in %s item %d: %v", format, name, i+1, err)), nil
+					}
+					normalized[i] = text
+				}
+				request.GetArguments()[name] = normalized
+				continue
+			}
+			text, err := normalizeDate(value, format, loc)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid %s for %s: %v", format, name, err)), nil
+			}
+			request.GetArguments()[name] = text
+		}
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,163 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var derivedFunction = regexp.MustCompile(`^(len|sum|exists)\((.+)\)$`)
+
+// derivedField computes Name from the JSON response of a tool with Expr,
+// a JSONPath or one of len(path), sum(path) and exists(path).
+type derivedField struct {
+	Tool string // empty matches every tool
+	Name string
+	Expr string
+}
+
+// parseDerivedFields parses fields in [tool:]name=expression format, separated by commas.
+func parseDerivedFields(fields string) []derivedField {
+	parsed := []derivedField{}
+	for _, field := range splitList(fields) {
+		name, expr, ok := strings.Cut(field, "=")
+		expr = strings.TrimSpace(expr)
+		if !ok || expr == "" || !(strings.HasPrefix(expr, "$") || derivedFunction.MatchString(expr)) {
+			fmt.Printf("Invalid derived field: %s\n", field)
+			continue
+		}
+		tool := ""
+		if idx := strings.Index(name, ":"); idx >= 0 {
+			tool, name = name[:idx], name[idx+1:]
+		}
+		if tool == "*" {
+			tool = ""
+		}
+		parsed = append(parsed, derivedField{Tool: strings.TrimSpace(tool), Name: strings.TrimSpace(name), Expr: expr})
+	}
+	return parsed
+}
+
+// derivedFieldsFor returns the fields computed for toolName.
+func derivedFieldsFor(fields []derivedField, toolName string) []derivedField {
+	matched := []derivedField{}
+	for _, field := range fields {
+		if field.Tool == "" || field.Tool == toolName {
+			matched = append(matched, field)
+		}
+	}
+	return matched
+}
+
+// pathValues resolves a JSONPath such as $.items[*].price, where [*] matches
+// every element of a list, and returns the values found.
+func pathValues(data interface{}, path string) []interface{} {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := []interface{}{data}
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '[' }) {
+		next := []interface{}{}
+		for _, value := range current {
+			if part == "*]" {
+				if list, ok := value.([]interface{}); ok {
+					next = append(next, list...)
+				}
+				continue
+			}
+			if index, err := strconv.Atoi(strings.TrimSuffix(part, "]")); err == nil && strings.HasSuffix(part, "]") {
+				if list, ok := value.([]interface{}); ok && index >= 0 && index < len(list) {
+					next = append(next, list[index])
+				}
+				continue
+			}
+			if obj, ok := value.(map[string]interface{}); ok {
+				if field, ok := obj[part]; ok {
+					next = append(next, field)
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// evaluate computes the field from a JSON response. It returns false when the
+// path is not in the response.
+func (f derivedField) evaluate(data interface{}) (interface{}, bool) {
+	function, path := "", f.Expr
+	if match := derivedFunction.FindStringSubmatch(f.Expr); match != nil {
+		function, path = match[1], strings.TrimSpace(match[2])
+	}
+	values := pathValues(data, path)
+	// a single list stands for its elements
+	if len(values) == 1 && function != "" && function != "exists" {
+		if list, ok := values[0].([]interface{}); ok {
+			values = list
+		} else if obj, ok := values[0].(map[string]interface{}); ok && function == "len" {
+			return len(obj), true
+		} else if str, ok := values[0].(string); ok && function == "len" {
+			return len(str), true
+		}
+	}
+	switch function {
+	case "len":
+		return len(values), true
+	case "sum":
+		total := 0.0
+		for _, value := range values {
+			if number, ok := value.(float64); ok {
+				total += number
+			}
+		}
+		return total, true
+	case "exists":
+		for _, value := range values {
+			if value != nil && value != false && value != "" {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	switch len(values) {
+	case 0:
+		return nil, false
+	case 1:
+		return values[0], true
+	}
+	return values, true
+}
+
+// wrapDerivedFields adds a derived item holding fields computed from the JSON
+// response of handler.
+func wrapDerivedFields(fields []derivedField, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+			return result, err
+		}
+		first, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			return result, nil
+		}
+		var data interface{}
+		if json.Unmarshal([]byte(first.Text), &data) != nil {
+			return result, nil
+		}
+		derived := map[string]interface{}{}
+		for _, field := range fields {
+			if value, ok := field.evaluate(data); ok {
+				derived[field.Name] = value
+			}
+		}
+		if len(derived) > 0 {
+			derivedData, _ := json.Marshal(map[string]interface{}{"derived": derived})
+			result.Content = append(result.Content, mcp.NewTextContent(string(derivedData)))
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,140 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxPollingEntries bounds the number of previous results kept.
+const maxPollingEntries = 1000
+
+// valueChange is a field whose value differs from the previous call.
+type valueChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// responseDiff lists the fields of a JSON result that changed since the previous call.
+type responseDiff struct {
+	Changed map[string]valueChange `json:"changed,omitempty"`
+	Added   map[string]interface{} `json:"added,omitempty"`
+	Removed []string               `json:"removed,omitempty"`
+}
+
+// pollingDiffer answers repeated identical GET calls of a session with what
+// changed since the previous call instead of the whole result.
+type pollingDiffer struct {
+	mu   sync.Mutex
+	last map[string]string // session, tool and arguments => result body
+}
+
+func newPollingDiffer() *pollingDiffer {
+	return &pollingDiffer{last: map[string]string{}}
+}
+
+// swap stores body as the latest result of key and returns the previous one.
+func (p *pollingDiffer) swap(key, body string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	previous, found := p.last[key]
+	if !found && len(p.last) >= maxPollingEntries {
+		for k := range p.last {
+			delete(p.last, k)
+			break
+		}
+	}
+	p.last[key] = body
+	return previous, found
+}
+
+// diffJSON compares two JSON documents field by field.
+func diffJSON(path string, from, to interface{}, diff *responseDiff) {
+	fromObj, fromIsObj := from.(map[string]interface{})
+	toObj, toIsObj := to.(map[string]interface{})
+	if fromIsObj && toIsObj {
+		for key, fromValue := range fromObj {
+			if toValue, ok := toObj[key]; ok {
+				diffJSON(path+"."+key, fromValue, toValue, diff)
+			} else {
+				diff.Removed = append(diff.Removed, path+"."+key)
+			}
+		}
+		for key, toValue := range toObj {
+			if _, ok := fromObj[key]; !ok {
+				diff.Added[path+"."+key] = toValue
+			}
+		}
+		return
+	}
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		for i := 0; i < len(fromList) || i < len(toList); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(toList):
+				diff.Removed = append(diff.Removed, itemPath)
+			case i >= len(fromList):
+				diff.Added[itemPath] = toList[i]
+			default:
+				diffJSON(itemPath, fromList[i], toList[i], diff)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(from, to) {
+		diff.Changed[path] = valueChange{From: from, To: to}
+	}
+}
+
+// wrap replaces the result of a repeated identical GET call with "unchanged"
+// or, for JSON results, the fields that changed since the previous call.
+func (p *pollingDiffer) wrap(toolName, method string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if !strings.EqualFold(method, http.MethodGet) {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+			return result, err
+		}
+		first, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			return result, nil
+		}
+		args, _ := json.Marshal(request.Params.Arguments)
+		previous, found := p.swap(sessionID(ctx)+"\x00"+toolName+"\x00"+string(args), first.Text)
+		if !found {
+			return result, nil
+		}
+		if previous == first.Text {
+			first.Text = "Unchanged since the previous identical call of this tool."
+			result.Content[0] = first
+			return result, nil
+		}
+		var from, to interface{}
+		if json.Unmarshal([]byte(previous), &from) != nil || json.Unmarshal([]byte(first.Text), &to) != nil {
+			return result, nil
+		}
+		diff := &responseDiff{Changed: map[string]valueChange{}, Added: map[string]interface{}{}}
+		diffJSON("$", from, to, diff)
+		sort.Strings(diff.Removed)
+		diffData, err := json.Marshal(map[string]interface{}{"changes_since_previous_call": diff})
+		if err != nil || len(diffData) >= len(first.Text) {
+			// the whole result is shorter than its diff
+			return result, nil
+		}
+		first.Text = string(diffData)
+		result.Content[0] = first
+		return result, nil
+	}
+}
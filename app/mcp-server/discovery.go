@@ -0,0 +1,238 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	k8sScheme    = "k8s://"
+	consulScheme = "consul://"
+
+	k8sServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// IsDiscoveryURL reports whether baseURL names a service to look up in
+// Kubernetes (k8s://namespace/service:port/path) or Consul (consul://service/path).
+func IsDiscoveryURL(baseURL string) bool {
+	return strings.HasPrefix(baseURL, k8sScheme) || strings.HasPrefix(baseURL, consulScheme)
+}
+
+// serviceResolver remembers the address each discovered service resolved to
+// until a request to it fails.
+type serviceResolver struct {
+	mu       sync.Mutex
+	resolved map[string]string // service => http://host:port
+}
+
+var services = &serviceResolver{resolved: map[string]string{}}
+
+// resolve replaces the service part of a k8s:// or consul:// URL with the
+// address of one of its endpoints. It returns the URL unchanged otherwise,
+// with the service as the second value.
+func (s *serviceResolver) resolve(ctx context.Context, rawURL string) (string, string, error) {
+	if !IsDiscoveryURL(rawURL) {
+		return rawURL, "", nil
+	}
+	scheme, rest, _ := strings.Cut(rawURL, "://")
+	path := ""
+	// k8s services are namespace/service:port, consul services a single segment
+	segments := 1
+	if scheme+"://" == k8sScheme {
+		segments = 2
+	}
+	parts := strings.SplitN(rest, "/", segments+1)
+	if len(parts) < segments {
+		return "", "", fmt.Errorf("invalid service URL %s", rawURL)
+	}
+	service := strings.Join(parts[:segments], "/")
+	if len(parts) > segments {
+		path = "/" + parts[segments]
+	}
+	key := scheme + "://" + service
+
+	s.mu.Lock()
+	address, ok := s.resolved[key]
+	s.mu.Unlock()
+	if !ok {
+		var err error
+		if scheme+"://" == k8sScheme {
+			address, err = resolveK8sService(ctx, service)
+		} else {
+			address, err = resolveConsulService(ctx, service)
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve %s: %v", key, err)
+		}
+		s.mu.Lock()
+		s.resolved[key] = address
+		s.mu.Unlock()
+	}
+	return address + path, key, nil
+}
+
+// forget drops the address of service so the next request looks it up again.
+func (s *serviceResolver) forget(service string) {
+	if service == "" {
+		return
+	}
+	s.mu.Lock()
+	delete(s.resolved, service)
+	s.mu.Unlock()
+}
+
+// isConnectError reports whether the request never reached the backend, so it
+// is safe to send it again to another address.
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// resolveK8sService returns the address of a ready endpoint of namespace/service:port
+// from the Kubernetes API, using the pod's service account.
+func resolveK8sService(ctx context.Context, service string) (string, error) {
+	namespace, name, _ := strings.Cut(service, "/")
+	name, port, _ := strings.Cut(name, ":")
+	host, apiPort := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" {
+		return "", fmt.Errorf("not running in a Kubernetes cluster, KUBERNETES_SERVICE_HOST is not set")
+	}
+	token, err := os.ReadFile(k8sServiceAccountDir + "/token")
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if ca, err := os.ReadFile(k8sServiceAccountDir + "/ca.crt"); err == nil {
+		pool.AppendCertsFromPEM(ca)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	apiURL := "https://" + net.JoinHostPort(host, apiPort)
+	get := func(resource string, target interface{}) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/namespaces/%s/%s/%s", apiURL, url.PathEscape(namespace), resource, url.PathEscape(name)), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("kubernetes API returned %s for %s %s/%s", resp.Status, resource, namespace, name)
+		}
+		return json.NewDecoder(resp.Body).Decode(target)
+	}
+
+	// the port of the service names the endpoint port to connect to
+	var svc struct {
+		Spec struct {
+			Ports []struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			} `json:"ports"`
+		} `json:"spec"`
+	}
+	if err := get("services", &svc); err != nil {
+		return "", err
+	}
+	portName, found := "", port == ""
+	for _, p := range svc.Spec.Ports {
+		if port == "" || strconv.Itoa(p.Port) == port || p.Name == port {
+			portName, found = p.Name, true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("service %s has no port %s", service, port)
+	}
+
+	var endpoints struct {
+		Subsets []struct {
+			Addresses []struct {
+				IP string `json:"ip"`
+			} `json:"addresses"`
+			Ports []struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			} `json:"ports"`
+		} `json:"subsets"`
+	}
+	if err := get("endpoints", &endpoints); err != nil {
+		return "", err
+	}
+	candidates := []string{}
+	for _, subset := range endpoints.Subsets {
+		for _, p := range subset.Ports {
+			if p.Name != portName && len(subset.Ports) > 1 {
+				continue
+			}
+			for _, address := range subset.Addresses {
+				candidates = append(candidates, net.JoinHostPort(address.IP, strconv.Itoa(p.Port)))
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("service %s has no ready endpoints", service)
+	}
+	return "http://" + candidates[rand.Intn(len(candidates))], nil
+}
+
+// resolveConsulService returns the address of a healthy instance of service
+// from the Consul agent at CONSUL_HTTP_ADDR.
+func resolveConsulService(ctx context.Context, service string) (string, error) {
+	agent := os.Getenv("CONSUL_HTTP_ADDR")
+	if agent == "" {
+		agent = "http://127.0.0.1:8500"
+	} else if !strings.Contains(agent, "://") {
+		agent = "http://" + agent
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/health/service/%s?passing=true", strings.TrimSuffix(agent, "/"), url.PathEscape(service)), nil)
+	if err != nil {
+		return "", err
+	}
+	if token := os.Getenv("CONSUL_HTTP_TOKEN"); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("consul returned %s", resp.Status)
+	}
+	var entries []struct {
+		Node struct {
+			Address string `json:"Address"`
+		} `json:"Node"`
+		Service struct {
+			Address string `json:"Address"`
+			Port    int    `json:"Port"`
+		} `json:"Service"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return "", fmt.Errorf("invalid consul response: %v", err)
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("service %s has no healthy instances", service)
+	}
+	entry := entries[rand.Intn(len(entries))]
+	host := entry.Service.Address
+	if host == "" {
+		host = entry.Node.Address
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(entry.Service.Port)), nil
+}
@@ -0,0 +1,146 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/mock"
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// operationDocsURI prefixes the URIs of the operation documentation resources.
+const operationDocsURI = "swagger-mcp://operations/"
+
+// operationDocs renders the documentation of an operation as markdown, with an
+// example of each response. Responses without a declared example get one
+// synthesized from their schema.
+func operationDocs(swaggerSpec models.SwaggerSpec, path, method, toolName string, details models.Endpoint) string {
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "# %s %s\n\nTool: `%s`\n", strings.ToUpper(method), path, toolName)
+	for _, text := range []string{details.Summary, details.Description} {
+		if text != "" {
+			fmt.Fprintf(&doc, "\n%s\n", text)
+		}
+	}
+
+	params := []string{}
+	for _, param := range details.Parameters {
+		if param.In == "body" {
+			continue
+		}
+		line := fmt.Sprintf("- `%s` (%s", param.Name, param.In)
+		if schemaType := paramType(param); schemaType != "" {
+			line += ", " + schemaType
+		}
+		if param.Required {
+			line += ", required"
+		}
+		line += ")"
+		if param.Description != "" {
+			line += ": " + param.Description
+		}
+		for _, example := range namedExamples(param.Examples) {
+			line += fmt.Sprintf("\n  - example `%s`", example.Name)
+			if example.Summary != "" {
+				line += " (" + example.Summary + ")"
+			}
+			if data, err := json.Marshal(example.Value); err == nil {
+				line += fmt.Sprintf(": `%s`", data)
+			}
+		}
+		params = append(params, line)
+	}
+	if len(params) > 0 {
+		fmt.Fprintf(&doc, "\n## Parameters\n\n%s\n", strings.Join(params, "\n"))
+	}
+
+	if presets := bodyPresets(details); len(presets) > 0 {
+		doc.WriteString("\n## Request Body Examples\n\nPass the name as `" + exampleArgument + "` to pre-fill the body with it.\n")
+		names := make([]string, 0, len(presets))
+		for name := range presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			writeExample(&doc, "###", name, presets[name].Summary, presets[name].Body)
+		}
+	}
+
+	statuses := make([]string, 0, len(details.Responses))
+	for status := range details.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	if len(statuses) > 0 {
+		doc.WriteString("\n## Responses\n")
+	}
+	for _, status := range statuses {
+		resp := details.Responses[status]
+		fmt.Fprintf(&doc, "\n### %s %s\n", status, resp.Description)
+		if examples := responseNamedExamples(resp); len(examples) > 0 {
+			for _, example := range examples {
+				writeExample(&doc, "####", example.Name, example.Summary, example.Value)
+			}
+			continue
+		}
+		example, declared, found := mock.ResponseExample(swaggerSpec, resp)
+		if !found {
+			continue
+		}
+		data, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			continue
+		}
+		if !declared {
+			doc.WriteString("\nExample synthesized from the response schema, the values are illustrative:\n")
+		}
+		fmt.Fprintf(&doc, "\n```json\n%s\n```\n", data)
+	}
+	return doc.String()
+}
+
+// responseNamedExamples returns the named examples of the JSON content of a response.
+func responseNamedExamples(resp models.Response) []namedExample {
+	for contentType, mediaType := range resp.Content {
+		if strings.Contains(contentType, "json") {
+			if examples := namedExamples(mediaType.Examples); len(examples) > 0 {
+				return examples
+			}
+		}
+	}
+	return nil
+}
+
+// writeExample renders a named example as a heading with its summary and JSON value.
+func writeExample(doc *strings.Builder, heading, name, summary string, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(doc, "\n%s %s\n", heading, name)
+	if summary != "" {
+		fmt.Fprintf(doc, "\n%s\n", summary)
+	}
+	fmt.Fprintf(doc, "\n```json\n%s\n```\n", data)
+}
+
+// addOperationDocs registers the documentation resource of a tool.
+func addOperationDocs(mcpServer *server.MCPServer, toolName, docs string) {
+	uri := operationDocsURI + toolName
+	mcpServer.AddResource(
+		mcp.NewResource(uri, toolName+" documentation",
+			mcp.WithResourceDescription(fmt.Sprintf("Parameters and example responses of the %s tool", toolName)),
+			mcp.WithMIMEType("text/markdown"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: uri, MIMEType: "text/markdown", Text: docs},
+			}, nil
+		},
+	)
+}
@@ -0,0 +1,157 @@
+package mcpserver
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// saveToArgument is the tool argument naming the file a response is written to.
+const saveToArgument = "save_to"
+
+// downloadRoots writes binary and large responses into the directories the
+// operator set with --downloadRoots, so they are referenced by path instead of
+// inlined. They are not the roots an MCP client declares.
+type downloadRoots struct {
+	dirs      []string // without symlinks
+	threshold int      // bodies larger than this are saved, 0 only saves binary bodies
+}
+
+// savedDownload describes a response written to a root.
+type savedDownload struct {
+	Path        string `json:"path"`
+	Bytes       int    `json:"bytes"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// newDownloadRoots parses a comma-separated list of directories or file:// root URIs.
+func newDownloadRoots(roots string, threshold int) (*downloadRoots, error) {
+	dirs := []string{}
+	for _, root := range splitList(roots) {
+		if strings.HasPrefix(root, "file://") {
+			u, err := url.Parse(root)
+			if err != nil {
+				return nil, fmt.Errorf("invalid root %s: %v", root, err)
+			}
+			root = u.Path
+		}
+		dir, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %s: %v", root, err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("root %s is not a directory", dir)
+		}
+		if dir, err = filepath.EvalSymlinks(dir); err != nil {
+			return nil, fmt.Errorf("invalid root %s: %v", root, err)
+		}
+		dirs = append(dirs, dir)
+	}
+	return &downloadRoots{dirs: dirs, threshold: threshold}, nil
+}
+
+// describe returns the description of the save_to tool argument.
+func (d *downloadRoots) describe() string {
+	return fmt.Sprintf("Optional file to write the response body to instead of returning it, relative to %s or an absolute path inside one of: %s", d.dirs[0], strings.Join(d.dirs, ", "))
+}
+
+// shouldSave reports whether a response is saved even without save_to.
+func (d *downloadRoots) shouldSave(contentType string, body []byte) bool {
+	if d.threshold > 0 && len(body) > d.threshold {
+		return true
+	}
+	return isBinaryContent(contentType, body)
+}
+
+// resolve returns the file for saveTo, which must stay inside one of the roots
+// once its symlinks are resolved. An empty saveTo gets a generated name in the
+// first root.
+func (d *downloadRoots) resolve(saveTo, toolName, contentType string) (string, error) {
+	if saveTo == "" {
+		ext := ".bin"
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+				ext = exts[0]
+			}
+		}
+		saveTo = fmt.Sprintf("%s-%s%s", toolName, time.Now().Format("20060102-150405"), ext)
+	}
+	file := filepath.Clean(saveTo)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(d.dirs[0], file)
+	}
+	// a link inside a root may point outside of it, the files it reaches are checked
+	file, err := realPath(file)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", saveTo, err)
+	}
+	for _, dir := range d.dirs {
+		if rel, err := filepath.Rel(dir, file); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside the download roots", saveTo)
+}
+
+// realPath resolves the symlinks of the absolute path file, whose last
+// elements may not exist yet. A link whose target does not exist is refused,
+// writing to it would create the target wherever it points.
+func realPath(file string) (string, error) {
+	missing := []string{}
+	existing := file
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		missing = append([]string{filepath.Base(existing)}, missing...)
+		existing = parent
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{real}, missing...)...), nil
+}
+
+// save writes body to saveTo inside the roots.
+func (d *downloadRoots) save(saveTo, toolName, contentType string, body []byte) (*savedDownload, error) {
+	file, err := d.resolve(saveTo, toolName, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, body, 0o644); err != nil {
+		return nil, err
+	}
+	return &savedDownload{Path: file, Bytes: len(body), ContentType: contentType}, nil
+}
+
+// isBinaryContent reports whether a body cannot be returned as text.
+func isBinaryContent(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		return !utf8.Valid(body)
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return false
+	}
+	for _, textual := range []string{"json", "xml", "yaml", "javascript", "x-www-form-urlencoded", "csv"} {
+		if strings.Contains(mediaType, textual) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package mcpserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadRootsResolve(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	other := t.TempDir()
+	for _, dir := range []string{filepath.Join(root, "reports"), filepath.Join(outside, "secrets")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{filepath.Join(outside, "passwd"), filepath.Join(root, "reports", "q1.csv")} {
+		if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"escape":          outside,
+		"escape.txt":      filepath.Join(outside, "passwd"),
+		"dangling.txt":    filepath.Join(outside, "created-by-the-link"),
+		"latest":          filepath.Join(root, "reports"),
+		"reports/up.csv":  filepath.Join(root, "reports", "q1.csv"),
+		"reports/out.csv": filepath.Join(outside, "passwd"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+	}
+	roots, err := newDownloadRoots(root+","+other, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	realRoot, _ := filepath.EvalSymlinks(root)
+	realOther, _ := filepath.EvalSymlinks(other)
+
+	tests := []struct {
+		name   string
+		saveTo string
+		want   string // "" when refused
+	}{
+		{"relative name", "report.pdf", filepath.Join(realRoot, "report.pdf")},
+		{"new directory", "new/dir/report.pdf", filepath.Join(realRoot, "new", "dir", "report.pdf")},
+		{"name starting with dots", "..notes.txt", filepath.Join(realRoot, "..notes.txt")},
+		{"absolute in another root", filepath.Join(other, "a.bin"), filepath.Join(realOther, "a.bin")},
+		{"parent directory", "../escaped.txt", ""},
+		{"absolute outside", filepath.Join(outside, "passwd"), ""},
+		{"root itself", root, ""},
+		{"directory link out of the root", "escape/secrets/key.pem", ""},
+		{"file link out of the root", "escape.txt", ""},
+		{"dangling link", "dangling.txt", ""},
+		{"nested file link out of the root", "reports/out.csv", ""},
+		{"directory link inside the root", "latest/q2.csv", filepath.Join(realRoot, "reports", "q2.csv")},
+		{"file link inside the root", "reports/up.csv", filepath.Join(realRoot, "reports", "q1.csv")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := roots.resolve(test.saveTo, "tool", "")
+			if test.want == "" {
+				if err == nil {
+					t.Errorf("resolve(%s) = %s, want it refused", test.saveTo, got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("resolve(%s) = %s, %v, want %s", test.saveTo, got, err, test.want)
+			}
+		})
+	}
+
+	if _, err := roots.save("escape/secrets/key.pem", "tool", "", []byte("x")); err == nil {
+		t.Error("a response was saved through a link out of the root")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "secrets", "key.pem")); err == nil {
+		t.Error("a file was written outside the roots")
+	}
+	if _, err := roots.openUpload(context.Background(), "escape.txt"); err == nil {
+		t.Error("a file outside the roots was opened as an upload")
+	}
+	saved, err := roots.save("", "get__report", "application/pdf", []byte("%PDF"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(saved.Path, realRoot+string(filepath.Separator)+"get__report-") || !strings.HasSuffix(saved.Path, ".pdf") {
+		t.Errorf("saved to %s, want a generated .pdf name in %s", saved.Path, realRoot)
+	}
+}
@@ -0,0 +1,218 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// elevationGrant is a temporary access of a session to the elevated tools.
+type elevationGrant struct {
+	Session string    `json:"session"`
+	Tools   []string  `json:"tools"`
+	Reason  string    `json:"reason,omitempty"`
+	Expires time.Time `json:"expires"`
+	timer   *time.Timer
+}
+
+// connectedSession is an MCP session as listed by the admin API.
+type connectedSession struct {
+	ID        string          `json:"id"`
+	Client    string          `json:"client,omitempty"`
+	Connected time.Time       `json:"connected"`
+	Elevation *elevationGrant `json:"elevation,omitempty"`
+}
+
+// elevationRegistry keeps the elevated tools, which are not listed to any
+// session unless an admin grants it access to them for a limited time.
+type elevationRegistry struct {
+	mu       sync.Mutex
+	tools    map[*server.MCPServer][]server.ServerTool
+	sessions map[string]*connectedSession
+	grants   map[string]*elevationGrant
+}
+
+var elevatedTools = &elevationRegistry{
+	tools:    map[*server.MCPServer][]server.ServerTool{},
+	sessions: map[string]*connectedSession{},
+	grants:   map[string]*elevationGrant{},
+}
+
+// isElevated tells whether toolName belongs to the elevated tool group.
+func isElevated(patterns []*regexp.Regexp, toolName string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+// add keeps an elevated tool of mcpServer without registering it.
+func (r *elevationRegistry) add(mcpServer *server.MCPServer, tool server.ServerTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tools := r.tools[mcpServer]
+	if i := slices.IndexFunc(tools, func(known server.ServerTool) bool { return known.Tool.Name == tool.Tool.Name }); i >= 0 {
+		tools[i] = tool
+		return
+	}
+	r.tools[mcpServer] = append(tools, tool)
+}
+
+// toolsOf returns the elevated tools kept for mcpServer.
+func (r *elevationRegistry) toolsOf(mcpServer *server.MCPServer) []mcp.Tool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tools := make([]mcp.Tool, len(r.tools[mcpServer]))
+	for i, tool := range r.tools[mcpServer] {
+		tools[i] = tool.Tool
+	}
+	return tools
+}
+
+// forget drops every elevated tool of mcpServer, which is no longer served.
+func (r *elevationRegistry) forget(mcpServer *server.MCPServer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tools, mcpServer)
+}
+
+// remove drops elevated tools of mcpServer, also from the sessions granted them.
+func (r *elevationRegistry) remove(mcpServer *server.MCPServer, names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[mcpServer] = slices.DeleteFunc(r.tools[mcpServer], func(tool server.ServerTool) bool { return slices.Contains(names, tool.Tool.Name) })
+	for session, grant := range r.grants {
+		granted := slices.DeleteFunc(slices.Clone(grant.Tools), func(name string) bool { return !slices.Contains(names, name) })
+		if len(granted) == 0 {
+			continue
+		}
+		if err := mcpServer.DeleteSessionTools(session, granted...); err != nil && !errors.Is(err, server.ErrSessionNotFound) {
+			log.Printf("Failed to remove the elevated tools of session %s: %v", session, err)
+		}
+		grant.Tools = slices.DeleteFunc(grant.Tools, func(name string) bool { return slices.Contains(granted, name) })
+	}
+}
+
+// addSessionHooks tracks the sessions of a server so admins can find the one to elevate.
+func (r *elevationRegistry) addSessionHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sessions[session.SessionID()] = &connectedSession{ID: session.SessionID(), Connected: time.Now().UTC()}
+	})
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if session, ok := r.sessions[sessionID(ctx)]; ok {
+			session.Client = fmt.Sprintf("%s %s", message.Params.ClientInfo.Name, message.Params.ClientInfo.Version)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.sessions, session.SessionID())
+		if grant, ok := r.grants[session.SessionID()]; ok {
+			grant.timer.Stop()
+			delete(r.grants, session.SessionID())
+			log.Printf("Audit: elevation of session %s ended, the session disconnected", session.SessionID())
+		}
+	})
+}
+
+// grant gives the session access to the elevated tools for duration. A new
+// grant replaces the previous one of the session.
+func (r *elevationRegistry) grant(session string, duration time.Duration, reason string) (*elevationGrant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := []string{}
+	for mcpServer, tools := range r.tools {
+		if err := mcpServer.AddSessionTools(session, tools...); err != nil {
+			if errors.Is(err, server.ErrSessionNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to grant the elevated tools: %v", err)
+		}
+		for _, tool := range tools {
+			names = append(names, tool.Tool.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("session %s not found or no elevated tools are configured", session)
+	}
+	sort.Strings(names)
+
+	if previous, ok := r.grants[session]; ok {
+		previous.timer.Stop()
+	}
+	grant := &elevationGrant{Session: session, Tools: names, Reason: reason, Expires: time.Now().Add(duration).UTC()}
+	grant.timer = time.AfterFunc(duration, func() {
+		r.expire(grant)
+	})
+	r.grants[session] = grant
+	if connected, ok := r.sessions[session]; ok {
+		connected.Elevation = grant
+	}
+	log.Printf("Audit: granted session %s access to %v for %s, reason: %q", session, names, duration, reason)
+	return grant, nil
+}
+
+// revoke removes the elevated tools from the session. It returns false when
+// the session holds no grant.
+func (r *elevationRegistry) revoke(session, why string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	grant, ok := r.grants[session]
+	if !ok {
+		return false
+	}
+	r.end(grant, why)
+	return true
+}
+
+// expire revokes grant when its timer fires. A timer that fired while a new
+// grant was replacing it, too late to be stopped, leaves the new grant alone.
+func (r *elevationRegistry) expire(grant *elevationGrant) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.grants[grant.Session] == grant {
+		r.end(grant, "expired")
+	}
+}
+
+// end removes the elevated tools of grant from its session, r.mu is held.
+func (r *elevationRegistry) end(grant *elevationGrant, why string) {
+	grant.timer.Stop()
+	delete(r.grants, grant.Session)
+	if connected, ok := r.sessions[grant.Session]; ok {
+		connected.Elevation = nil
+	}
+	for mcpServer := range r.tools {
+		if err := mcpServer.DeleteSessionTools(grant.Session, grant.Tools...); err != nil && !errors.Is(err, server.ErrSessionNotFound) {
+			log.Printf("Failed to remove the elevated tools of session %s: %v", grant.Session, err)
+		}
+	}
+	log.Printf("Audit: revoked the elevation of session %s (%s)", grant.Session, why)
+}
+
+// list returns the connected sessions, oldest first.
+func (r *elevationRegistry) list() []connectedSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]connectedSession, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Connected.Before(sessions[j].Connected) })
+	return sessions
+}
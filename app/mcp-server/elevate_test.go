@@ -0,0 +1,147 @@
+package mcpserver
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testToolSession is a client session that can be given tools of its own.
+type testToolSession struct {
+	*testSession
+	mu    sync.Mutex
+	tools map[string]server.ServerTool
+}
+
+func (s *testToolSession) GetSessionTools() map[string]server.ServerTool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tools
+}
+
+func (s *testToolSession) SetSessionTools(tools map[string]server.ServerTool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = tools
+}
+
+func (s *testToolSession) toolNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := []string{}
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newElevationTest returns a registry with two elevated tools of a server
+// and a session connected to it.
+func newElevationTest(t *testing.T) (*elevationRegistry, *server.MCPServer, *testToolSession) {
+	t.Helper()
+	registry := &elevationRegistry{
+		tools:    map[*server.MCPServer][]server.ServerTool{},
+		sessions: map[string]*connectedSession{},
+		grants:   map[string]*elevationGrant{},
+	}
+	hooks := &server.Hooks{}
+	registry.addSessionHooks(hooks)
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithHooks(hooks), server.WithToolCapabilities(true))
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	for _, name := range []string{"delete__users_id", "put__users_id"} {
+		registry.add(mcpServer, server.ServerTool{Tool: mcp.NewTool(name), Handler: handler})
+	}
+	session := &testToolSession{testSession: newTestSession("s1")}
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	return registry, mcpServer, session
+}
+
+func TestElevationGrantAndRevoke(t *testing.T) {
+	registry, mcpServer, session := newElevationTest(t)
+
+	if _, err := registry.grant("unknown", time.Hour, "test"); err == nil {
+		t.Error("a session that is not connected was granted the elevated tools")
+	}
+	grant, err := registry.grant("s1", time.Hour, "incident 42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"delete__users_id", "put__users_id"}
+	if !slices.Equal(grant.Tools, want) || !slices.Equal(session.toolNames(), want) {
+		t.Errorf("granted %v, the session has %v, want %v", grant.Tools, session.toolNames(), want)
+	}
+	if sessions := registry.list(); len(sessions) != 1 || sessions[0].Elevation != grant {
+		t.Errorf("the listed session does not show the grant: %+v", sessions)
+	}
+
+	if !registry.revoke("s1", "test") {
+		t.Fatal("the grant was not revoked")
+	}
+	if names := session.toolNames(); len(names) != 0 {
+		t.Errorf("the session keeps %v", names)
+	}
+	if registry.revoke("s1", "test") {
+		t.Error("a revoked grant was revoked again")
+	}
+
+	if _, err := registry.grant("s1", time.Hour, "again"); err != nil {
+		t.Fatal(err)
+	}
+	mcpServer.UnregisterSession(context.Background(), "s1")
+	if _, ok := registry.grants["s1"]; ok {
+		t.Error("the grant of a disconnected session is kept")
+	}
+}
+
+func TestElevationGrantExpires(t *testing.T) {
+	registry, _, session := newElevationTest(t)
+	if _, err := registry.grant("s1", 10*time.Millisecond, "short"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(session.toolNames()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the grant did not expire")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if registry.revoke("s1", "test") {
+		t.Error("the expired grant is still held")
+	}
+}
+
+func TestReplacedElevationGrantIsNotExpiredByTheOldTimer(t *testing.T) {
+	registry, _, session := newElevationTest(t)
+	previous, err := registry.grant("s1", time.Hour, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := registry.grant("s1", time.Hour, "extended")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the timer of the first grant fired while the second one replaced it
+	registry.expire(previous)
+
+	if registry.grants["s1"] != current {
+		t.Fatal("the new grant was revoked by the timer of the one it replaced")
+	}
+	if names := session.toolNames(); len(names) != 2 {
+		t.Errorf("the session has %v, want the elevated tools", names)
+	}
+	registry.expire(current)
+	if _, ok := registry.grants["s1"]; ok || len(session.toolNames()) != 0 {
+		t.Error("the current grant did not expire")
+	}
+}
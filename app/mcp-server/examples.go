@@ -0,0 +1,215 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// examplePrompt turns the request body examples of an operation into calls of
+// its tool, served as a prompt so agents see realistic arguments first.
+type examplePrompt struct {
+	name     string
+	toolName string
+	summary  string
+	presets  map[string]bodyPreset
+
+	// arguments of the parameters documenting an example, by argument name
+	params map[string]interface{}
+	// body fields taken as tool arguments, by their name in the body
+	fields map[string]string
+	// argument taking the whole body, when its fields are not arguments
+	wholeBody string
+	// required arguments the examples give no value for
+	missing []string
+}
+
+// parameterExample returns the example of a parameter: its own, the one of
+// its schema, or the first of its named examples.
+func parameterExample(param models.Parameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	if param.Schema != nil && param.Schema.Example != nil {
+		return param.Schema.Example
+	}
+	if examples := namedExamples(param.Examples); len(examples) > 0 {
+		return examples[0].Value
+	}
+	return nil
+}
+
+// newExamplePrompt collects the examples of the operation of toolName, served
+// as prompt name, given the body fields and whole body argument its tool takes.
+func newExamplePrompt(name, toolName string, details models.Endpoint, presets map[string]bodyPreset, aliases *argumentAliases, reqBody map[string]interface{}, rawBodyParam string) *examplePrompt {
+	prompt := &examplePrompt{
+		name:     name,
+		toolName: toolName,
+		summary:  details.Summary,
+		presets:  presets,
+		params:   map[string]interface{}{},
+		fields:   map[string]string{},
+	}
+	for _, param := range details.Parameters {
+		switch param.In {
+		case "path", "query", "header", "cookie":
+		default:
+			continue
+		}
+		if example := parameterExample(param); example != nil {
+			prompt.params[aliases.name(param.Name)] = example
+		} else if param.Required && param.Default == nil {
+			prompt.missing = append(prompt.missing, aliases.name(param.Name))
+		}
+	}
+	for field := range reqBody {
+		prompt.fields[field] = aliases.name(field)
+	}
+	if rawBodyParam != "" {
+		prompt.wholeBody = aliases.name(rawBodyParam)
+	}
+	sort.Strings(prompt.missing)
+	return prompt
+}
+
+// names returns the names of the examples, "default" first.
+func (p *examplePrompt) names() []string {
+	names := make([]string, 0, len(p.presets))
+	for name := range p.presets {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "default") != (names[j] == "default") {
+			return names[i] == "default"
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// arguments returns the tool arguments of the example name. Body fields the
+// tool has no argument for come from the example through _example.
+func (p *examplePrompt) arguments(name string) map[string]interface{} {
+	arguments := map[string]interface{}{}
+	for argument, value := range p.params {
+		arguments[argument] = value
+	}
+	body := p.presets[name].Body
+	if p.wholeBody != "" {
+		arguments[p.wholeBody] = body
+		return arguments
+	}
+	for field, value := range body {
+		if argument, ok := p.fields[field]; ok {
+			arguments[argument] = value
+		} else {
+			arguments[exampleArgument] = name
+		}
+	}
+	return arguments
+}
+
+// text returns the message of the prompt for the example name.
+func (p *examplePrompt) text(name string) string {
+	data, _ := json.MarshalIndent(p.arguments(name), "", "  ")
+	example := name
+	if summary := p.presets[name].Summary; summary != "" {
+		example = fmt.Sprintf("%s (%s)", name, summary)
+	}
+	text := fmt.Sprintf("Call the %s tool", p.toolName)
+	if p.summary != "" {
+		text += fmt.Sprintf(" (%s)", p.summary)
+	}
+	text += fmt.Sprintf(" with arguments like the documented example %s:\n\n```json\n%s\n```\n\nReplace the example values with the ones of the request and keep the structure.", example, data)
+	if len(p.missing) > 0 {
+		text += fmt.Sprintf(" The example has no value for %s, ask the user for them.", strings.Join(p.missing, ", "))
+	}
+	return text
+}
+
+// examplePromptName returns the prompt name of a tool before collisions are resolved.
+func examplePromptName(toolName string) string {
+	return "example_" + strings.Trim(promptNameUnsafe.ReplaceAllString(toolName, "_"), "_")
+}
+
+// examplePromptNames names the example prompts of the tools in toolNames, by
+// path and method. Tool names only differing in characters unsafe in prompt
+// names, such as get.x and get_x, would share a prompt: the tool whose name is
+// already safe, or else the first in sorted order, keeps the plain prompt name
+// and the others get a short hash of their tool name appended, which stays
+// the same across runs.
+func examplePromptNames(toolNames map[string]map[string]string) map[string]string {
+	tools := []string{}
+	for _, methods := range toolNames {
+		for _, toolName := range methods {
+			tools = append(tools, toolName)
+		}
+	}
+	safe := func(toolName string) bool {
+		return examplePromptName(toolName) == "example_"+toolName
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if safe(tools[i]) != safe(tools[j]) {
+			return safe(tools[i])
+		}
+		return tools[i] < tools[j]
+	})
+
+	names := map[string]string{}
+	taken := map[string]bool{}
+	for _, toolName := range tools {
+		if name := examplePromptName(toolName); !taken[name] {
+			names[toolName], taken[name] = name, true
+		}
+	}
+	for _, toolName := range tools {
+		if _, named := names[toolName]; named {
+			continue
+		}
+		sum := sha256.Sum256([]byte(toolName))
+		name := examplePromptName(toolName) + "_" + hex.EncodeToString(sum[:4])
+		names[toolName] = name
+		log.Printf("Warning: example prompt name collision, the prompt of %s is named %s", toolName, name)
+	}
+	return names
+}
+
+// addExamplePrompt registers the prompt, its example argument choosing among
+// the examples of the operation.
+func addExamplePrompt(mcpServer *server.MCPServer, prompt *examplePrompt, registered *specTools) {
+	names := prompt.names()
+	description := fmt.Sprintf("Call %s with arguments pre-filled from a documented request example", prompt.toolName)
+	if prompt.summary != "" {
+		description += ": " + prompt.summary
+	}
+	mcpServer.AddPrompt(
+		mcp.NewPrompt(prompt.name,
+			mcp.WithPromptDescription(description),
+			mcp.WithArgument("example",
+				mcp.ArgumentDescription(fmt.Sprintf("The example to start from: %s (default %s)", strings.Join(names, ", "), names[0])),
+			),
+		),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			name := request.Params.Arguments["example"]
+			if name == "" {
+				name = names[0]
+			}
+			if _, ok := prompt.presets[name]; !ok {
+				return nil, fmt.Errorf("unknown example: %s, use one of %s", name, strings.Join(names, ", "))
+			}
+			return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(prompt.text(name))),
+			}), nil
+		},
+	)
+	registered.notePrompt(prompt.name)
+}
@@ -0,0 +1,27 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExamplePromptNames(t *testing.T) {
+	names := examplePromptNames(map[string]map[string]string{
+		"/x":     {"get": "get.x"},
+		"/x2":    {"get": "get_x"},
+		"/y":     {"get": "get-y"},
+		"/users": {"post": "post__users"},
+	})
+	if names["get_x"] != "example_get_x" {
+		t.Errorf("the safe tool name lost its plain prompt name: %v", names)
+	}
+	if !strings.HasPrefix(names["get.x"], "example_get_x_") || len(names["get.x"]) != len("example_get_x_")+8 {
+		t.Errorf("the colliding tool name got no hash suffix: %v", names)
+	}
+	if names["get-y"] != "example_get-y" || names["post__users"] != "example_post__users" {
+		t.Errorf("names without collision changed: %v", names)
+	}
+	if again := examplePromptNames(map[string]map[string]string{"/x2": {"get": "get_x"}, "/x": {"get": "get.x"}}); again["get.x"] != names["get.x"] {
+		t.Errorf("the suffix is not stable: %s then %s", names["get.x"], again["get.x"])
+	}
+}
@@ -0,0 +1,116 @@
+package mcpserver
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// failoverCooldown is how long an unreachable server is tried last.
+const failoverCooldown = 30 * time.Second
+
+// failoverGroup is an ordered list of base URLs serving the same API. Calls
+// go to the first one up; a server a connection failed to is down for
+// failoverCooldown, so calls stick to the healthy one meanwhile and go back
+// to the preferred one afterwards.
+type failoverGroup struct {
+	mu        sync.Mutex
+	bases     []string
+	downUntil map[string]time.Time
+}
+
+var (
+	failoverGroupsMu sync.Mutex
+	failoverGroups   = map[string]*failoverGroup{}
+)
+
+// failoverFor returns the group of bases, shared by every tool using the same
+// servers so they all remember which one is down. It is nil for a single base.
+func failoverFor(bases []string) *failoverGroup {
+	if len(bases) < 2 {
+		return nil
+	}
+	key := strings.Join(bases, " ")
+	failoverGroupsMu.Lock()
+	defer failoverGroupsMu.Unlock()
+	if group, ok := failoverGroups[key]; ok {
+		return group
+	}
+	group := &failoverGroup{bases: bases, downUntil: map[string]time.Time{}}
+	failoverGroups[key] = group
+	return group
+}
+
+// failoverBases returns the base URLs of an operation in the order they are
+// tried: primary, then the failoverUrls of apiCfg, or with "servers" the
+// other servers the spec declares for the operation.
+func failoverBases(apiCfg models.ApiConfig, swaggerSpec models.SwaggerSpec, details models.Endpoint, primary string) []string {
+	primary = strings.TrimSuffix(primary, "/")
+	bases := []string{primary}
+	add := func(base string) {
+		if base = strings.TrimSuffix(strings.TrimSpace(base), "/"); base != "" && !slices.Contains(bases, base) {
+			bases = append(bases, base)
+		}
+	}
+	if strings.TrimSpace(apiCfg.FailoverUrls) == "servers" {
+		servers := details.Servers
+		if len(servers) == 0 {
+			servers = swaggerSpec.Servers
+		}
+		for _, server := range servers {
+			add(server.URL)
+		}
+		return bases
+	}
+	for _, base := range splitList(apiCfg.FailoverUrls) {
+		add(base)
+	}
+	return bases
+}
+
+// order returns the bases to try in turn: the ones up in their order, then
+// the ones down, the least recently failed first.
+func (g *failoverGroup) order() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	up, down := []string{}, []string{}
+	for _, base := range g.bases {
+		if until, ok := g.downUntil[base]; ok && now.Before(until) {
+			down = append(down, base)
+		} else {
+			up = append(up, base)
+		}
+	}
+	sort.SliceStable(down, func(i, j int) bool { return g.downUntil[down[i]].Before(g.downUntil[down[j]]) })
+	return append(up, down...)
+}
+
+// down marks base unreachable for failoverCooldown.
+func (g *failoverGroup) down(base string, err error) {
+	g.mu.Lock()
+	g.downUntil[base] = time.Now().Add(failoverCooldown)
+	g.mu.Unlock()
+	log.Printf("Server %s is unreachable, failing over: %v", base, err)
+}
+
+// up marks base reachable again.
+func (g *failoverGroup) up(base string) {
+	g.mu.Lock()
+	delete(g.downUntil, base)
+	g.mu.Unlock()
+}
+
+// rebase moves rawURL from one base URL to another, false when it is not
+// under from, e.g. after a URL rewrite.
+func rebase(rawURL, from, to string) (string, bool) {
+	if !strings.HasPrefix(rawURL, from) {
+		return rawURL, false
+	}
+	return to + strings.TrimPrefix(rawURL, from), true
+}
@@ -0,0 +1,115 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// operationConsumes returns the media types an operation accepts: its own
+// consumes, the root-level one in Swagger 2.0, or the content types of its
+// OpenAPI 3.0 request body.
+func operationConsumes(swaggerSpec models.SwaggerSpec, details models.Endpoint) []string {
+	if len(details.Consumes) > 0 {
+		return details.Consumes
+	}
+	if len(swaggerSpec.Consumes) > 0 {
+		return swaggerSpec.Consumes
+	}
+	if details.RequestBody == nil {
+		return nil
+	}
+	contentTypes := make([]string, 0, len(details.RequestBody.Content))
+	for contentType := range details.RequestBody.Content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+	return contentTypes
+}
+
+// formEncoded tells whether the body is sent as a form: the operation
+// consumes application/x-www-form-urlencoded and no JSON.
+func formEncoded(consumes []string) bool {
+	form := false
+	for _, contentType := range consumes {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			continue
+		}
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return false
+		}
+		form = form || mediaType == formContentType
+	}
+	return form
+}
+
+// encodeForm encodes the body fields as application/x-www-form-urlencoded, in
+// order and then by name. Arrays of formData parameters follow their
+// collectionFormat, other arrays repeat the field once per item as OpenAPI 3.0
+// forms do; objects are sent as JSON text.
+func encodeForm(data map[string]interface{}, order []string, specs map[string]models.Parameter) (string, error) {
+	names := []string{}
+	for _, name := range order {
+		if _, ok := data[name]; ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	rest := []string{}
+	for name := range data {
+		if !slices.Contains(names, name) {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	form := []string{}
+	for _, name := range names {
+		values := []string{}
+		if items, ok := data[name].([]interface{}); ok {
+			for _, item := range items {
+				text, err := formValue(item)
+				if err != nil {
+					return "", err
+				}
+				values = append(values, text)
+			}
+			if spec, ok := specs[name]; ok && spec.In == "formData" {
+				values = collectionValues(values, spec)
+			}
+		} else {
+			text, err := formValue(data[name])
+			if err != nil {
+				return "", err
+			}
+			values = append(values, text)
+		}
+		for _, value := range values {
+			form = append(form, url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(form, "&"), nil
+}
+
+// formValue returns the text of a form field, JSON for objects and nested arrays.
+func formValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		text, err := json.Marshal(v)
+		return string(text), err
+	case string, float64, bool:
+		return argumentString(v, "string")
+	}
+	return fmt.Sprint(value), nil
+}
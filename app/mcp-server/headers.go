@@ -0,0 +1,73 @@
+package mcpserver
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// headerCapture copies a response header of the operations matching Path into
+// the tool result, and into the session variable Variable when it is set.
+type headerCapture struct {
+	Path     *regexp.Regexp // nil matches every operation
+	Header   string
+	Variable string
+}
+
+// parseHeaderCaptures parses rules in [pathRegex:]Header[=variable] format, separated by commas.
+func parseHeaderCaptures(rules string) []headerCapture {
+	captures := []headerCapture{}
+	for _, rule := range splitList(rules) {
+		expr, variable, _ := strings.Cut(rule, "=")
+		capture := headerCapture{Header: expr, Variable: strings.TrimSpace(variable)}
+		if idx := strings.LastIndex(expr, ":"); idx >= 0 {
+			path, err := regexp.Compile(strings.TrimSpace(expr[:idx]))
+			if err != nil {
+				fmt.Printf("Invalid header capture path %s: %v\n", expr[:idx], err)
+				continue
+			}
+			capture.Path, capture.Header = path, expr[idx+1:]
+		}
+		capture.Header = http.CanonicalHeaderKey(strings.TrimSpace(capture.Header))
+		if capture.Header == "" {
+			fmt.Printf("Invalid header capture: %s\n", rule)
+			continue
+		}
+		captures = append(captures, capture)
+	}
+	return captures
+}
+
+// headerCapturesFor returns the captures applying to an operation path.
+func headerCapturesFor(captures []headerCapture, path string) []headerCapture {
+	matched := []headerCapture{}
+	for _, capture := range captures {
+		if capture.Path == nil || capture.Path.MatchString(path) {
+			matched = append(matched, capture)
+		}
+	}
+	return matched
+}
+
+// headerCaptureRules saves the captured headers with a variable name through the variable store.
+func headerCaptureRules(captures []headerCapture) []captureRule {
+	rules := []captureRule{}
+	for _, capture := range captures {
+		if capture.Variable != "" {
+			rules = append(rules, captureRule{Path: "$.response_headers." + capture.Header, Name: capture.Variable})
+		}
+	}
+	return rules
+}
+
+// capturedHeaders returns the values of the captured headers present in header.
+func capturedHeaders(header http.Header, captures []headerCapture) map[string]string {
+	values := map[string]string{}
+	for _, capture := range captures {
+		if value := header.Get(capture.Header); value != "" {
+			values[capture.Header] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,180 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	bolt "go.etcd.io/bbolt"
+)
+
+const historyBucket = "history"
+
+// historyRecord is a tool call result kept in the history store.
+type historyRecord struct {
+	ID        uint64                 `json:"id"`
+	Session   string                 `json:"session"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	IsError   bool                   `json:"is_error,omitempty"`
+	Result    string                 `json:"result"`
+	Time      time.Time              `json:"time"`
+}
+
+// historyStore records tool results in a bolt database so they can be looked
+// up again with the query_history tool instead of calling the API again.
+type historyStore struct {
+	db *bolt.DB
+}
+
+var (
+	historyStoresMu sync.Mutex
+	historyStores   = map[string]*historyStore{}
+)
+
+// newHistoryStore opens the history store at path. Servers of the same process
+// share the store, bolt only allows a database file to be opened once.
+func newHistoryStore(path string) (*historyStore, error) {
+	historyStoresMu.Lock()
+	defer historyStoresMu.Unlock()
+	if store, ok := historyStores[path]; ok {
+		return store, nil
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store %s: %v", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(historyBucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history store: %v", err)
+	}
+	store := &historyStore{db: db}
+	historyStores[path] = store
+	return store, nil
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	texts := []string{}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (h *historyStore) add(record historyRecord) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(historyBucket))
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		record.ID = id
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, id)
+		return bucket.Put(key, data)
+	})
+}
+
+// query returns the newest records of the session, most recent first.
+func (h *historyStore) query(session, tool, contains string, limit int) ([]historyRecord, error) {
+	records := []historyRecord{}
+	err := h.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(historyBucket)).Cursor()
+		for key, value := cursor.Last(); key != nil && len(records) < limit; key, value = cursor.Prev() {
+			var record historyRecord
+			if err := json.Unmarshal(value, &record); err != nil {
+				continue
+			}
+			if record.Session != session {
+				continue
+			}
+			if tool != "" && record.Tool != tool {
+				continue
+			}
+			if contains != "" && !strings.Contains(record.Result, contains) {
+				continue
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// wrap records the results of handler under toolName, without the values of the sensitive arguments.
+func (h *historyStore) wrap(toolName string, sensitive []string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		record := historyRecord{
+			Session:   sessionID(ctx),
+			Tool:      toolName,
+			Arguments: redactArguments(request.GetArguments(), sensitive),
+			IsError:   result.IsError,
+			Result:    resultText(result),
+			Time:      time.Now().UTC(),
+		}
+		if err := h.add(record); err != nil {
+			fmt.Printf("Failed to record tool result: %v\n", err)
+		}
+		return result, nil
+	}
+}
+
+// addQueryHistoryTool registers the query_history meta tool.
+func (h *historyStore) addQueryHistoryTool(mcpServer *server.MCPServer) {
+	mcpServer.AddTool(
+		mcp.NewTool("query_history",
+			mcp.WithDescription("Look up results of earlier tool calls of this session instead of calling a rate-limited endpoint again. Returns the most recent matching results first."),
+			mcp.WithString("tool", mcp.Description("Only return results of this tool")),
+			mcp.WithString("contains", mcp.Description("Only return results containing this text")),
+			mcp.WithString("limit", mcp.Description("Maximum number of results to return (default 5)")),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tool, _ := request.GetArguments()["tool"].(string)
+			contains, _ := request.GetArguments()["contains"].(string)
+			limit := 5
+			if limitStr, ok := request.GetArguments()["limit"].(string); ok && limitStr != "" {
+				var err error
+				if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid limit: %s", limitStr)), nil
+				}
+			}
+			records, err := h.query(sessionID(ctx), tool, contains, limit)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to query history: %v", err)), nil
+			}
+			data, err := json.Marshal(records)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to marshal history: %v", err)), nil
+			}
+			return mcp.NewToolResultText(string(data)), nil
+		},
+	)
+}
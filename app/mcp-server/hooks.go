@@ -0,0 +1,50 @@
+package mcpserver
+
+import (
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// OperationHook builds the handler of the tool of an operation from the
+// generated one, next: it may wrap next, call it with other arguments or not
+// call it at all. The hooked handler still runs inside the checks, limits,
+// redaction and metrics of the tool.
+type OperationHook func(next server.ToolHandlerFunc) server.ToolHandlerFunc
+
+var (
+	operationHooksMu sync.RWMutex
+	operationHooks   = map[string]OperationHook{}
+)
+
+// RegisterOperationHook replaces or wraps the handler of the operation with
+// operationID in the specs loaded afterwards, e.g. to compute a result
+// locally or marshal a body the generated handler can't. The other
+// operations keep their generated handlers. A nil hook removes the one of
+// operationID.
+func RegisterOperationHook(operationID string, hook OperationHook) {
+	operationHooksMu.Lock()
+	defer operationHooksMu.Unlock()
+	if hook == nil {
+		delete(operationHooks, operationID)
+		return
+	}
+	operationHooks[operationID] = hook
+}
+
+// hookHandler applies the hook registered for operationID to handler.
+func hookHandler(operationID string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if operationID == "" {
+		return handler
+	}
+	operationHooksMu.RLock()
+	hook, ok := operationHooks[operationID]
+	operationHooksMu.RUnlock()
+	if !ok {
+		return handler
+	}
+	if hooked := hook(handler); hooked != nil {
+		return hooked
+	}
+	return handler
+}
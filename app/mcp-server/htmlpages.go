@@ -0,0 +1,83 @@
+package mcpserver
+
+import (
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const htmlExcerptLength = 300
+
+var (
+	htmlTitle   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlHeading = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	htmlBody    = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	htmlHidden  = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlTag     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// isHTMLPage tells whether a response is an HTML page, from its content type
+// or, when it has none, from its first bytes.
+func isHTMLPage(contentType, body string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	}
+	start := strings.ToLower(strings.TrimSpace(body))
+	return strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html")
+}
+
+// htmlText returns the visible text of an HTML fragment with whitespace collapsed.
+func htmlText(fragment string) string {
+	text := htmlTag.ReplaceAllString(htmlHidden.ReplaceAllString(fragment, " "), " ")
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// htmlErrorPage summarizes an HTML page returned by an operation that does not
+// produce HTML, typically the error page of a load balancer or gateway, so its
+// markup does not end up in the model's context. It returns false for other
+// responses.
+func htmlErrorPage(statusCode int, contentType, body string, produces []string) (string, bool) {
+	for _, mediaType := range produces {
+		if strings.Contains(mediaType, "html") {
+			return "", false
+		}
+	}
+	if !isHTMLPage(contentType, body) {
+		return "", false
+	}
+
+	summary := fmt.Sprintf("the API returned an HTML page instead of data (HTTP %d %s)", statusCode, http.StatusText(statusCode))
+	if statusCode >= 200 && statusCode < 300 {
+		summary += ", likely a login or maintenance page served in front of the API"
+	} else {
+		summary += ", likely the error page of a gateway or load balancer in front of the API"
+	}
+	details := []string{}
+	if match := htmlTitle.FindStringSubmatch(body); match != nil {
+		if title := htmlText(match[1]); title != "" {
+			details = append(details, "title: "+title)
+		}
+	}
+	if match := htmlHeading.FindStringSubmatch(body); match != nil {
+		if heading := htmlText(match[1]); heading != "" && !strings.Contains(strings.Join(details, ""), heading) {
+			details = append(details, "heading: "+heading)
+		}
+	}
+	content := body
+	if match := htmlBody.FindStringSubmatch(body); match != nil {
+		content = match[1]
+	}
+	if text := []rune(htmlText(content)); len(text) > 0 {
+		if len(text) > htmlExcerptLength {
+			text = append(text[:htmlExcerptLength], '…')
+		}
+		details = append(details, "text: "+string(text))
+	}
+	if len(details) > 0 {
+		summary += "\n" + strings.Join(details, "\n")
+	}
+	return summary, true
+}
@@ -0,0 +1,138 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const toolLimitationsURI = "swagger-mcp://tool_limitations"
+
+// toolLimitations records, by tool, the parameters and schema features of
+// the spec a tool could not represent, so users can tell why an agent cannot
+// set a field.
+type toolLimitations struct {
+	mu    sync.Mutex
+	tools map[string][]string
+}
+
+var (
+	toolLimitationsMu sync.Mutex
+	limitationLogs    = map[*server.MCPServer]*toolLimitations{}
+)
+
+// limitationsOf returns the limitations of the tools of mcpServer, and
+// registers their resource the first time.
+func limitationsOf(mcpServer *server.MCPServer) *toolLimitations {
+	toolLimitationsMu.Lock()
+	defer toolLimitationsMu.Unlock()
+	if limitations, ok := limitationLogs[mcpServer]; ok {
+		return limitations
+	}
+	limitations := &toolLimitations{tools: map[string][]string{}}
+	limitationLogs[mcpServer] = limitations
+	mcpServer.AddResource(
+		mcp.NewResource(toolLimitationsURI, "Tool limitations",
+			mcp.WithResourceDescription("The parameters and schema features of the API each tool leaves out or degrades, with the reason, e.g. an unsupported type or an undefined $ref"),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			data, err := json.MarshalIndent(limitations.snapshot(), "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: toolLimitationsURI, MIMEType: "application/json", Text: string(data)},
+			}, nil
+		},
+	)
+	return limitations
+}
+
+// set replaces the limitations of toolName, as when its spec is reloaded.
+func (l *toolLimitations) set(toolName string, notes []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(notes) == 0 {
+		delete(l.tools, toolName)
+		return
+	}
+	l.tools[toolName] = notes
+}
+
+func (l *toolLimitations) snapshot() map[string][]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tools := make(map[string][]string, len(l.tools))
+	for name, notes := range l.tools {
+		tools[name] = notes
+	}
+	return tools
+}
+
+// limitationNotes collects the limitations of one tool while it is built.
+type limitationNotes struct {
+	method, path, toolName string
+	notes                  []string
+}
+
+// add records a limitation and logs it as a warning.
+func (n *limitationNotes) add(format string, args ...interface{}) {
+	note := fmt.Sprintf(format, args...)
+	n.notes = append(n.notes, note)
+	log.Printf("Warning: %s %s, tool %s: %s", strings.ToUpper(n.method), n.path, n.toolName, note)
+}
+
+// paramLimitation notes a parameter whose type can't be given to the tool
+// argument, which then takes a string sent as is.
+func (n *limitationNotes) paramLimitation(param models.Parameter) {
+	switch paramType(param) {
+	case "string", "integer", "number", "boolean", "array":
+		return
+	case "":
+		if param.Schema != nil && param.Schema.Ref != "" {
+			n.add("the %s parameter %s has the schema %s, which is not resolved for parameters, the argument takes a string sent as is", param.In, param.Name, ExtractSchemaName(param.Schema.Ref, ""))
+		}
+	default:
+		n.add("the %s parameter %s has the unsupported type %s, the argument takes a string sent as is", param.In, param.Name, paramType(param))
+	}
+}
+
+// bodyLimitations notes the properties of a request body definition that
+// refer to a schema the spec does not define.
+func (n *limitationNotes) bodyLimitations(swaggerSpec models.SwaggerSpec, definition models.Definition) {
+	names := make([]string, 0, len(definition.Properties))
+	for name := range definition.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := resolveProperty(swaggerSpec, definition.Properties[name])
+		if prop.Ref != "" && prop.Type == "" {
+			n.add("the body field %s refers to the schema %s the spec does not define, its structure is unknown", name, ExtractSchemaName(prop.Ref, ""))
+		}
+	}
+}
+
+// noteLimitations records the limitations of toolName on mcpServer. The
+// resource only appears once a tool has some.
+func noteLimitations(mcpServer *server.MCPServer, toolName string, notes []string) {
+	if len(notes) > 0 {
+		limitationsOf(mcpServer).set(toolName, notes)
+		return
+	}
+	toolLimitationsMu.Lock()
+	limitations, ok := limitationLogs[mcpServer]
+	toolLimitationsMu.Unlock()
+	if ok {
+		limitations.set(toolName, nil)
+	}
+}
@@ -0,0 +1,203 @@
+package mcpserver
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// specTools are the tools, resources and prompts one spec registered on a
+// server.
+type specTools struct {
+	tools     []string
+	elevated  []string
+	resources []string
+	prompts   []string
+}
+
+func (t *specTools) noteTool(name string) {
+	if t != nil {
+		t.tools = append(t.tools, name)
+	}
+}
+
+func (t *specTools) noteElevated(name string) {
+	if t != nil {
+		t.elevated = append(t.elevated, name)
+	}
+}
+
+func (t *specTools) noteResource(uri string) {
+	if t != nil {
+		t.resources = append(t.resources, uri)
+	}
+}
+
+func (t *specTools) notePrompt(name string) {
+	if t != nil {
+		t.prompts = append(t.prompts, name)
+	}
+}
+
+// names returns the tool names, sorted.
+func (t *specTools) names() []string {
+	names := append(slices.Clone(t.tools), t.elevated...)
+	sort.Strings(names)
+	return names
+}
+
+// liveSpec is a spec registered on a LiveServer.
+type liveSpec struct {
+	apiCfg     models.ApiConfig
+	registered *specTools
+}
+
+// SpecChanges lists the tools a LiveServer call registered, replaced or removed.
+type SpecChanges struct {
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+}
+
+// LiveServer adds, removes and reloads the tools of specs on a running MCP
+// server, for programs embedding swagger-mcp. Each spec is known by an id and
+// a change only touches the tools of that spec: connected clients get
+// notifications/tools/list_changed and keep their sessions. Tools shared by
+// all specs, such as query_history or the variable tools, stay registered.
+type LiveServer struct {
+	mcpServer *server.MCPServer
+	mu        sync.Mutex
+	specs     map[string]*liveSpec
+}
+
+// NewLiveServer manages the spec tools of mcpServer.
+func NewLiveServer(mcpServer *server.MCPServer) *LiveServer {
+	return &LiveServer{mcpServer: mcpServer, specs: map[string]*liveSpec{}}
+}
+
+// AddSpec registers the tools of a spec under id, with the settings of apiCfg.
+func (l *LiveServer) AddSpec(id string, swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig) (SpecChanges, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, exists := l.specs[id]; exists {
+		return SpecChanges{}, fmt.Errorf("spec %s is already registered, reload it instead", id)
+	}
+	if err := l.checkConflicts(id, swaggerSpec, apiCfg); err != nil {
+		return SpecChanges{}, err
+	}
+	registered := &specTools{}
+	if err := loadSwagger(l.mcpServer, swaggerSpec, apiCfg, registered); err != nil {
+		return SpecChanges{}, fmt.Errorf("spec %s: %v", id, err)
+	}
+	l.specs[id] = &liveSpec{apiCfg: apiCfg, registered: registered}
+	return SpecChanges{Added: registered.names(), Updated: []string{}, Removed: []string{}}, nil
+}
+
+// RemoveSpec unregisters the tools of the spec registered under id.
+func (l *LiveServer) RemoveSpec(id string) (SpecChanges, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	spec, exists := l.specs[id]
+	if !exists {
+		return SpecChanges{}, fmt.Errorf("spec %s is not registered", id)
+	}
+	l.unregister(spec.registered, nil)
+	delete(l.specs, id)
+	return SpecChanges{Added: []string{}, Updated: []string{}, Removed: spec.registered.names()}, nil
+}
+
+// ReloadSpec replaces the spec registered under id with a new version of it:
+// tools of operations still in the spec are replaced in place, the ones of
+// new operations added and the ones of removed operations unregistered.
+func (l *LiveServer) ReloadSpec(id string, swaggerSpec models.SwaggerSpec) (SpecChanges, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	spec, exists := l.specs[id]
+	if !exists {
+		return SpecChanges{}, fmt.Errorf("spec %s is not registered", id)
+	}
+	if err := l.checkConflicts(id, swaggerSpec, spec.apiCfg); err != nil {
+		return SpecChanges{}, err
+	}
+	registered := &specTools{}
+	if err := loadSwagger(l.mcpServer, swaggerSpec, spec.apiCfg, registered); err != nil {
+		return SpecChanges{}, fmt.Errorf("spec %s: %v", id, err)
+	}
+	l.unregister(spec.registered, registered)
+
+	previous, current := spec.registered.names(), registered.names()
+	changes := SpecChanges{Added: []string{}, Updated: []string{}, Removed: []string{}}
+	for _, name := range current {
+		if slices.Contains(previous, name) {
+			changes.Updated = append(changes.Updated, name)
+		} else {
+			changes.Added = append(changes.Added, name)
+		}
+	}
+	for _, name := range previous {
+		if !slices.Contains(current, name) {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	spec.registered = registered
+	return changes, nil
+}
+
+// Specs returns the ids of the registered specs and their tools.
+func (l *LiveServer) Specs() map[string][]string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	specs := make(map[string][]string, len(l.specs))
+	for id, spec := range l.specs {
+		specs[id] = spec.registered.names()
+	}
+	return specs
+}
+
+// checkConflicts refuses a spec whose tool names are taken by another spec,
+// registering it would silently replace the other spec's tools.
+func (l *LiveServer) checkConflicts(id string, swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig) error {
+	includeOperation := OperationFilter(apiCfg, swaggerSpec)
+	toolNames, _ := operationToolNames(apiCfg, swaggerSpec)
+	for path, methods := range swaggerSpec.Paths {
+		for method := range methods {
+			if !includeOperation(path, method) {
+				continue
+			}
+			name := toolNames[path][method]
+			for otherID, other := range l.specs {
+				if otherID != id && slices.Contains(other.registered.names(), name) {
+					return fmt.Errorf("tool %s of spec %s is already registered by spec %s", name, id, otherID)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unregister removes the tools, resources and prompts of previous that kept is not
+// registering again, kept is nil to remove them all.
+func (l *LiveServer) unregister(previous *specTools, kept *specTools) {
+	gone := func(names []string, still []string) []string {
+		return slices.DeleteFunc(slices.Clone(names), func(name string) bool { return slices.Contains(still, name) })
+	}
+	if kept == nil {
+		kept = &specTools{}
+	}
+	if tools := gone(previous.tools, kept.tools); len(tools) > 0 {
+		apiTools.remove(l.mcpServer, tools)
+	}
+	if elevated := gone(previous.elevated, kept.elevated); len(elevated) > 0 {
+		elevatedTools.remove(l.mcpServer, elevated)
+	}
+	for _, uri := range gone(previous.resources, kept.resources) {
+		l.mcpServer.RemoveResource(uri)
+	}
+	if prompts := gone(previous.prompts, kept.prompts); len(prompts) > 0 {
+		l.mcpServer.DeletePrompts(prompts...)
+	}
+}
@@ -0,0 +1,134 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// liveSpecOf returns a spec with a GET operation on each of paths.
+func liveSpecOf(t *testing.T, paths ...string) models.SwaggerSpec {
+	t.Helper()
+	operations := map[string]interface{}{}
+	for _, path := range paths {
+		operations[path] = map[string]interface{}{"get": map[string]interface{}{"responses": map[string]interface{}{"200": map[string]interface{}{"description": "ok"}}}}
+	}
+	document, _ := json.Marshal(map[string]interface{}{"swagger": "2.0", "host": "api.example.com", "paths": operations})
+	var spec models.SwaggerSpec
+	if err := json.Unmarshal(document, &spec); err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+// listedToolNames returns the names of the tools mcpServer lists, sorted.
+func listedToolNames(mcpServer *server.MCPServer) []string {
+	response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	names := []string{}
+	for _, tool := range response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestLiveServerAddReloadRemove(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	live := NewLiveServer(mcpServer)
+	apiCfg := models.ApiConfig{BaseUrl: "https://api.example.com"}
+
+	changes, err := live.AddSpec("users", liveSpecOf(t, "/users", "/groups"), apiCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SpecChanges{Added: []string{"get__groups", "get__users"}, Updated: []string{}, Removed: []string{}}); !reflect.DeepEqual(changes, want) {
+		t.Errorf("add changes = %+v, want %+v", changes, want)
+	}
+	if _, err := live.AddSpec("users", liveSpecOf(t, "/users"), apiCfg); err == nil {
+		t.Error("a second spec with the same id was added")
+	}
+
+	changes, err = live.ReloadSpec("users", liveSpecOf(t, "/users", "/roles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SpecChanges{Added: []string{"get__roles"}, Updated: []string{"get__users"}, Removed: []string{"get__groups"}}); !reflect.DeepEqual(changes, want) {
+		t.Errorf("reload changes = %+v, want %+v", changes, want)
+	}
+	if got := listedToolNames(mcpServer); !slices.Equal(got, []string{"get__roles", "get__users"}) {
+		t.Errorf("listed tools after the reload = %v", got)
+	}
+
+	if _, err := live.AddSpec("pets", liveSpecOf(t, "/pets"), apiCfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := live.ReloadSpec("pets", liveSpecOf(t, "/pets", "/users")); err == nil || !strings.Contains(err.Error(), "already registered by spec users") {
+		t.Errorf("reloading a spec with a taken tool name returned %v", err)
+	}
+	if got := live.Specs(); !reflect.DeepEqual(got, map[string][]string{"users": {"get__roles", "get__users"}, "pets": {"get__pets"}}) {
+		t.Errorf("specs = %v", got)
+	}
+
+	changes, err = live.RemoveSpec("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SpecChanges{Added: []string{}, Updated: []string{}, Removed: []string{"get__roles", "get__users"}}); !reflect.DeepEqual(changes, want) {
+		t.Errorf("remove changes = %+v, want %+v", changes, want)
+	}
+	if got := listedToolNames(mcpServer); !slices.Equal(got, []string{"get__pets"}) {
+		t.Errorf("listed tools after the removal = %v", got)
+	}
+	if _, err := live.RemoveSpec("users"); err == nil {
+		t.Error("a removed spec was removed again")
+	}
+	if _, err := live.ReloadSpec("users", liveSpecOf(t, "/users")); err == nil {
+		t.Error("a removed spec was reloaded")
+	}
+}
+
+func TestLiveServerRefusals(t *testing.T) {
+	apiCfg := models.ApiConfig{BaseUrl: "https://api.example.com"}
+	tests := []struct {
+		name    string
+		spec    []string
+		apiCfg  models.ApiConfig
+		wantErr string
+	}{
+		{"tool name taken by another spec", []string{"/users", "/teams"}, apiCfg, "already registered by spec users"},
+		{"invalid tool name template", []string{"/teams"}, models.ApiConfig{ToolNameTemplate: "{{.Path"}, "tool name template"},
+		{"invalid maintenance windows", []string{"/teams"}, models.ApiConfig{MaintenanceWindows: "0 18 * * 5"}, "maintenance windows"},
+		{"invalid body size", []string{"/teams"}, models.ApiConfig{MaxBodySize: "lots"}, "body size"},
+		{"unknown timezone", []string{"/teams"}, models.ApiConfig{Timezone: "Nowhere/Atlantis"}, "timezone"},
+		{"missing download root", []string{"/teams"}, models.ApiConfig{DownloadRoots: "/does/not/exist"}, "download roots"},
+		{"missing tool manifest", []string{"/teams"}, models.ApiConfig{ToolManifest: "/does/not/exist.json"}, "tool manifest"},
+		{"invalid URL rewrite", []string{"/teams"}, models.ApiConfig{UrlRewrites: "("}, "URL rewrites"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mcpServer := server.NewMCPServer("test", "1.0.0")
+			live := NewLiveServer(mcpServer)
+			if _, err := live.AddSpec("users", liveSpecOf(t, "/users"), apiCfg); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := live.AddSpec("teams", liveSpecOf(t, test.spec...), test.apiCfg)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("error = %v, want one about %s", err, test.wantErr)
+			}
+			if got := listedToolNames(mcpServer); !slices.Equal(got, []string{"get__users"}) {
+				t.Errorf("listed tools after the refusal = %v", got)
+			}
+			if _, registered := live.Specs()["teams"]; registered {
+				t.Error("the refused spec is registered")
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxWindowDuration bounds how far back window starts are searched for.
+const maxWindowDuration = 31 * 24 * time.Hour
+
+// cronField is the set of values a field of a cron expression matches.
+type cronField map[int]bool
+
+// maintenanceWindow is a freeze period starting at the times matched by a
+// cron expression and lasting duration.
+type maintenanceWindow struct {
+	spec     string
+	location *time.Location
+	fields   [5]cronField // minute, hour, day of month, month, day of week
+	duration time.Duration
+}
+
+var cronRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// parseMaintenanceWindows parses windows in "[CRON_TZ=zone] minute hour day month weekday duration"
+// format, separated by semicolons, e.g. "0 18 * * 5 60h" for Friday 18:00 to Monday 06:00.
+func parseMaintenanceWindows(windows string) ([]maintenanceWindow, error) {
+	parsed := []maintenanceWindow{}
+	for _, spec := range strings.Split(windows, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		window := maintenanceWindow{spec: spec, location: time.Local}
+		fields := strings.Fields(spec)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "CRON_TZ=") {
+			location, err := time.LoadLocation(strings.TrimPrefix(fields[0], "CRON_TZ="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid maintenance window %q: %v", spec, err)
+			}
+			window.location, fields = location, fields[1:]
+		}
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid maintenance window %q: expected 5 cron fields and a duration", spec)
+		}
+		for i := range window.fields {
+			field, err := parseCronField(fields[i], cronRanges[i][0], cronRanges[i][1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid maintenance window %q: %v", spec, err)
+			}
+			window.fields[i] = field
+		}
+		// Sunday may be written as 7
+		if window.fields[4][7] {
+			window.fields[4][0] = true
+		}
+		duration, err := time.ParseDuration(fields[5])
+		if err != nil || duration <= 0 || duration > maxWindowDuration {
+			return nil, fmt.Errorf("invalid maintenance window %q: the duration must be between 1m and 744h", spec)
+		}
+		window.duration = duration
+		parsed = append(parsed, window)
+	}
+	return parsed, nil
+}
+
+// parseCronField parses a cron field: *, values, ranges and steps such as 1-5 or */15.
+func parseCronField(field string, min, max int) (cronField, error) {
+	values := cronField{}
+	if max == 6 {
+		max = 7
+	}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", field)
+			}
+		}
+		low, high := min, max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return nil, fmt.Errorf("invalid value in %q", field)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highPart); err != nil {
+					return nil, fmt.Errorf("invalid range in %q", field)
+				}
+			} else if hasStep {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("%q is out of range %d-%d", field, min, max)
+		}
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func (w maintenanceWindow) matches(t time.Time) bool {
+	return w.fields[0][t.Minute()] && w.fields[1][t.Hour()] && w.fields[2][t.Day()] &&
+		w.fields[3][int(t.Month())] && w.fields[4][int(t.Weekday())]
+}
+
+// activeUntil returns the end of the window when now is inside it.
+func (w maintenanceWindow) activeUntil(now time.Time) (time.Time, bool) {
+	now = now.In(w.location).Truncate(time.Minute)
+	for start := now; now.Sub(start) < w.duration; start = start.Add(-time.Minute) {
+		if w.matches(start) {
+			return start.Add(w.duration), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// wrapMaintenance refuses calls of a mutating tool while a maintenance window is active.
+func wrapMaintenance(windows []maintenanceWindow, method string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if !isMutatingMethod(method) {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		now := time.Now()
+		for _, window := range windows {
+			if end, active := window.activeUntil(now); active {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] changes are frozen during the maintenance window %q until %s, this tool modifies data and is refused. Do not retry before then.", window.spec, end.Format(time.RFC3339))), nil
+			}
+		}
+		return handler(ctx, request)
+	}
+}
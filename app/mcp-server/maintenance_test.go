@@ -0,0 +1,71 @@
+package mcpserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	tests := []struct {
+		name     string
+		windows  string
+		count    int
+		duration time.Duration
+		wantErr  bool
+	}{
+		{"empty", "", 0, 0, false},
+		{"weekend", "0 18 * * 5 60h", 1, 60 * time.Hour, false},
+		{"several with a zone", "CRON_TZ=UTC 0 2 * * * 1h; */15 9-17 1,15 * 1-5 5m", 2, time.Hour, false},
+		{"sunday as 7", "0 0 * * 7 2h", 1, 2 * time.Hour, false},
+		{"missing duration", "0 18 * * 5", 0, 0, true},
+		{"minute out of range", "60 18 * * 5 1h", 0, 0, true},
+		{"reversed range", "0 18-9 * * * 1h", 0, 0, true},
+		{"zero step", "*/0 * * * * 1h", 0, 0, true},
+		{"duration too long", "0 0 1 * * 800h", 0, 0, true},
+		{"unknown zone", "CRON_TZ=Nowhere/City 0 0 * * * 1h", 0, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			windows, err := parseMaintenanceWindows(test.windows)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error %v", err, test.wantErr)
+			}
+			if len(windows) != test.count {
+				t.Fatalf("got %d windows, want %d", len(windows), test.count)
+			}
+			if test.count > 0 && windows[0].duration != test.duration {
+				t.Errorf("duration = %v, want %v", windows[0].duration, test.duration)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowActiveUntil(t *testing.T) {
+	windows, err := parseMaintenanceWindows("CRON_TZ=UTC 0 18 * * 5 60h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := windows[0]
+	friday := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		now    time.Time
+		active bool
+	}{
+		{"before the start", friday.Add(-time.Minute), false},
+		{"at the start", friday, true},
+		{"sunday", friday.Add(40 * time.Hour), true},
+		{"at the end", friday.Add(60 * time.Hour), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			end, active := window.activeUntil(test.now)
+			if active != test.active {
+				t.Fatalf("active = %v, want %v", active, test.active)
+			}
+			if active && !end.Equal(friday.Add(60*time.Hour)) {
+				t.Errorf("end = %v, want %v", end, friday.Add(60*time.Hour))
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolManifest lists the approved tools with a hash of their definition.
+// Tools missing from the manifest or whose definition changed are not registered.
+type ToolManifest struct {
+	Tools     map[string]string `json:"tools"`               // tool name => sha256 of the tool definition
+	Signature string            `json:"signature,omitempty"` // HMAC-SHA256 of the tools, when signed with a key
+}
+
+// toolHash hashes the name, description, input schema and annotations of a tool.
+func toolHash(tool mcp.Tool) (string, error) {
+	// the required list is built from map iteration, sort it so the hash is stable
+	tool.InputSchema.Required = slices.Clone(tool.InputSchema.Required)
+	slices.Sort(tool.InputSchema.Required)
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (m *ToolManifest) signature(key string) (string, error) {
+	data, err := json.Marshal(m.Tools)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// LoadToolManifest reads the manifest at path and checks its signature when key is set.
+func LoadToolManifest(path, key string) (*ToolManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool manifest: %v", err)
+	}
+	var manifest ToolManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid tool manifest: %v", err)
+	}
+	if key != "" {
+		expected, err := manifest.signature(key)
+		if err != nil {
+			return nil, err
+		}
+		if !hmac.Equal([]byte(expected), []byte(manifest.Signature)) {
+			return nil, fmt.Errorf("tool manifest signature does not match")
+		}
+	}
+	return &manifest, nil
+}
+
+// check returns why tool is not approved, or nil when it is.
+func (m *ToolManifest) check(tool mcp.Tool) error {
+	approved, ok := m.Tools[tool.Name]
+	if !ok {
+		return fmt.Errorf("not in the tool manifest")
+	}
+	hash, err := toolHash(tool)
+	if err != nil {
+		return err
+	}
+	if hash != approved {
+		return fmt.Errorf("definition changed since the manifest was approved")
+	}
+	return nil
+}
+
+// ExportToolManifest builds the manifest of the tools generated from swaggerSpec,
+// the elevated ones included, signed with key when it is set.
+func ExportToolManifest(swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig, key string) ([]byte, error) {
+	apiCfg.ToolManifest = ""
+	mcpServer := server.NewMCPServer("swagegr-mcp", "1.0.0")
+	LoadSwaggerServer(mcpServer, swaggerSpec, apiCfg)
+	defer elevatedTools.forget(mcpServer)
+
+	response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	rpcResponse, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		return nil, fmt.Errorf("failed to list tools")
+	}
+	toolList, ok := rpcResponse.Result.(mcp.ListToolsResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tool list result")
+	}
+
+	manifest := ToolManifest{Tools: map[string]string{}}
+	// elevated tools are checked against the manifest like the listed ones
+	for _, tool := range append(toolList.Tools, elevatedTools.toolsOf(mcpServer)...) {
+		hash, err := toolHash(tool)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Tools[tool.Name] = hash
+	}
+	if key != "" {
+		signature, err := manifest.signature(key)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Signature = signature
+	}
+	return json.MarshalIndent(manifest, "", "  ")
+}
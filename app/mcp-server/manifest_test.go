@@ -0,0 +1,40 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+func TestExportToolManifestIncludesElevatedTools(t *testing.T) {
+	var spec models.SwaggerSpec
+	if err := json.Unmarshal([]byte(`{
+		"swagger": "2.0",
+		"host": "api.example.com",
+		"paths": {
+			"/users": {"get": {"operationId": "listUsers", "summary": "List users", "responses": {"200": {"description": "ok"}}}},
+			"/users/{id}": {"delete": {"operationId": "deleteUser", "summary": "Delete a user", "parameters": [{"name": "id", "in": "path", "required": true, "type": "string"}], "responses": {"204": {"description": "deleted"}}}}
+		}
+	}`), &spec); err != nil {
+		t.Fatal(err)
+	}
+	apiCfg := models.ApiConfig{}
+	names, _ := operationToolNames(apiCfg, spec)
+	listName, deleteName := names["/users"]["get"], names["/users/{id}"]["delete"]
+	apiCfg.ElevatedTools = "^" + deleteName + "$"
+
+	data, err := ExportToolManifest(spec, apiCfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ToolManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{listName, deleteName} {
+		if manifest.Tools[name] == "" {
+			t.Errorf("the manifest has no hash for %s: %v", name, manifest.Tools)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// upstreamCall is filled in by a tool handler with the backend it called and
+// the status it answered, so the call can be counted by outcome.
+type upstreamCall struct {
+	mu     sync.Mutex
+	host   string
+	status int
+}
+
+type upstreamCallKey struct{}
+
+// withUpstreamCall returns ctx carrying the upstreamCall the tool handler
+// fills in, the one of an outer wrapper when there is one so every wrapper
+// counting calls sees the same outcome.
+func withUpstreamCall(ctx context.Context) (context.Context, *upstreamCall) {
+	if call, ok := ctx.Value(upstreamCallKey{}).(*upstreamCall); ok {
+		return ctx, call
+	}
+	call := &upstreamCall{}
+	return context.WithValue(ctx, upstreamCallKey{}, call), call
+}
+
+// noteUpstream records the backend host a tool call is about to send its request to.
+func noteUpstream(ctx context.Context, host string) {
+	if call, ok := ctx.Value(upstreamCallKey{}).(*upstreamCall); ok {
+		call.mu.Lock()
+		call.host = host
+		call.mu.Unlock()
+	}
+}
+
+// noteUpstreamStatus records the HTTP status the backend answered with.
+func noteUpstreamStatus(ctx context.Context, status int) {
+	if call, ok := ctx.Value(upstreamCallKey{}).(*upstreamCall); ok {
+		call.mu.Lock()
+		call.status = status
+		call.mu.Unlock()
+	}
+}
+
+// callCounterKey identifies one counter of the tool call metrics.
+type callCounterKey struct {
+	tool        string
+	host        string
+	statusClass string
+}
+
+// callMetrics counts the tool calls by tool, backend host and outcome: the
+// status class of the backend response (2xx to 5xx), network when no response
+// came back, and validation when the call was refused before anything was sent.
+type callMetrics struct {
+	mu       sync.Mutex
+	counters map[callCounterKey]uint64
+}
+
+var toolCallMetrics = &callMetrics{counters: map[callCounterKey]uint64{}}
+
+// statusClass returns the outcome label of a call.
+func statusClass(call *upstreamCall, result *mcp.CallToolResult, err error) string {
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	switch {
+	case call.status > 0:
+		return fmt.Sprintf("%dxx", call.status/100)
+	case call.host != "":
+		return "network"
+	case err != nil || result == nil || result.IsError:
+		return "validation"
+	}
+	return "local"
+}
+
+// wrap counts the calls of handler under toolName.
+func (m *callMetrics) wrap(toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, call := withUpstreamCall(ctx)
+		result, err := handler(ctx, request)
+		class := statusClass(call, result, err)
+		call.mu.Lock()
+		key := callCounterKey{tool: toolName, host: call.host, statusClass: class}
+		call.mu.Unlock()
+		m.mu.Lock()
+		m.counters[key]++
+		m.mu.Unlock()
+		return result, err
+	}
+}
+
+// escapeLabel escapes a label value of the text exposition formats.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// write renders the counters in the OpenMetrics text format, or in the
+// Prometheus text format the OpenMetrics one is compatible with.
+func (m *callMetrics) write(w http.ResponseWriter, openMetrics bool) {
+	m.mu.Lock()
+	keys := make([]callCounterKey, 0, len(m.counters))
+	for key := range m.counters {
+		keys = append(keys, key)
+	}
+	values := make(map[callCounterKey]uint64, len(keys))
+	for _, key := range keys {
+		values[key] = m.counters[key]
+	}
+	m.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tool != keys[j].tool {
+			return keys[i].tool < keys[j].tool
+		}
+		if keys[i].host != keys[j].host {
+			return keys[i].host < keys[j].host
+		}
+		return keys[i].statusClass < keys[j].statusClass
+	})
+
+	var out strings.Builder
+	name := "swagger_mcp_tool_calls"
+	// OpenMetrics names the counter family without its _total sample suffix
+	family := name + "_total"
+	if openMetrics {
+		family = name
+	}
+	fmt.Fprintf(&out, "# HELP %s Tool calls by tool, backend host and status class of the backend response (network: no response, validation: refused before sending).\n", family)
+	fmt.Fprintf(&out, "# TYPE %s counter\n", family)
+	for _, key := range keys {
+		fmt.Fprintf(&out, "%s_total{tool=\"%s\",host=\"%s\",status_class=\"%s\"} %d\n", name, escapeLabel(key.tool), escapeLabel(key.host), key.statusClass, values[key])
+	}
+	if openMetrics {
+		out.WriteString("# EOF\n")
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	}
+	w.Write([]byte(out.String()))
+}
+
+// serveMetrics serves the tool call counters on addr at /metrics.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		toolCallMetrics.write(w, strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text"))
+	})
+	log.Printf("Serving metrics on %s/metrics", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Metrics server error: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package mcpserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// maxToolNameLength is the longest tool name MCP clients accept.
+const maxToolNameLength = 64
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// toolNameFields are the fields of an operation a tool name template uses.
+type toolNameFields struct {
+	Method      string // lower case, e.g. get
+	Path        string // e.g. /users/{id}
+	OperationId string
+	Tag         string // first tag of the operation
+	Version     string // version path segment, e.g. v2
+	Default     string // the method_path name
+}
+
+var toolNameTemplates sync.Map
+
+func buildToolName(method, path string) string {
+	pathWithoutDot := strings.ReplaceAll(path, "/", "_")
+
+	toolName := fmt.Sprintf("%s_%s", method, strings.ReplaceAll(strings.ReplaceAll(pathWithoutDot, "}", ""), "{", ""))
+
+	if len(toolName) >= 40 {
+		toolName = toolName[:40]
+
+	}
+	return toolName
+}
+
+// sanitizeToolName turns name into a valid tool name: letters, digits, _ and
+// -, at most maxToolNameLength long. Other characters become underscores and
+// longer names are cut and end with a hash of the whole name, so names
+// sharing a long prefix stay apart.
+func sanitizeToolName(name string) string {
+	name = strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > maxToolNameLength {
+		name = hashSuffixed(name, name)
+	}
+	return name
+}
+
+// hashSuffixed appends a short hash of key to name, cutting name so the
+// whole fits in maxToolNameLength.
+func hashSuffixed(name, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	if len(name) > maxToolNameLength-len(suffix) {
+		name = strings.TrimRight(name[:maxToolNameLength-len(suffix)], "_")
+	}
+	return name + suffix
+}
+
+// parseToolNameTemplate parses a tool name template, once per text, and
+// checks it against the fields it may use.
+func parseToolNameTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := toolNameTemplates.Load(text); ok {
+		return tmpl.(*template.Template), nil
+	}
+	tmpl, err := template.New("toolName").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool name template %q: %v", text, err)
+	}
+	if err := tmpl.Execute(new(bytes.Buffer), toolNameFields{}); err != nil {
+		return nil, fmt.Errorf("invalid tool name template %q: %v", text, err)
+	}
+	toolNameTemplates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// templateToolName executes the tool name template of apiCfg for an
+// operation, empty when the name it gives is.
+func templateToolName(apiCfg models.ApiConfig, method, path string, details models.Endpoint) string {
+	tmpl, err := parseToolNameTemplate(apiCfg.ToolNameTemplate)
+	if err != nil {
+		return ""
+	}
+	fields := toolNameFields{Method: method, Path: path, OperationId: details.OperationID, Default: buildToolName(method, path)}
+	if len(details.Tags) > 0 {
+		fields.Tag = details.Tags[0]
+	}
+	fields.Version, _ = pathVersion(path)
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, fields); err != nil {
+		return ""
+	}
+	return sanitizeToolName(name.String())
+}
+
+// toolNameFor returns the tool name of an operation: the one of the tool name
+// template when set, its operationId with operationIdNames, otherwise
+// method_path. With versioned tools the version moves to the end of
+// method_path names, e.g. get__users_id_v2, so it survives the truncation of
+// long names.
+func toolNameFor(apiCfg models.ApiConfig, method, path string, details models.Endpoint) string {
+	if apiCfg.ToolNameTemplate != "" {
+		if name := templateToolName(apiCfg, method, path, details); name != "" {
+			return name
+		}
+	}
+	if apiCfg.OperationIdNames {
+		if name := sanitizeToolName(details.OperationID); name != "" {
+			return name
+		}
+	}
+	if apiCfg.VersionedTools {
+		if version, unversioned := pathVersion(path); version != "" {
+			return buildToolName(method, unversioned) + "_" + strings.ReplaceAll(version, ".", "_")
+		}
+	}
+	return buildToolName(method, path)
+}
+
+// operationToolNames returns the tool names of all the operations of the
+// spec, by path and method, unique so no tool replaces another. When
+// operations get the same name, the first one in METHOD path order keeps it
+// and the others get a hash of their method and path appended, which stays
+// the same from one run to the next. renamed tells which operations were
+// renamed.
+func operationToolNames(apiCfg models.ApiConfig, swaggerSpec models.SwaggerSpec) (names map[string]map[string]string, renamed []string) {
+	type operation struct{ path, method string }
+	operations := []operation{}
+	for path, methods := range swaggerSpec.Paths {
+		for method := range methods {
+			operations = append(operations, operation{path, method})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].method != operations[j].method {
+			return strings.ToUpper(operations[i].method) < strings.ToUpper(operations[j].method)
+		}
+		return operations[i].path < operations[j].path
+	})
+	// the plain names are all taken first, a suffixed name must not take one
+	plain := make([]string, len(operations))
+	counts := map[string]int{}
+	for i, op := range operations {
+		plain[i] = toolNameFor(apiCfg, op.method, op.path, swaggerSpec.Paths[op.path][op.method])
+		counts[plain[i]]++
+	}
+	names = map[string]map[string]string{}
+	taken := map[string]bool{}
+	for i, op := range operations {
+		name := plain[i]
+		if taken[name] {
+			key := strings.ToUpper(op.method) + " " + op.path
+			name = hashSuffixed(plain[i], key)
+			for attempt := 2; taken[name] || counts[name] > 0; attempt++ {
+				name = hashSuffixed(plain[i], fmt.Sprintf("%s#%d", key, attempt))
+			}
+			renamed = append(renamed, fmt.Sprintf("%s %s is renamed %s, %s is taken", strings.ToUpper(op.method), op.path, name, plain[i]))
+		}
+		taken[name] = true
+		if names[op.path] == nil {
+			names[op.path] = map[string]string{}
+		}
+		names[op.path][op.method] = name
+	}
+	return names, renamed
+}
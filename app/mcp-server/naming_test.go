@@ -0,0 +1,87 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+func namingSpec(t *testing.T, paths string) models.SwaggerSpec {
+	t.Helper()
+	var spec models.SwaggerSpec
+	if err := json.Unmarshal([]byte(`{"swagger": "2.0", "paths": `+paths+`}`), &spec); err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+func TestOperationToolNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiCfg      models.ApiConfig
+		paths       string
+		want        map[string]string // "METHOD path" => name, a trailing * matches a hash suffix
+		wantRenamed int
+	}{
+		{
+			name:  "method and path",
+			paths: `{"/users": {"get": {}, "post": {}}, "/users/{id}": {"get": {}}}`,
+			want:  map[string]string{"get /users": "get__users", "post /users": "post__users", "get /users/{id}": "get__users_id"},
+		},
+		{
+			name:        "truncated paths colliding",
+			paths:       `{"/organizations/departments/employees/list": {"get": {}}, "/organizations/departments/employees/listing": {"get": {}}}`,
+			want:        map[string]string{"get /organizations/departments/employees/list": "get__organizations_departments_employees", "get /organizations/departments/employees/listing": "get__organizations_departments_employees_*"},
+			wantRenamed: 1,
+		},
+		{
+			name:   "operationIds",
+			apiCfg: models.ApiConfig{OperationIdNames: true},
+			paths:  `{"/users": {"get": {"operationId": "list.users"}, "post": {}}}`,
+			want:   map[string]string{"get /users": "list_users", "post /users": "post__users"},
+		},
+		{
+			name:        "duplicate operationIds",
+			apiCfg:      models.ApiConfig{OperationIdNames: true},
+			paths:       `{"/a": {"get": {"operationId": "fetch"}}, "/b": {"get": {"operationId": "fetch"}}}`,
+			want:        map[string]string{"get /a": "fetch", "get /b": "fetch_*"},
+			wantRenamed: 1,
+		},
+		{
+			name:   "template",
+			apiCfg: models.ApiConfig{ToolNameTemplate: "{{.Tag}}_{{.OperationId}}"},
+			paths:  `{"/pets": {"get": {"operationId": "listPets", "tags": ["pets"]}}}`,
+			want:   map[string]string{"get /pets": "pets_listPets"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := namingSpec(t, test.paths)
+			names, renamed := operationToolNames(test.apiCfg, spec)
+			if len(renamed) != test.wantRenamed {
+				t.Errorf("renamed = %v, want %d renames", renamed, test.wantRenamed)
+			}
+			for operation, want := range test.want {
+				method, path, _ := strings.Cut(operation, " ")
+				got := names[path][method]
+				if prefix, suffixed := strings.CutSuffix(want, "*"); suffixed {
+					if !strings.HasPrefix(got, prefix) || len(got) != len(prefix)+8 {
+						t.Errorf("%s is named %q, want %s and a hash", operation, got, prefix)
+					}
+				} else if got != want {
+					t.Errorf("%s is named %q, want %q", operation, got, want)
+				}
+			}
+			again, _ := operationToolNames(test.apiCfg, spec)
+			for path, methods := range names {
+				for method, name := range methods {
+					if again[path][method] != name {
+						t.Errorf("%s %s is named %s then %s", method, path, name, again[path][method])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package mcpserver
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxNestedDepth bounds the expansion of recursive body schemas.
+const maxNestedDepth = 8
+
+// lookupDefinition returns the schema a local $ref points to.
+func lookupDefinition(swaggerSpec models.SwaggerSpec, ref string) (models.Definition, bool) {
+	name := ExtractSchemaName(ref, "")
+	if definition, found := swaggerSpec.Definitions[name]; found {
+		return definition, true
+	}
+	if swaggerSpec.Components != nil {
+		definition, found := swaggerSpec.Components.Schemas[name]
+		return definition, found
+	}
+	return models.Definition{}, false
+}
+
+// bodyDefinition returns the schema of a request body declared as a $ref,
+// inline, composed with allOf, or made of oneOf/anyOf variants.
+func bodyDefinition(swaggerSpec models.SwaggerSpec, schema *models.SchemaRef) (models.Definition, bool) {
+	if variants := bodyVariantsOf(swaggerSpec, schema); variants != nil {
+		// the properties of all the variants, bodyVariants picks the ones sent
+		return variants.union, true
+	}
+	if schema.Ref != "" {
+		return lookupDefinition(swaggerSpec, schema.Ref)
+	}
+	if len(schema.AllOf) == 0 && len(schema.Properties) == 0 {
+		return models.Definition{}, false
+	}
+	// an inline schema is merged as the only part
+	merged := models.Definition{}
+	parts := append(slices.Clone(schema.AllOf), &models.SchemaRef{Properties: schema.Properties, Required: schema.Required})
+	for _, part := range parts {
+		if part.Ref != "" || len(part.AllOf) > 0 {
+			if definition, found := bodyDefinition(swaggerSpec, part); found {
+				merged.Merge(definition)
+			}
+			continue
+		}
+		inline := models.Definition{Type: part.Type, Properties: map[string]models.Property{}, Required: part.Required}
+		for propName, prop := range part.Properties {
+			inline.Properties[propName] = schemaProperty(prop)
+		}
+		merged.Merge(inline)
+	}
+	if merged.Type == "" {
+		merged.Type = "object"
+	}
+	return merged, len(merged.Properties) > 0
+}
+
+// wholeBody returns the request body schema of an operation whose fields
+// could not be read, to be sent whole as one argument: an array, a free-form
+// object or a $ref to a schema the spec does not define. name is the argument
+// name, the one of the Swagger 2.0 body parameter or body, and JSON content
+// types come first.
+func wholeBody(details models.Endpoint) (name string, schema *models.SchemaRef, contentType string) {
+	for _, param := range details.Parameters {
+		if param.In == "body" && param.Schema != nil {
+			contentType = "application/json"
+			for _, consumed := range details.Consumes {
+				if strings.Contains(consumed, "json") {
+					contentType = consumed
+					break
+				}
+			}
+			return param.Name, param.Schema, contentType
+		}
+	}
+	if details.RequestBody == nil {
+		return "", nil, ""
+	}
+	contentTypes := make([]string, 0, len(details.RequestBody.Content))
+	for contentType, mediaType := range details.RequestBody.Content {
+		if mediaType.Schema != nil {
+			contentTypes = append(contentTypes, contentType)
+		}
+	}
+	sort.SliceStable(contentTypes, func(i, j int) bool {
+		iJSON, jJSON := strings.Contains(contentTypes[i], "json"), strings.Contains(contentTypes[j], "json")
+		if iJSON != jJSON {
+			return iJSON
+		}
+		return contentTypes[i] < contentTypes[j]
+	})
+	if len(contentTypes) == 0 {
+		return "", nil, ""
+	}
+	return "body", details.RequestBody.Content[contentTypes[0]].Schema, contentTypes[0]
+}
+
+// wholeBodyOption builds the argument carrying a whole request body with the
+// structure known of it, an object when nothing is.
+func wholeBodyOption(swaggerSpec models.SwaggerSpec, name string, schema *models.SchemaRef, required bool) mcp.ToolOption {
+	prop := resolveProperty(swaggerSpec, schemaProperty(schema))
+	description := "The request body, sent as JSON"
+	if prop.Type == "" {
+		if prop.Ref != "" {
+			description += fmt.Sprintf(". Its schema %s is not defined in the spec, pass the fields the API expects", ExtractSchemaName(prop.Ref, ""))
+		}
+		prop.Type, prop.Ref = "object", ""
+	}
+	propOptions := []mcp.PropertyOption{mcp.Description(description)}
+	if required {
+		propOptions = append(propOptions, mcp.Required())
+	}
+	return bodyPropertyOption(swaggerSpec, name, prop, propOptions)
+}
+
+// schemaProperty converts an inline schema to a body property.
+func schemaProperty(schema *models.SchemaRef) models.Property {
+	prop := models.Property{
+		Type:        schema.Type,
+		Title:       schema.Title,
+		Format:      schema.Format,
+		Description: schema.Description,
+		Enum:        schema.Enum,
+		Example:     schema.Example,
+		Default:     schema.Default,
+		Nullable:    schema.Nullable,
+		Ref:         schema.Ref,
+		Required:    schema.Required,
+	}
+	if len(schema.Properties) > 0 {
+		prop.Properties = map[string]models.Property{}
+		for propName, nested := range schema.Properties {
+			prop.Properties[propName] = schemaProperty(nested)
+		}
+	}
+	if schema.Items != nil {
+		items := schemaProperty(schema.Items)
+		prop.Items = &items
+	}
+	for _, part := range schema.AllOf {
+		prop.AllOf = append(prop.AllOf, schemaProperty(part))
+	}
+	return prop
+}
+
+// resolveProperty fills in a property declared as a $ref with the type,
+// properties and required list of the schema it points to. A property
+// composed with allOf gets the properties of all its parts.
+func resolveProperty(swaggerSpec models.SwaggerSpec, prop models.Property) models.Property {
+	if prop.Ref == "" && len(prop.AllOf) == 1 && prop.AllOf[0].Ref != "" {
+		// allOf with a single $ref, the usual way to describe a referenced property
+		prop.Ref = prop.AllOf[0].Ref
+		prop.AllOf = nil
+	}
+	if prop.Ref == "" && len(prop.AllOf) > 0 {
+		merged := models.Definition{}
+		for _, part := range prop.AllOf {
+			part = resolveProperty(swaggerSpec, part)
+			merged.Merge(models.Definition{Type: part.Type, Properties: part.Properties, Required: part.Required})
+		}
+		merged.Merge(models.Definition{Properties: prop.Properties, Required: prop.Required})
+		prop.Type, prop.Properties, prop.Required, prop.AllOf = "object", merged.Properties, merged.Required, nil
+		return prop
+	}
+	if prop.Ref == "" {
+		return prop
+	}
+	definition, found := lookupDefinition(swaggerSpec, prop.Ref)
+	if !found {
+		return prop
+	}
+	prop.Type = definition.Type
+	if prop.Type == "" {
+		prop.Type = "object"
+	}
+	prop.Properties = definition.Properties
+	prop.Required = definition.Required
+	return prop
+}
+
+// nestedSchema returns the JSON schema of a property, with its nested object
+// properties and array items expanded. expanding lists the refs being expanded,
+// a recursive schema stops where it refers to itself.
+func nestedSchema(swaggerSpec models.SwaggerSpec, prop models.Property, depth int, expanding []string) map[string]interface{} {
+	ref := prop.Ref
+	prop = resolveProperty(swaggerSpec, prop)
+	schemaType := prop.Type
+	if schemaType == "" && len(prop.Properties) > 0 {
+		schemaType = "object"
+	}
+	schema := map[string]interface{}{}
+	if schemaType != "" {
+		schema["type"] = schemaType
+	}
+	if prop.Title != "" {
+		schema["title"] = prop.Title
+	}
+	if prop.Description != "" {
+		schema["description"] = prop.Description
+	}
+	if prop.Format != "" {
+		schema["format"] = prop.Format
+	}
+	if len(prop.Enum) > 0 {
+		schema["enum"] = prop.Enum
+	}
+	if prop.Example != nil {
+		schema["example"] = prop.Example
+	}
+	if prop.Default != nil {
+		schema["default"] = prop.Default
+	}
+	if prop.IsNullable() && schemaType != "" {
+		schema["type"] = []string{schemaType, "null"}
+	}
+	if slices.Contains(expanding, ref) {
+		if prop.Description == "" {
+			schema["description"] = fmt.Sprintf("Same structure as %s", ExtractSchemaName(ref, ""))
+		}
+		return schema
+	}
+	if depth >= maxNestedDepth {
+		// deeper levels are left open
+		return schema
+	}
+	if ref != "" {
+		expanding = append(expanding, ref)
+	}
+	switch schemaType {
+	case "object":
+		properties := map[string]interface{}{}
+		for propName, nested := range prop.Properties {
+			properties[propName] = nestedSchema(swaggerSpec, nested, depth+1, expanding)
+		}
+		schema["properties"] = properties
+		if len(prop.Required) > 0 {
+			schema["required"] = prop.Required
+		}
+	case "array":
+		if prop.Items != nil {
+			schema["items"] = nestedSchema(swaggerSpec, *prop.Items, depth+1, expanding)
+		}
+	}
+	return schema
+}
+
+// isNestedType reports whether a body property is sent as structured JSON.
+func isNestedType(schemaType string) bool {
+	return schemaType == "object" || schemaType == "array"
+}
+
+// bodyPropertyOption returns the tool argument of a resolved body property.
+// Objects and arrays become arguments of that type carrying their nested
+// schema, so clients pass the structure itself rather than a JSON string.
+func bodyPropertyOption(swaggerSpec models.SwaggerSpec, name string, prop models.Property, propOptions []mcp.PropertyOption) mcp.ToolOption {
+	if len(prop.Enum) > 0 && !isNestedType(prop.Type) {
+		allowed := prop.Enum
+		if prop.IsNullable() {
+			allowed = append(slices.Clone(allowed), nil)
+		}
+		propOptions = append(propOptions, enumOption(allowed))
+	}
+	if prop.Default != nil && !isNestedType(prop.Type) {
+		propOptions = append(propOptions, defaultOption(prop.Default))
+	}
+	switch prop.Type {
+	case "integer", "int":
+		// ahead of the other options, nullableOption builds on the type
+		integer := func(schema map[string]interface{}) {
+			schema["type"] = "integer"
+		}
+		return mcp.WithNumber(name, append([]mcp.PropertyOption{integer}, propOptions...)...)
+	case "number", "float":
+		return mcp.WithNumber(name, propOptions...)
+	case "boolean", "bool":
+		return mcp.WithBoolean(name, propOptions...)
+	}
+	if !isNestedType(prop.Type) {
+		return mcp.WithString(name, propOptions...)
+	}
+	nested := nestedSchema(swaggerSpec, prop, 0, nil)
+	options := []mcp.PropertyOption{func(schema map[string]interface{}) {
+		for key, value := range nested {
+			if key != "title" && key != "description" && key != "type" && key != "required" {
+				schema[key] = value
+			}
+		}
+	}}
+	options = append(options, propOptions...)
+	if prop.Type == "array" {
+		return mcp.WithArray(name, options...)
+	}
+	return func(tool *mcp.Tool) {
+		mcp.WithObject(name, options...)(tool)
+		// mcp.Required() marks the argument itself required through the same key,
+		// set the required fields of the object once it is done
+		if required, ok := nested["required"]; ok {
+			if schema, ok := tool.InputSchema.Properties[name].(map[string]interface{}); ok {
+				schema["required"] = required
+			}
+		}
+	}
+}
+
+// structuredArgument returns an object or array argument passed as JSON
+// rather than as a JSON string.
+func structuredArgument(arg interface{}, paramType string) (interface{}, bool) {
+	switch value := arg.(type) {
+	case map[string]interface{}:
+		return value, paramType == "object"
+	case []interface{}:
+		return value, paramType == "array"
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// requestFailure describes why an upstream request got no response at all.
+type requestFailure struct {
+	Kind string // dns, tls, refused, reset, timeout, cancelled or network
+	Hint string
+}
+
+// classifyRequestError tells DNS, TLS, connection and timeout failures apart.
+func classifyRequestError(err error) requestFailure {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return requestFailure{Kind: "cancelled", Hint: "the tool call was cancelled"}
+	case errors.As(err, &dnsErr):
+		return requestFailure{Kind: "dns", Hint: fmt.Sprintf("the host %s could not be resolved, check the host name in baseUrl", dnsErr.Name)}
+	case errors.As(err, &recordErr):
+		return requestFailure{Kind: "tls", Hint: "the backend did not answer with TLS, check whether baseUrl should use http:// instead of https://"}
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &invalidCert):
+		return requestFailure{Kind: "tls", Hint: "the backend certificate is not trusted or does not match the host, check the certificate or the host name in baseUrl"}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return requestFailure{Kind: "refused", Hint: "backend unreachable, nothing is listening there: check that it is running and the port in baseUrl"}
+	case errors.Is(err, syscall.ECONNRESET):
+		return requestFailure{Kind: "reset", Hint: "the backend closed the connection, it may be overloaded or restarting"}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return requestFailure{Kind: "timeout", Hint: "the backend did not answer in time, it may be slow or a firewall may drop the traffic"}
+	}
+	return requestFailure{Kind: "network", Hint: "the request could not be sent"}
+}
+
+// requestErrorResult is the tool result for an upstream request that failed
+// before any response was received.
+func requestErrorResult(err error) string {
+	failure := classifyRequestError(err)
+	fmt.Printf("Request failed (%s): %v\n", failure.Kind, err)
+	return fmt.Sprintf("[Error] failed to make HTTP request (%s): %s. Cause: %v", failure.Kind, failure.Hint, err)
+}
@@ -0,0 +1,103 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// outputSchema returns the JSON schema of the 2xx responses of an operation.
+// MCP requires structured content from every successful call of a tool with
+// an output schema, so the schema is nil unless every 2xx response declares
+// the same JSON object body.
+func outputSchema(swaggerSpec models.SwaggerSpec, details models.Endpoint) map[string]interface{} {
+	statuses := []string{}
+	for status := range details.Responses {
+		if code, err := strconv.Atoi(status); err == nil && code >= 200 && code < 300 || strings.EqualFold(status, "2XX") {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+	var output map[string]interface{}
+	for _, status := range statuses {
+		schema := responseSchema(details.Responses[status])
+		if schema == nil {
+			return nil
+		}
+		declared := nestedSchema(swaggerSpec, schemaProperty(schema), 0, nil)
+		if declared["type"] != "object" || output != nil && !reflect.DeepEqual(declared, output) {
+			return nil
+		}
+		output = declared
+	}
+	return output
+}
+
+// responseSchema returns the schema of the JSON body of a response, nil when
+// it declares none.
+func responseSchema(resp models.Response) *models.SchemaRef {
+	if resp.Schema != nil {
+		return resp.Schema
+	}
+	contentTypes := make([]string, 0, len(resp.Content))
+	for contentType := range resp.Content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+	for _, contentType := range contentTypes {
+		if strings.Contains(contentType, "json") && resp.Content[contentType].Schema != nil {
+			return resp.Content[contentType].Schema
+		}
+	}
+	return nil
+}
+
+// outputSchemaOption declares schema as the output schema of a tool.
+func outputSchemaOption(schema map[string]interface{}) mcp.ToolOption {
+	data, _ := json.Marshal(schema)
+	return mcp.WithRawOutputSchema(data)
+}
+
+// wrapStructuredOutput adds the JSON object a successful call answered with
+// to the result as its structured content, next to the text clients without
+// output schema support read. A result that has no such object, the API
+// answering with another status or a body that is not a JSON object, is
+// turned into an error, which MCP does not check against the output schema.
+func wrapStructuredOutput(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		responseStatus, ok := ctx.Value(responseStatusKey).(*int)
+		if !ok {
+			responseStatus = new(int)
+			ctx = context.WithValue(ctx, responseStatusKey, responseStatus)
+		}
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		// a revalidated cached response has the body of the earlier 2xx one
+		success := *responseStatus >= 200 && *responseStatus < 300 || *responseStatus == http.StatusNotModified
+		if success && len(result.Content) > 0 {
+			if first, ok := result.Content[0].(mcp.TextContent); ok {
+				var structured map[string]interface{}
+				if json.Unmarshal([]byte(first.Text), &structured) == nil && structured != nil {
+					result.StructuredContent = structured
+					return result, nil
+				}
+			}
+		}
+		result.IsError = true
+		if success {
+			result.Content = append([]mcp.Content{mcp.NewTextContent(fmt.Sprintf("[Error] the API answered HTTP %d without the JSON object the output schema of this tool declares, the call itself may have succeeded", *responseStatus))}, result.Content...)
+		}
+		return result, nil
+	}
+}
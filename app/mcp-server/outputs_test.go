@@ -0,0 +1,82 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestOutputSchema(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses string
+		want      bool
+	}{
+		{"one object response", `{"200": {"schema": {"$ref": "#/definitions/User"}}}`, true},
+		{"same object for every status", `{"200": {"schema": {"$ref": "#/definitions/User"}}, "201": {"schema": {"$ref": "#/definitions/User"}}, "404": {"description": "missing"}}`, true},
+		{"another object", `{"200": {"schema": {"$ref": "#/definitions/User"}}, "202": {"schema": {"$ref": "#/definitions/Job"}}}`, false},
+		{"no content", `{"200": {"schema": {"$ref": "#/definitions/User"}}, "204": {"description": "unchanged"}}`, false},
+		{"range", `{"200": {"schema": {"$ref": "#/definitions/User"}}, "2XX": {"description": "other"}}`, false},
+		{"array", `{"200": {"schema": {"type": "array", "items": {"$ref": "#/definitions/User"}}}}`, false},
+		{"no 2xx response", `{"default": {"schema": {"$ref": "#/definitions/User"}}}`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var spec models.SwaggerSpec
+			if err := json.Unmarshal([]byte(`{
+				"swagger": "2.0",
+				"paths": {"/users": {"post": {"responses": `+test.responses+`}}},
+				"definitions": {
+					"User": {"type": "object", "properties": {"id": {"type": "integer"}}},
+					"Job": {"type": "object", "properties": {"job": {"type": "string"}}}
+				}
+			}`), &spec); err != nil {
+				t.Fatal(err)
+			}
+			if got := outputSchema(spec, spec.Paths["/users"]["post"]); (got != nil) != test.want {
+				t.Errorf("outputSchema = %v, want a schema: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWrapStructuredOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		result         *mcp.CallToolResult
+		wantStructured bool
+		wantError      bool
+	}{
+		{"object", 200, mcp.NewToolResultText(`{"id": 1}`), true, false},
+		{"other success status", 201, mcp.NewToolResultText(`{"id": 1}`), true, false},
+		{"revalidated", 304, mcp.NewToolResultText(`{"id": 1}`), true, false},
+		{"array body", 200, mcp.NewToolResultText(`[{"id": 1}]`), false, true},
+		{"empty body", 204, mcp.NewToolResultText("Success, the API returned HTTP 204 with an empty body"), false, true},
+		{"truncated body", 200, mcp.NewToolResultText(`{"id": 1, "na`), false, true},
+		{"saved download", 200, mcp.NewToolResultText("Saved the 3 byte response to /tmp/x"), false, true},
+		{"error status", 404, mcp.NewToolResultText(`{"message": "not found"}`), false, true},
+		{"error result", 0, mcp.NewToolResultError("[Error] missing Path Parameter: id"), false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := wrapStructuredOutput(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				*ctx.Value(responseStatusKey).(*int) = test.status
+				return test.result, nil
+			})
+			result, err := handler(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (result.StructuredContent != nil) != test.wantStructured {
+				t.Errorf("structured content = %v, want some: %v", result.StructuredContent, test.wantStructured)
+			}
+			if result.IsError != test.wantError {
+				t.Errorf("error = %v, want %v", result.IsError, test.wantError)
+			}
+		})
+	}
+}
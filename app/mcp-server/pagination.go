@@ -0,0 +1,178 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// paginationState is the pagination metadata detected in a response.
+type paginationState struct {
+	Next          string            `json:"next,omitempty"`
+	Prev          string            `json:"prev,omitempty"`
+	First         string            `json:"first,omitempty"`
+	Last          string            `json:"last,omitempty"`
+	NextCursor    string            `json:"next_cursor,omitempty"`
+	TotalCount    *int64            `json:"total_count,omitempty"`
+	Page          *int64            `json:"page,omitempty"`
+	PerPage       *int64            `json:"per_page,omitempty"`
+	HasMore       *bool             `json:"has_more,omitempty"`
+	NextArguments map[string]string `json:"next_arguments,omitempty"` // tool arguments to pass to fetch the next page
+}
+
+var linkRelPattern = regexp.MustCompile(`<([^>]*)>\s*;\s*rel="?([^";]+)"?`)
+
+var (
+	cursorFields   = []string{"next_cursor", "nextCursor", "next_page_token", "nextPageToken", "cursor"}
+	totalFields    = []string{"total_count", "totalCount", "total", "totalElements", "total_results"}
+	pageFields     = []string{"page", "current_page", "currentPage", "pageNumber"}
+	perPageFields  = []string{"per_page", "perPage", "page_size", "pageSize", "limit"}
+	hasMoreFields  = []string{"has_more", "hasMore", "has_next", "hasNext"}
+	nextLinkFields = []string{"next", "next_url", "nextUrl", "next_page_url"}
+	cursorParams   = []string{"cursor", "page_token", "pageToken", "next_cursor", "nextCursor", "after", "starting_after"}
+	containerKeys  = []string{"pagination", "paging", "meta", "page_info", "pageInfo"}
+	listKeys       = []string{"items", "data", "results", "content", "records", "entries", "elements", "hits"}
+)
+
+// detectPagination builds the pagination state from the Link and count
+// headers and well-known fields of a JSON body. It returns nil when nothing
+// was found. The next arguments are named as the tool exposes them through
+// aliases.
+func detectPagination(header http.Header, body []byte, reqQueryParam []string, aliases *argumentAliases) *paginationState {
+	state := &paginationState{}
+	found := false
+
+	for _, link := range header.Values("Link") {
+		for _, match := range linkRelPattern.FindAllStringSubmatch(link, -1) {
+			switch strings.ToLower(match[2]) {
+			case "next":
+				state.Next = match[1]
+			case "prev", "previous":
+				state.Prev = match[1]
+			case "first":
+				state.First = match[1]
+			case "last":
+				state.Last = match[1]
+			default:
+				continue
+			}
+			found = true
+		}
+	}
+	if total, err := strconv.ParseInt(header.Get("X-Total-Count"), 10, 64); err == nil {
+		state.TotalCount = &total
+		found = true
+	}
+
+	var data map[string]interface{}
+	if json.Unmarshal(body, &data) == nil {
+		objects := []map[string]interface{}{data}
+		// the counts of a single resource, such as the total of an order, are
+		// not page counts: they are only read from a page envelope
+		counted := []map[string]interface{}{}
+		if holdsList(data) {
+			counted = append(counted, data)
+		}
+		for _, key := range containerKeys {
+			if nested, ok := data[key].(map[string]interface{}); ok {
+				objects = append(objects, nested)
+				counted = append(counted, nested)
+			}
+		}
+		for _, obj := range objects {
+			if v, ok := firstString(obj, cursorFields); ok && state.NextCursor == "" {
+				state.NextCursor, found = v, true
+			}
+			if v, ok := firstString(obj, nextLinkFields); ok && state.Next == "" {
+				state.Next, found = v, true
+			}
+			for _, name := range hasMoreFields {
+				if v, ok := obj[name].(bool); ok && state.HasMore == nil {
+					state.HasMore, found = &v, true
+				}
+			}
+		}
+		for _, obj := range counted {
+			if v, ok := firstInt(obj, totalFields); ok && state.TotalCount == nil {
+				state.TotalCount, found = &v, true
+			}
+			if v, ok := firstInt(obj, pageFields); ok && state.Page == nil {
+				state.Page, found = &v, true
+			}
+			if v, ok := firstInt(obj, perPageFields); ok && state.PerPage == nil {
+				state.PerPage, found = &v, true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	// translate the next page into arguments of the same tool
+	nextArgs := map[string]string{}
+	if state.Next != "" {
+		if u, err := url.Parse(state.Next); err == nil {
+			for name, values := range u.Query() {
+				if slices.Contains(reqQueryParam, name) && len(values) > 0 {
+					nextArgs[aliases.argument(name)] = values[0]
+				}
+			}
+		}
+	}
+	if state.NextCursor != "" {
+		for _, name := range cursorParams {
+			if slices.Contains(reqQueryParam, name) {
+				nextArgs[aliases.argument(name)] = state.NextCursor
+				break
+			}
+		}
+	}
+	if len(nextArgs) == 0 && state.Page != nil && slices.Contains(reqQueryParam, "page") && (state.HasMore == nil || *state.HasMore) {
+		nextArgs[aliases.argument("page")] = strconv.FormatInt(*state.Page+1, 10)
+	}
+	if len(nextArgs) > 0 {
+		state.NextArguments = nextArgs
+	}
+	return state
+}
+
+// holdsList tells whether a JSON object keeps the items of a page in one of
+// the usual list fields.
+func holdsList(obj map[string]interface{}) bool {
+	for _, key := range listKeys {
+		if _, ok := obj[key].([]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func firstString(obj map[string]interface{}, names []string) (string, bool) {
+	for _, name := range names {
+		if v, ok := obj[name].(string); ok && v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func firstInt(obj map[string]interface{}, names []string) (int64, bool) {
+	for _, name := range names {
+		switch v := obj[name].(type) {
+		case float64:
+			// an amount such as 12.5 is no count
+			if v == float64(int64(v)) {
+				return int64(v), true
+			}
+		case string:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,94 @@
+package mcpserver
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDetectPagination(t *testing.T) {
+	aliases := newArgumentAliases(nil)
+	aliases.name("page[number]")
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		body       string
+		params     []string
+		wantNil    bool
+		wantTotal  int64
+		wantCursor string
+		wantArgs   map[string]string
+	}{
+		{
+			name:    "single resource with a total",
+			body:    `{"id": 7, "total": 42, "page": 3}`,
+			params:  []string{"page"},
+			wantNil: true,
+		},
+		{
+			name:    "amount in a list",
+			body:    `{"items": [], "total": 12.5}`,
+			wantNil: true,
+		},
+		{
+			name:      "page envelope",
+			body:      `{"items": [{"id": 1}], "total": 42, "page": 2}`,
+			params:    []string{"page"},
+			wantTotal: 42,
+			wantArgs:  map[string]string{"page": "3"},
+		},
+		{
+			name:      "meta object",
+			body:      `{"id": 1, "meta": {"total": 5}}`,
+			wantTotal: 5,
+		},
+		{
+			name:      "count header",
+			header:    http.Header{"X-Total-Count": {"9"}},
+			body:      `{"id": 1, "total": 42}`,
+			wantTotal: 9,
+		},
+		{
+			name:       "cursor",
+			body:       `{"data": [], "next_cursor": "abc"}`,
+			params:     []string{"starting_after"},
+			wantCursor: "abc",
+			wantArgs:   map[string]string{"starting_after": "abc"},
+		},
+		{
+			name:     "aliased link parameter",
+			header:   http.Header{"Link": {`<https://api.example.com/users?page%5Bnumber%5D=3>; rel="next"`}},
+			body:     `[]`,
+			params:   []string{"page[number]"},
+			wantArgs: map[string]string{"page_number": "3"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := test.header
+			if header == nil {
+				header = http.Header{}
+			}
+			state := detectPagination(header, []byte(test.body), test.params, aliases)
+			if test.wantNil {
+				if state != nil {
+					t.Fatalf("expected no pagination, got %+v", state)
+				}
+				return
+			}
+			if state == nil {
+				t.Fatal("expected pagination")
+			}
+			if test.wantTotal != 0 && (state.TotalCount == nil || *state.TotalCount != test.wantTotal) {
+				t.Errorf("total = %v, want %d", state.TotalCount, test.wantTotal)
+			}
+			if state.NextCursor != test.wantCursor {
+				t.Errorf("cursor = %q, want %q", state.NextCursor, test.wantCursor)
+			}
+			if !reflect.DeepEqual(state.NextArguments, test.wantArgs) {
+				t.Errorf("next arguments = %v, want %v", state.NextArguments, test.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// paramType returns the declared type of a parameter, from its schema in OpenAPI 3.0.
+func paramType(param models.Parameter) string {
+	if param.Type == "" && param.Schema != nil {
+		return param.Schema.Type
+	}
+	return param.Type
+}
+
+// typedParamOption builds the tool argument of a query or path parameter with its declared type.
+func typedParamOption(name string, param models.Parameter) mcp.ToolOption {
+	propOptions := []mcp.PropertyOption{
+		mcp.Description(parameterDescription(param)),
+	}
+	if value := paramDefault(param); value != nil {
+		// the default is sent when the argument is left out
+		propOptions = append(propOptions, defaultOption(value))
+	} else if param.Required {
+		propOptions = append(propOptions, mcp.Required())
+	}
+	enum := paramEnum(param)
+	if len(enum) > 0 && paramType(param) != "array" {
+		propOptions = append(propOptions, enumOption(enum))
+	}
+	switch paramType(param) {
+	case "integer":
+		propOptions = append(propOptions, func(schema map[string]interface{}) {
+			schema["type"] = "integer"
+		})
+		return mcp.WithNumber(name, propOptions...)
+	case "number":
+		return mcp.WithNumber(name, propOptions...)
+	case "boolean":
+		return mcp.WithBoolean(name, propOptions...)
+	case "array":
+		items := map[string]interface{}{"type": itemType(param)}
+		if len(enum) > 0 {
+			items["enum"] = enum
+		}
+		propOptions = append(propOptions, mcp.Items(items))
+		return mcp.WithArray(name, propOptions...)
+	}
+	return mcp.WithString(name, propOptions...)
+}
+
+// itemType returns the item type of an array parameter, string when undeclared.
+func itemType(param models.Parameter) string {
+	items := param.Items
+	if items == nil && param.Schema != nil {
+		items = param.Schema.Items
+	}
+	if items == nil || items.Type == "" {
+		return "string"
+	}
+	return items.Type
+}
+
+// paramEnum returns the allowed values of a parameter, of its items for arrays.
+func paramEnum(param models.Parameter) []interface{} {
+	if paramType(param) == "array" {
+		items := param.Items
+		if items == nil && param.Schema != nil {
+			items = param.Schema.Items
+		}
+		if items == nil {
+			return nil
+		}
+		return items.Enum
+	}
+	if len(param.Enum) == 0 && param.Schema != nil {
+		return param.Schema.Enum
+	}
+	return param.Enum
+}
+
+// paramDefault returns the default value of a parameter, nil when it has none.
+func paramDefault(param models.Parameter) interface{} {
+	if param.Default == nil && param.Schema != nil {
+		return param.Schema.Default
+	}
+	return param.Default
+}
+
+// defaultOption advertises the value used when the argument is left out.
+func defaultOption(value interface{}) mcp.PropertyOption {
+	return func(schema map[string]interface{}) {
+		schema["default"] = value
+	}
+}
+
+// argumentOrDefault returns the argument of a parameter, or its default when
+// the argument was left out.
+func argumentOrDefault(arguments map[string]interface{}, param models.Parameter) (interface{}, bool) {
+	if value, ok := arguments[param.Name]; ok {
+		return value, true
+	}
+	value := paramDefault(param)
+	return value, value != nil
+}
+
+// enumOption restricts an argument to the allowed values, with mcp.Enum when
+// they are all strings and as they are declared otherwise.
+func enumOption(values []interface{}) mcp.PropertyOption {
+	texts := make([]string, 0, len(values))
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) == len(values) {
+		return mcp.Enum(texts...)
+	}
+	return func(schema map[string]interface{}) {
+		schema["enum"] = values
+	}
+}
+
+// checkEnum fails when value, in its text form, is not one of the allowed values.
+func checkEnum(value string, allowed []interface{}) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	texts := make([]string, len(allowed))
+	for i, candidate := range allowed {
+		texts[i] = fmt.Sprint(candidate)
+		if texts[i] == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(texts, ", "))
+}
+
+// argumentValues validates an argument against the declared type and enum of param and
+// returns its text form. Array arguments, a JSON array or a comma-separated
+// string, give one value per item when they are sent as repeated query
+// parameters and a single joined value otherwise.
+func argumentValues(value interface{}, param models.Parameter) ([]string, error) {
+	if paramType(param) != "array" {
+		text, err := argumentString(value, paramType(param))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkEnum(text, paramEnum(param)); err != nil {
+			return nil, err
+		}
+		return []string{text}, nil
+	}
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		if err := json.Unmarshal([]byte(v), &items); err != nil {
+			items = []interface{}{}
+			for _, item := range strings.Split(v, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected array")
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		text, err := argumentString(item, itemType(param))
+		if err == nil {
+			err = checkEnum(text, paramEnum(param))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %v", i+1, err)
+		}
+		values[i] = text
+	}
+	return collectionValues(values, param), nil
+}
+
+// collectionValues joins the items of an array parameter as its
+// collectionFormat says, or leaves them apart when the parameter is repeated
+// once per item.
+func collectionValues(values []string, param models.Parameter) []string {
+	switch {
+	case (param.In == "query" || param.In == "formData") && repeatsArray(param):
+		return values
+	case param.CollectionFormat == "ssv":
+		return []string{strings.Join(values, " ")}
+	case param.CollectionFormat == "tsv":
+		return []string{strings.Join(values, "\t")}
+	case param.CollectionFormat == "pipes":
+		return []string{strings.Join(values, "|")}
+	}
+	return []string{strings.Join(values, ",")}
+}
+
+// repeatsArray tells whether an array query parameter is sent once per item,
+// as collectionFormat multi in Swagger 2.0 and by default in OpenAPI 3.0.
+func repeatsArray(param models.Parameter) bool {
+	if param.Schema == nil {
+		return param.CollectionFormat == "multi"
+	}
+	return param.Explode == nil || *param.Explode
+}
+
+// argumentString validates a query or path argument against its declared type
+// and returns its canonical text form, e.g. 3 rather than 3.0 and true rather than True.
+func argumentString(value interface{}, schemaType string) (string, error) {
+	switch schemaType {
+	case "integer":
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return "", fmt.Errorf("expected integer, got %v", v)
+			}
+			return strconv.FormatInt(int64(v), 10), nil
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("expected integer, got %q", v)
+			}
+			return strconv.FormatInt(n, 10), nil
+		}
+		return "", fmt.Errorf("expected integer")
+	case "number":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", fmt.Errorf("expected number, got %q", v)
+			}
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		return "", fmt.Errorf("expected number")
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return "", fmt.Errorf("expected boolean, got %q", v)
+			}
+			return strconv.FormatBool(b), nil
+		}
+		return "", fmt.Errorf("expected boolean")
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("expected string")
+}
+
+// queryObject returns the schema of a query parameter taking an object, with
+// the properties of the schema it refers to.
+func queryObject(swaggerSpec models.SwaggerSpec, param models.Parameter) (models.Property, bool) {
+	if param.In != "query" {
+		return models.Property{}, false
+	}
+	if param.Schema == nil {
+		return models.Property{Type: "object"}, param.Type == "object"
+	}
+	prop := resolveProperty(swaggerSpec, schemaProperty(param.Schema))
+	if prop.Type == "" && len(prop.Properties) > 0 {
+		prop.Type = "object"
+	}
+	return prop, prop.Type == "object"
+}
+
+// objectParamOption builds the object tool argument of a query parameter
+// with the properties of its schema.
+func objectParamOption(swaggerSpec models.SwaggerSpec, name string, param models.Parameter, prop models.Property) mcp.ToolOption {
+	propOptions := []mcp.PropertyOption{
+		mcp.Description(parameterDescription(param)),
+	}
+	if value := paramDefault(param); value != nil {
+		propOptions = append(propOptions, defaultOption(value))
+	} else if param.Required {
+		propOptions = append(propOptions, mcp.Required())
+	}
+	return bodyPropertyOption(swaggerSpec, name, prop, propOptions)
+}
+
+// explodesObject tells whether an object query parameter is sent in the
+// exploded form style, its properties as query keys of their own.
+func explodesObject(param models.Parameter) bool {
+	return (param.Style == "" || param.Style == "form") && (param.Explode == nil || *param.Explode)
+}
+
+// objectQueryCollisions returns the properties of an exploded object query
+// parameter named like one of the declared query parameters.
+func objectQueryCollisions(param models.Parameter, prop models.Property, declared []models.Parameter) []string {
+	if !explodesObject(param) {
+		return nil
+	}
+	collisions := []string{}
+	for _, other := range declared {
+		if other.In != "query" || other.Name == param.Name {
+			continue
+		}
+		if _, ok := prop.Properties[other.Name]; ok {
+			collisions = append(collisions, other.Name)
+		}
+	}
+	sort.Strings(collisions)
+	return collisions
+}
+
+// objectQueryValues serializes an object query parameter in its style, e.g.
+// {"R": 100, "G": 200} as R=100&G=200 in the form style, color=R,100,G,200
+// without explode, color[R]=100&color[G]=200 in the deepObject style, and
+// with spaces or pipes between the pairs in the spaceDelimited and
+// pipeDelimited styles. Properties are sent sorted by name, nested objects as
+// JSON. In the exploded form style the properties become query keys of their
+// own, those named like one of the declared query parameters are refused.
+func objectQueryValues(value interface{}, param models.Parameter, declared []string) (url.Values, error) {
+	object, ok := value.(map[string]interface{})
+	if text, isString := value.(string); isString && json.Unmarshal([]byte(text), &object) == nil {
+		ok = true
+	}
+	if !ok {
+		return nil, fmt.Errorf("expected object")
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := url.Values{}
+	pairs := []string{}
+	for _, name := range names {
+		text, err := objectPropertyString(object[name])
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %v", name, err)
+		}
+		switch {
+		case param.Style == "deepObject":
+			values.Add(param.Name+"["+name+"]", text)
+		case explodesObject(param):
+			if name != param.Name && slices.Contains(declared, name) {
+				return nil, fmt.Errorf("property %s has the name of the query parameter %s, pass it there", name, name)
+			}
+			values.Add(name, text)
+		default:
+			pairs = append(pairs, name, text)
+		}
+	}
+	if len(pairs) > 0 {
+		separator := ","
+		switch param.Style {
+		case "spaceDelimited":
+			separator = " "
+		case "pipeDelimited":
+			separator = "|"
+		}
+		values.Set(param.Name, strings.Join(pairs, separator))
+	}
+	return values, nil
+}
+
+// objectPropertyString returns the text form of a property of an object
+// query parameter: arrays comma separated, objects as JSON.
+func objectPropertyString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			text, err := argumentString(item, "")
+			if err != nil {
+				return "", fmt.Errorf("item %d: %v", i+1, err)
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+	return argumentString(value, "")
+}
+
+var pathPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// pathParams returns the path parameters of an operation matched against the
+// {name} placeholders of its path: the declared parameters the path uses,
+// then a required string parameter for each placeholder declaring none.
+// undeclared and unused list the mismatches, unused parameters are left out
+// as they have nowhere to go.
+func pathParams(path string, details models.Endpoint) (params []models.Parameter, undeclared, unused []string) {
+	placeholders := []string{}
+	for _, match := range pathPlaceholder.FindAllStringSubmatch(path, -1) {
+		placeholders = append(placeholders, match[1])
+	}
+	declared := []string{}
+	for _, param := range details.Parameters {
+		if param.In != "path" {
+			continue
+		}
+		declared = append(declared, param.Name)
+		if slices.Contains(placeholders, param.Name) {
+			params = append(params, param)
+		} else {
+			unused = append(unused, param.Name)
+		}
+	}
+	for _, name := range placeholders {
+		if !slices.Contains(declared, name) && !slices.Contains(undeclared, name) {
+			undeclared = append(undeclared, name)
+			params = append(params, models.Parameter{
+				Name:        name,
+				In:          "path",
+				Required:    true,
+				Type:        "string",
+				Description: fmt.Sprintf("The {%s} segment of the path, the spec does not declare it", name),
+			})
+		}
+	}
+	return params, undeclared, unused
+}
+
+// expandPathParam renders a path parameter value in its style: simple (value),
+// label (.value) or matrix (;name=value). Characters such as / are
+// percent-encoded so the value stays one path segment, unless the parameter
+// sets allowReserved.
+func expandPathParam(param models.Parameter, value string) string {
+	escaped := url.PathEscape(value)
+	if param.AllowReserved {
+		escaped = escapeUnreserved(value)
+	}
+	switch param.Style {
+	case "label":
+		return "." + escaped
+	case "matrix":
+		return ";" + param.Name + "=" + escaped
+	}
+	return escaped
+}
+
+// escapeUnreserved percent-encodes everything but the unreserved and the
+// reserved characters of RFC 3986, as allowReserved requires.
+func escapeUnreserved(value string) string {
+	const reserved = ":/?#[]@!$&'()*+,;="
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte("-._~"+reserved, c) >= 0) {
+			escaped.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&escaped, "%%%02X", c)
+	}
+	return escaped.String()
+}
+
+// encodeQuery encodes the query like url.Values.Encode, leaving the reserved
+// characters of the allowReserved parameters as they are.
+func encodeQuery(q url.Values, params map[string]models.Parameter) string {
+	keys := make([]string, 0, len(q))
+	for key := range q {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, key := range keys {
+		for _, value := range q[key] {
+			if params[key].AllowReserved {
+				pairs = append(pairs, url.QueryEscape(key)+"="+escapeUnreserved(value))
+			} else {
+				pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// cookieEscaper escapes the characters that would end a cookie value.
+var cookieEscaper = strings.NewReplacer(";", "%3B", "\"", "%22", "\\", "%5C")
+
+// addCookie adds a cookie parameter to the Cookie header. Unlike
+// http.Request.AddCookie it does not quote values holding commas or spaces, so
+// arrays are sent in the form style of OpenAPI, e.g. ids=1,2.
+func addCookie(req *http.Request, name, value string) {
+	pair := name + "=" + cookieEscaper.Replace(value)
+	if existing := req.Header.Get("Cookie"); existing != "" {
+		pair = existing + "; " + pair
+	}
+	req.Header.Set("Cookie", pair)
+}
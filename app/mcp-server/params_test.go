@@ -0,0 +1,100 @@
+package mcpserver
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+func TestArgumentValues(t *testing.T) {
+	noExplode := false
+	integers := &models.SchemaRef{Type: "integer"}
+	tests := []struct {
+		name    string
+		value   interface{}
+		param   models.Parameter
+		want    []string
+		wantErr bool
+	}{
+		{"string", "abc", models.Parameter{Name: "q", In: "query", Type: "string"}, []string{"abc"}, false},
+		{"whole number", 3.0, models.Parameter{Name: "n", In: "query", Type: "integer"}, []string{"3"}, false},
+		{"integer text", "42", models.Parameter{Name: "n", In: "query", Type: "integer"}, []string{"42"}, false},
+		{"fraction for an integer", 3.5, models.Parameter{Name: "n", In: "query", Type: "integer"}, nil, true},
+		{"number", 2.5, models.Parameter{Name: "x", In: "query", Type: "number"}, []string{"2.5"}, false},
+		{"boolean text", "True", models.Parameter{Name: "b", In: "query", Type: "boolean"}, []string{"true"}, false},
+		{"not a boolean", "yes", models.Parameter{Name: "b", In: "query", Type: "boolean"}, nil, true},
+		{"enum value", "cat", models.Parameter{Name: "kind", In: "query", Type: "string", Enum: []interface{}{"cat", "dog"}}, []string{"cat"}, false},
+		{"outside the enum", "cow", models.Parameter{Name: "kind", In: "query", Type: "string", Enum: []interface{}{"cat", "dog"}}, nil, true},
+		{"OpenAPI 3.0 schema type", 7.0, models.Parameter{Name: "id", In: "path", Schema: &models.SchemaRef{Type: "integer"}}, []string{"7"}, false},
+		{"multi array", []interface{}{1.0, 2.0}, models.Parameter{Name: "ids", In: "query", Type: "array", Items: integers, CollectionFormat: "multi"}, []string{"1", "2"}, false},
+		{"csv array", []interface{}{1.0, 2.0}, models.Parameter{Name: "ids", In: "query", Type: "array", Items: integers}, []string{"1,2"}, false},
+		{"pipes array", "[1, 2]", models.Parameter{Name: "ids", In: "query", Type: "array", Items: integers, CollectionFormat: "pipes"}, []string{"1|2"}, false},
+		{"comma separated text", "a, b", models.Parameter{Name: "tags", In: "query", Type: "array", CollectionFormat: "multi"}, []string{"a", "b"}, false},
+		{"exploded OpenAPI 3.0 array", []interface{}{"a", "b"}, models.Parameter{Name: "tags", In: "query", Schema: &models.SchemaRef{Type: "array"}}, []string{"a", "b"}, false},
+		{"OpenAPI 3.0 array without explode", []interface{}{"a", "b"}, models.Parameter{Name: "tags", In: "query", Explode: &noExplode, Schema: &models.SchemaRef{Type: "array"}}, []string{"a,b"}, false},
+		{"path array", []interface{}{"a", "b"}, models.Parameter{Name: "tags", In: "path", Type: "array", CollectionFormat: "multi"}, []string{"a,b"}, false},
+		{"invalid item", []interface{}{1.0, "x"}, models.Parameter{Name: "ids", In: "query", Type: "array", Items: integers}, nil, true},
+		{"not an array", true, models.Parameter{Name: "ids", In: "query", Type: "array"}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := argumentValues(test.value, test.param)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("values = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestObjectQueryValues(t *testing.T) {
+	noExplode := false
+	color := map[string]interface{}{"R": 100.0, "G": 200.0}
+	tests := []struct {
+		name     string
+		value    interface{}
+		param    models.Parameter
+		declared []string
+		want     url.Values
+		wantErr  bool
+	}{
+		{"form", color, models.Parameter{Name: "color", In: "query"}, nil, url.Values{"G": {"200"}, "R": {"100"}}, false},
+		{"form without explode", color, models.Parameter{Name: "color", In: "query", Explode: &noExplode}, nil, url.Values{"color": {"G,200,R,100"}}, false},
+		{"deepObject", color, models.Parameter{Name: "color", In: "query", Style: "deepObject"}, nil, url.Values{"color[G]": {"200"}, "color[R]": {"100"}}, false},
+		{"spaceDelimited", color, models.Parameter{Name: "color", In: "query", Style: "spaceDelimited", Explode: &noExplode}, nil, url.Values{"color": {"G 200 R 100"}}, false},
+		{"pipeDelimited", color, models.Parameter{Name: "color", In: "query", Style: "pipeDelimited", Explode: &noExplode}, nil, url.Values{"color": {"G|200|R|100"}}, false},
+		{"JSON text", `{"R": 1}`, models.Parameter{Name: "color", In: "query"}, nil, url.Values{"R": {"1"}}, false},
+		{"nested object", map[string]interface{}{"range": map[string]interface{}{"min": 1.0}}, models.Parameter{Name: "filter", In: "query", Style: "deepObject"}, nil, url.Values{"filter[range]": {`{"min":1}`}}, false},
+		{"not an object", "red", models.Parameter{Name: "color", In: "query"}, nil, nil, true},
+		{"property named like a query parameter", map[string]interface{}{"limit": 5.0}, models.Parameter{Name: "filter", In: "query"}, []string{"filter", "limit"}, nil, true},
+		{"property named like the parameter itself", map[string]interface{}{"filter": "x"}, models.Parameter{Name: "filter", In: "query"}, []string{"filter"}, url.Values{"filter": {"x"}}, false},
+		{"deepObject property named like a query parameter", map[string]interface{}{"limit": 5.0}, models.Parameter{Name: "filter", In: "query", Style: "deepObject"}, []string{"filter", "limit"}, url.Values{"filter[limit]": {"5"}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := objectQueryValues(test.value, test.param, test.declared)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("values = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestObjectQueryCollisions(t *testing.T) {
+	prop := models.Property{Type: "object", Properties: map[string]models.Property{"limit": {Type: "integer"}, "status": {Type: "string"}}}
+	declared := []models.Parameter{{Name: "filter", In: "query"}, {Name: "limit", In: "query"}, {Name: "status", In: "header"}}
+
+	if got := objectQueryCollisions(models.Parameter{Name: "filter", In: "query"}, prop, declared); !reflect.DeepEqual(got, []string{"limit"}) {
+		t.Errorf("form collisions = %v, want [limit]", got)
+	}
+	if got := objectQueryCollisions(models.Parameter{Name: "filter", In: "query", Style: "deepObject"}, prop, declared); len(got) != 0 {
+		t.Errorf("deepObject collisions = %v, want none", got)
+	}
+}
@@ -0,0 +1,54 @@
+package mcpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// partialResponse describes a response body cut short by the request timeout.
+type partialResponse struct {
+	Partial       bool   `json:"partial"`
+	ReceivedBytes int    `json:"received_bytes"`
+	Items         int    `json:"items,omitempty"` // complete list items kept
+	Reason        string `json:"reason"`
+}
+
+// salvagePartialBody keeps the usable part of a truncated body: the complete
+// items of a JSON array, or the complete lines of a line-delimited stream.
+// Other bodies are returned as received. items is the number of items kept.
+func salvagePartialBody(body []byte) ([]byte, int) {
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		if _, err := decoder.Token(); err == nil {
+			items := []json.RawMessage{}
+			for decoder.More() {
+				var item json.RawMessage
+				if decoder.Decode(&item) != nil {
+					break
+				}
+				items = append(items, item)
+			}
+			if salvaged, err := json.Marshal(items); err == nil {
+				return salvaged, len(items)
+			}
+		}
+	}
+	if end := bytes.LastIndexByte(body, '\n'); end > 0 {
+		lines := body[:end+1]
+		return lines, bytes.Count(lines, []byte("\n"))
+	}
+	return body, 0
+}
+
+// partialResult builds the partial marker for a body whose read timed out.
+func partialResult(received int, items int, timeout time.Duration) partialResponse {
+	return partialResponse{
+		Partial:       true,
+		ReceivedBytes: received,
+		Items:         items,
+		Reason:        fmt.Sprintf("the response did not complete within %s, only the part received so far is returned; narrow the request (filters, page size) for the rest", timeout),
+	}
+}
@@ -0,0 +1,95 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// playbookURI prefixes the URIs of the tag playbook resources.
+const playbookURI = "swagger-mcp://playbooks/"
+
+var promptNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// loadPlaybooks reads the markdown playbooks of dir, one <tag>.md file per tag.
+func loadPlaybooks(dir string) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list playbooks: %v", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no <tag>.md playbook found in %s", dir)
+	}
+	playbooks := map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read playbook: %v", err)
+		}
+		playbooks[strings.TrimSuffix(filepath.Base(file), ".md")] = string(data)
+	}
+	return playbooks, nil
+}
+
+// addPlaybooks registers each playbook as a prompt and a resource, followed by
+// the tools of its tag. Tag names are matched case-insensitively.
+func addPlaybooks(mcpServer *server.MCPServer, playbooks map[string]string, tags []models.Tag, tagTools map[string][]string) {
+	for name, playbook := range playbooks {
+		tag := models.Tag{Name: name}
+		for _, specTag := range tags {
+			if strings.EqualFold(specTag.Name, name) {
+				tag = specTag
+			}
+		}
+		tools := []string{}
+		for tagName, names := range tagTools {
+			if strings.EqualFold(tagName, name) {
+				tools = append(tools, names...)
+			}
+		}
+		if len(tools) == 0 {
+			fmt.Printf("Playbook %s does not match the tag of any tool\n", name)
+		}
+		sort.Strings(tools)
+
+		text := strings.TrimSpace(playbook)
+		if len(tools) > 0 {
+			text += fmt.Sprintf("\n\nTools of the %s tag: %s", tag.Name, strings.Join(tools, ", "))
+		}
+		description := fmt.Sprintf("How to use the %s tools: call order, pagination and gotchas", tag.Name)
+		if tag.Description != "" {
+			description = fmt.Sprintf("%s. %s", description, tag.Description)
+		}
+
+		uri := playbookURI + tag.Name
+		mcpServer.AddResource(
+			mcp.NewResource(uri, tag.Name+" playbook",
+				mcp.WithResourceDescription(description),
+				mcp.WithMIMEType("text/markdown"),
+			),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{URI: uri, MIMEType: "text/markdown", Text: text},
+				}, nil
+			},
+		)
+		mcpServer.AddPrompt(
+			mcp.NewPrompt("playbook_"+strings.Trim(promptNameUnsafe.ReplaceAllString(tag.Name, "_"), "_"),
+				mcp.WithPromptDescription(description),
+			),
+			func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return mcp.NewGetPromptResult(description, []mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+				}), nil
+			},
+		)
+	}
+}
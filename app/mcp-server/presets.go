@@ -0,0 +1,99 @@
+package mcpserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// exampleArgument selects a request body example to pre-fill the body with.
+const exampleArgument = "_example"
+
+// bodyPreset is a named request body example of an operation.
+type bodyPreset struct {
+	Summary string
+	Body    map[string]interface{}
+}
+
+// bodyPresets collects the object request body examples of an operation by name:
+// the named OpenAPI 3.0 examples, and the single example as "default".
+func bodyPresets(details models.Endpoint) map[string]bodyPreset {
+	presets := map[string]bodyPreset{}
+	for _, param := range details.Parameters {
+		if param.In == "body" && param.Schema != nil {
+			if body, ok := param.Schema.Example.(map[string]interface{}); ok {
+				presets["default"] = bodyPreset{Body: body}
+			}
+		}
+	}
+	if details.RequestBody != nil {
+		for contentType, mediaType := range details.RequestBody.Content {
+			if !strings.Contains(contentType, "json") {
+				continue
+			}
+			if body, ok := mediaType.Example.(map[string]interface{}); ok {
+				presets["default"] = bodyPreset{Body: body}
+			}
+			for _, example := range namedExamples(mediaType.Examples) {
+				if body, ok := example.Value.(map[string]interface{}); ok {
+					presets[example.Name] = bodyPreset{Summary: example.Summary, Body: body}
+				}
+			}
+		}
+	}
+	return presets
+}
+
+// namedExample is an OpenAPI 3.0 named example of a parameter or media type.
+type namedExample struct {
+	Name    string
+	Summary string
+	Value   interface{}
+}
+
+// namedExamples returns the named examples that carry a value, sorted by name.
+// References into components/examples are resolved when the spec is parsed.
+func namedExamples(examples map[string]interface{}) []namedExample {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	found := []namedExample{}
+	for _, name := range names {
+		example, ok := examples[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, ok := example["value"]
+		if !ok {
+			continue
+		}
+		summary, _ := example["summary"].(string)
+		found = append(found, namedExample{Name: name, Summary: summary, Value: value})
+	}
+	return found
+}
+
+// presetOption builds the _example tool argument listing the presets.
+func presetOption(presets map[string]bodyPreset) mcp.ToolOption {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	described := make([]string, len(names))
+	for i, name := range names {
+		described[i] = name
+		if summary := presets[name].Summary; summary != "" {
+			described[i] = fmt.Sprintf("%s (%s)", name, summary)
+		}
+	}
+	return mcp.WithString(exampleArgument,
+		mcp.Description(fmt.Sprintf("Pre-fill the request body with a documented example: %s. Body arguments that are also given override the example values.", strings.Join(described, ", "))),
+		mcp.Enum(names...),
+	)
+}
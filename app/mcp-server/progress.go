@@ -0,0 +1,68 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressStateKey carries the *atomic.Value the handlers report their current state in.
+const progressStateKey = "__progressStateKey"
+
+// setProgressState records what a tool call is doing, for the progress notifications.
+func setProgressState(ctx context.Context, state string) {
+	if current, ok := ctx.Value(progressStateKey).(*atomic.Value); ok {
+		current.Store(state)
+	}
+}
+
+// progressReporter sends MCP progress notifications while a tool call takes
+// longer than interval, so clients do not assume the call died.
+type progressReporter struct {
+	interval time.Duration
+}
+
+// wrap notifies the client every interval until handler returns, when the
+// client asked for progress with a progress token.
+func (p *progressReporter) wrap(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mcpServer := server.ServerFromContext(ctx)
+		if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil || mcpServer == nil {
+			return handler(ctx, request)
+		}
+		token := request.Params.Meta.ProgressToken
+
+		state := &atomic.Value{}
+		state.Store("starting")
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			start := time.Now()
+			ticker := time.NewTicker(p.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					elapsed := time.Since(start).Round(time.Second)
+					err := mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+						"progressToken": token,
+						"progress":      elapsed.Seconds(),
+						"message":       fmt.Sprintf("%s, %s elapsed", state.Load(), elapsed),
+					})
+					if err != nil {
+						return
+					}
+				}
+			}
+		}()
+		return handler(context.WithValue(ctx, progressStateKey, state), request)
+	}
+}
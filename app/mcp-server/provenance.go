@@ -0,0 +1,47 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// provenance tells where the data of a result comes from and how fresh it is,
+// so agents and auditors can judge whether to trust or refetch it.
+type provenance struct {
+	SourceURL   string `json:"source_url"`
+	Method      string `json:"method"`
+	Status      int    `json:"status"`
+	Cache       string `json:"cache"` // hit when served from the ETag cache, live otherwise
+	RetrievedAt string `json:"retrieved_at"`
+	SpecVersion string `json:"spec_version,omitempty"`
+}
+
+// specVersion returns the API version declared in the spec info.
+func specVersion(swaggerSpec models.SwaggerSpec) string {
+	if swaggerSpec.Info == nil {
+		return ""
+	}
+	return swaggerSpec.Info.Version
+}
+
+// addProvenance appends a provenance section to result. The source URL is
+// redacted like the logs, it may carry credentials in its query.
+func addProvenance(ctx context.Context, result *mcp.CallToolResult, sourceURL, method string, status int, cacheHit bool, version string) {
+	cache := "live"
+	if cacheHit {
+		cache = "hit"
+	}
+	data, _ := json.Marshal(map[string]interface{}{"provenance": provenance{
+		SourceURL:   redactLog(ctx, sourceURL),
+		Method:      method,
+		Status:      status,
+		Cache:       cache,
+		RetrievedAt: time.Now().UTC().Format(time.RFC3339),
+		SpecVersion: version,
+	}})
+	result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+}
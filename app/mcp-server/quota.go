@@ -0,0 +1,69 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// quotaUsage counts the tool calls of one session.
+type quotaUsage struct {
+	calls    int
+	mutating int
+}
+
+// quotaTracker caps the number of tool calls an MCP session can make, as a
+// hard limit on what an autonomous agent can do.
+type quotaTracker struct {
+	mu          sync.Mutex
+	maxCalls    int // 0 means unlimited
+	maxMutating int // 0 means unlimited
+	sessions    map[string]*quotaUsage
+}
+
+func newQuotaTracker(maxCalls, maxMutating int) *quotaTracker {
+	return &quotaTracker{maxCalls: maxCalls, maxMutating: maxMutating, sessions: map[string]*quotaUsage{}}
+}
+
+// take reserves one call of the session, or returns why the budget is exhausted.
+func (q *quotaTracker) take(session string, mutating bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.sessions[session]
+	if usage == nil {
+		usage = &quotaUsage{}
+		q.sessions[session] = usage
+	}
+	if q.maxCalls > 0 && usage.calls >= q.maxCalls {
+		return fmt.Errorf("call budget exhausted: this session already made %d of %d allowed tool calls", usage.calls, q.maxCalls)
+	}
+	if mutating && q.maxMutating > 0 && usage.mutating >= q.maxMutating {
+		return fmt.Errorf("call budget exhausted: this session already made %d of %d allowed mutating calls", usage.mutating, q.maxMutating)
+	}
+	usage.calls++
+	if mutating {
+		usage.mutating++
+	}
+	return nil
+}
+
+// forget drops the call counts of a session that ended.
+func (q *quotaTracker) forget(session string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.sessions, session)
+}
+
+// wrap refuses calls of handler once the session has used up its budget.
+func (q *quotaTracker) wrap(method string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	mutating := isMutatingMethod(method)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := q.take(sessionID(ctx), mutating); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+		}
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,268 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	requestLogURI = "swagger-mcp://requests"
+
+	// request and response bodies longer than this are cut in the log
+	requestLogBodyLimit = 4096
+)
+
+// credentialHeaders never show their value in the request log.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// loggedRequest is a request a tool sent and the response it got, redacted.
+type loggedRequest struct {
+	Time           time.Time         `json:"time"`
+	Tool           string            `json:"tool"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	Response       *loggedResponse   `json:"response,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	DurationMillis int64             `json:"durationMillis"`
+
+	session string
+	sent    bool
+}
+
+type loggedResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// loggedCall is filled in by a tool handler with what it sent and received.
+type loggedCall struct {
+	mu      sync.Mutex
+	entry   loggedRequest
+	secrets []string
+}
+
+type loggedCallKey struct{}
+
+// requestLog keeps the last requests of the tools of a server in a ring, for
+// the requests resource.
+type requestLog struct {
+	mu      sync.Mutex
+	entries []loggedRequest
+	next    int
+	full    bool
+}
+
+var (
+	requestLogsMu sync.Mutex
+	requestLogs   = map[*server.MCPServer]*requestLog{}
+)
+
+// openRequestLog returns the request log of mcpServer, keeping the last size
+// requests, and registers its resource the first time.
+func openRequestLog(mcpServer *server.MCPServer, size int) *requestLog {
+	requestLogsMu.Lock()
+	defer requestLogsMu.Unlock()
+	if requests, ok := requestLogs[mcpServer]; ok {
+		return requests
+	}
+	requests := &requestLog{entries: make([]loggedRequest, size)}
+	requestLogs[mcpServer] = requests
+	mcpServer.AddResource(
+		mcp.NewResource(requestLogURI, "Recent requests",
+			mcp.WithResourceDescription(fmt.Sprintf("The last %d HTTP requests the tools of this session sent and the responses they got, newest first, with credentials and sensitive values redacted", size)),
+			mcp.WithMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			data, err := json.MarshalIndent(requests.recent(sessionID(ctx)), "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: requestLogURI, MIMEType: "application/json", Text: string(data)},
+			}, nil
+		},
+	)
+	return requests
+}
+
+func (l *requestLog) add(entry loggedRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	l.full = l.full || l.next == 0
+}
+
+// recent returns the requests of session, newest first.
+func (l *requestLog) recent(session string) []loggedRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+	entries := []loggedRequest{}
+	for i := 1; i <= count; i++ {
+		entry := l.entries[(l.next-i+len(l.entries))%len(l.entries)]
+		if entry.session == session {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// wrap logs the request handler sends, if any, under toolName. The
+// credentials of apiCfg are redacted along with the sensitive arguments.
+func (l *requestLog) wrap(toolName string, apiCfg models.ApiConfig, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	secrets := credentialValues(apiCfg)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		call := &loggedCall{secrets: secrets}
+		start := time.Now()
+		result, err := handler(context.WithValue(ctx, loggedCallKey{}, call), request)
+		call.mu.Lock()
+		entry := call.entry
+		call.mu.Unlock()
+		if !entry.sent {
+			// refused before anything was sent
+			return result, err
+		}
+		entry.Tool = toolName
+		entry.session = sessionID(ctx)
+		entry.DurationMillis = time.Since(start).Milliseconds()
+		if entry.Response == nil {
+			switch {
+			case err != nil:
+				entry.Error = err.Error()
+			case result != nil && result.IsError:
+				entry.Error = redactValues(resultText(result), secrets)
+			}
+		}
+		l.add(entry)
+		return result, err
+	}
+}
+
+// noteRequest records the request a tool call sends, the last one when it is
+// retried. body is the encoded request body, nil for a file upload.
+func noteRequest(ctx context.Context, req *http.Request, body []byte) {
+	call, ok := ctx.Value(loggedCallKey{}).(*loggedCall)
+	if !ok {
+		return
+	}
+	redact := func(text string) string {
+		return redactValues(redactLog(ctx, text), call.secrets)
+	}
+	entry := loggedRequest{
+		Time:    time.Now().UTC(),
+		Method:  req.Method,
+		URL:     redact(req.URL.String()),
+		Headers: loggedHeaders(req.Header, redact),
+		Body:    redact(loggedBody(body)),
+		sent:    true,
+	}
+	if body == nil && req.ContentLength > 0 {
+		entry.Body = fmt.Sprintf("(%d bytes streamed from a file)", req.ContentLength)
+	}
+	call.mu.Lock()
+	call.entry = entry
+	call.mu.Unlock()
+}
+
+// uploadlessBody returns the request body to log, nil when it is streamed
+// from a file.
+func uploadlessBody(upload *uploadBody, body []byte) []byte {
+	if upload != nil {
+		return nil
+	}
+	return body
+}
+
+// noteResponse records the response the backend answered with.
+func noteResponse(ctx context.Context, resp *http.Response, body []byte) {
+	call, ok := ctx.Value(loggedCallKey{}).(*loggedCall)
+	if !ok {
+		return
+	}
+	redact := func(text string) string {
+		return redactValues(redactLog(ctx, text), call.secrets)
+	}
+	call.mu.Lock()
+	call.entry.Response = &loggedResponse{
+		Status:  resp.StatusCode,
+		Headers: loggedHeaders(resp.Header, redact),
+		Body:    redact(loggedBody(body)),
+	}
+	call.mu.Unlock()
+}
+
+// loggedHeaders flattens headers, hiding the credential ones.
+func loggedHeaders(header http.Header, redact func(string) string) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if isCredentialHeader(name) {
+			value = redactedValue
+		}
+		headers[name] = redact(value)
+	}
+	return headers
+}
+
+func isCredentialHeader(name string) bool {
+	for _, header := range credentialHeaders {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loggedBody returns body as text, cut at requestLogBodyLimit bytes.
+func loggedBody(body []byte) string {
+	if len(body) > requestLogBodyLimit {
+		return string(body[:requestLogBodyLimit]) + fmt.Sprintf("... (%d more bytes)", len(body)-requestLogBodyLimit)
+	}
+	return string(body)
+}
+
+// credentialValues returns the secrets of apiCfg, which the request log must
+// not show wherever they end up: the basic and bearer credentials and the
+// values of the API keys.
+func credentialValues(apiCfg models.ApiConfig) []string {
+	values := []string{}
+	add := func(value string) {
+		if len(value) >= minRedactedLength {
+			values = append(values, value)
+		}
+	}
+	if apiCfg.BasicAuth != "" {
+		add(apiCfg.BasicAuth)
+		add(base64.StdEncoding.EncodeToString([]byte(apiCfg.BasicAuth)))
+	}
+	add(apiCfg.BearerAuth)
+	for _, part := range strings.Split(apiCfg.ApiKeyAuth, ",") {
+		if _, value, found := strings.Cut(part, "="); found {
+			add(strings.TrimSpace(value))
+		}
+	}
+	// longer values first, so a secret containing another is hidden whole
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
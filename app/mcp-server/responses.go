@@ -0,0 +1,110 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// responseOutcome tells the model which of the success responses of an operation occurred.
+type responseOutcome struct {
+	StatusCode  int    `json:"status_code"`
+	Outcome     string `json:"outcome"`
+	Description string `json:"description,omitempty"`
+	EmptyBody   bool   `json:"empty_body,omitempty"`
+}
+
+var successOutcomes = map[int]string{
+	http.StatusOK:                   "ok",
+	http.StatusCreated:              "created",
+	http.StatusAccepted:             "accepted, the request was queued and its result is not available yet",
+	http.StatusNonAuthoritativeInfo: "ok, the data comes from a proxy copy",
+	http.StatusNoContent:            "done, there is no response body",
+	http.StatusResetContent:         "done, there is no response body",
+	http.StatusPartialContent:       "partial, only part of the data was returned",
+}
+
+// successResponses returns the descriptions of the 2xx responses declared by the operation, by status code.
+func successResponses(details models.Endpoint) map[int]string {
+	responses := map[int]string{}
+	for status, resp := range details.Responses {
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 200 || code >= 300 {
+			continue
+		}
+		description := strings.TrimSpace(resp.Description)
+		if description == "" {
+			description = http.StatusText(code)
+		}
+		responses[code] = description
+	}
+	return responses
+}
+
+// describeSuccessResponses lists the success responses for the tool description.
+// It returns "" when the operation only answers 200.
+func describeSuccessResponses(responses map[int]string) string {
+	if len(responses) == 0 || (len(responses) == 1 && responses[http.StatusOK] != "") {
+		return ""
+	}
+	codes := make([]int, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%d (%s)", code, responses[code])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// detectOutcome describes a 2xx response when the operation declares several success
+// responses or the response is not a plain 200 with a body. It returns nil otherwise.
+func detectOutcome(statusCode int, body []byte, declared map[int]string) *responseOutcome {
+	if statusCode < 200 || statusCode >= 300 {
+		return nil
+	}
+	emptyBody := len(strings.TrimSpace(string(body))) == 0
+	if statusCode == http.StatusOK && !emptyBody && len(declared) <= 1 {
+		return nil
+	}
+	outcome, ok := successOutcomes[statusCode]
+	if !ok {
+		outcome = "success"
+	}
+	return &responseOutcome{
+		StatusCode:  statusCode,
+		Outcome:     outcome,
+		Description: declared[statusCode],
+		EmptyBody:   emptyBody,
+	}
+}
+
+// checkResourceExists sends a GET to the URL of a DELETE or PUT request and
+// returns an error result when the resource is not found, so a backend 404 is
+// not mistaken for "already deleted". It returns nil when the request may go ahead.
+func checkResourceExists(ctx context.Context, client httpDoer, req *http.Request) *mcp.CallToolResult {
+	check := req.Clone(ctx)
+	check.Method = http.MethodGet
+	check.Body, check.GetBody, check.ContentLength = nil, nil, 0
+	check.Header.Del("Content-Type")
+	check.URL.RawQuery = ""
+	setProgressState(ctx, fmt.Sprintf("checking that %s exists", check.URL.Path))
+	resp, err := client.Do(check)
+	if err != nil {
+		return mcp.NewToolResultError(requestErrorResult(err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		noteUpstreamStatus(ctx, resp.StatusCode)
+		return mcp.NewToolResultError(fmt.Sprintf("[Error] %s does not exist (GET returned %d), the %s was not sent. Check the identifier instead of assuming it was already done.", check.URL.Path, resp.StatusCode, req.Method))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mcpserver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// urlRewrite replaces the matches of a regex in the request URL.
+type urlRewrite struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// parseURLRewrites parses rules in pattern=>replacement format, separated by
+// semicolons. The replacement may reference groups as $1 or ${name}.
+func parseURLRewrites(rules string) ([]urlRewrite, error) {
+	parsed := []urlRewrite{}
+	for _, rule := range strings.Split(rules, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		expr, replacement, ok := strings.Cut(rule, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid URL rewrite %q, expected pattern=>replacement", rule)
+		}
+		pattern, err := regexp.Compile(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL rewrite pattern %q: %v", expr, err)
+		}
+		parsed = append(parsed, urlRewrite{pattern: pattern, replacement: strings.TrimSpace(replacement)})
+	}
+	return parsed, nil
+}
+
+// rewriteURL applies the rewrites in order, each one to the result of the previous.
+func rewriteURL(rewrites []urlRewrite, reqURL string) string {
+	for _, rewrite := range rewrites {
+		reqURL = rewrite.pattern.ReplaceAllString(reqURL, rewrite.replacement)
+	}
+	return reqURL
+}
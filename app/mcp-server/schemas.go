@@ -0,0 +1,149 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// schemaResourceURI prefixes the URIs of the schema resources.
+const schemaResourceURI = "swagger-mcp://schemas/"
+
+// schemaURI returns the URI of the resource of the schema name.
+func schemaURI(name string) string {
+	return schemaResourceURI + url.PathEscape(name)
+}
+
+// specSchemaNames returns the names of the Swagger 2.0 definitions and
+// OpenAPI 3.0 component schemas of swaggerSpec, sorted.
+func specSchemaNames(swaggerSpec models.SwaggerSpec) []string {
+	names := []string{}
+	for name := range swaggerSpec.Definitions {
+		names = append(names, name)
+	}
+	if swaggerSpec.Components != nil {
+		for name := range swaggerSpec.Components.Schemas {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaDocument returns the JSON schema of the schema name with the schemas
+// it refers to expanded, and its variants when it is a oneOf or anyOf.
+func schemaDocument(swaggerSpec models.SwaggerSpec, name string) map[string]interface{} {
+	// the ref the spec itself uses, so the schema stops where it refers to itself
+	ref := "#/definitions/" + name
+	if _, found := swaggerSpec.Definitions[name]; !found {
+		ref = "#/components/schemas/" + name
+	}
+	document := nestedSchema(swaggerSpec, models.Property{Ref: ref}, 0, nil)
+	document["title"] = name
+	definition, _ := lookupDefinition(swaggerSpec, ref)
+	for keyword, variants := range map[string][]models.Definition{"oneOf": definition.OneOf, "anyOf": definition.AnyOf} {
+		if len(variants) == 0 {
+			continue
+		}
+		schemas := make([]interface{}, len(variants))
+		for i, variant := range variants {
+			schemas[i] = nestedSchema(swaggerSpec, models.Property{
+				Type:       variant.Type,
+				Title:      variant.Title,
+				Ref:        variant.Ref,
+				Properties: variant.Properties,
+				Required:   variant.Required,
+			}, 1, []string{ref})
+		}
+		document[keyword] = schemas
+	}
+	return document
+}
+
+// addSchemaResources registers a resource with the JSON schema of each schema
+// of swaggerSpec, so models can look data models up when they need them.
+func addSchemaResources(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSpec, registered *specTools) {
+	for _, name := range specSchemaNames(swaggerSpec) {
+		uri := schemaURI(name)
+		data, err := json.MarshalIndent(schemaDocument(swaggerSpec, name), "", "  ")
+		if err != nil {
+			continue
+		}
+		mcpServer.AddResource(
+			mcp.NewResource(uri, name+" schema",
+				mcp.WithResourceDescription(fmt.Sprintf("JSON schema of the %s data model of the API, with the models it refers to expanded", name)),
+				mcp.WithMIMEType("application/schema+json"),
+			),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return []mcp.ResourceContents{
+					mcp.TextResourceContents{URI: uri, MIMEType: "application/schema+json", Text: string(data)},
+				}, nil
+			},
+		)
+		registered.noteResource(uri)
+	}
+}
+
+// operationSchemas returns the names of the schemas of swaggerSpec the request
+// body and the success responses of an operation are declared with, sorted.
+func operationSchemas(swaggerSpec models.SwaggerSpec, details models.Endpoint) []string {
+	names := []string{}
+	add := func(schema *models.SchemaRef) {
+		for schema != nil && schema.Ref == "" && schema.Items != nil {
+			schema = schema.Items
+		}
+		if schema == nil || schema.Ref == "" {
+			return
+		}
+		if _, found := lookupDefinition(swaggerSpec, schema.Ref); !found {
+			return
+		}
+		if name := ExtractSchemaName(schema.Ref, ""); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, param := range details.Parameters {
+		if param.In == "body" {
+			add(param.Schema)
+		}
+	}
+	if details.RequestBody != nil {
+		for contentType, mediaType := range details.RequestBody.Content {
+			if strings.Contains(contentType, "json") {
+				add(mediaType.Schema)
+			}
+		}
+	}
+	for status, resp := range details.Responses {
+		if code, err := strconv.Atoi(status); err == nil && code >= 200 && code < 300 {
+			add(responseSchema(resp))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describeOperationSchemas points the tool description to the schema
+// resources of the data models of an operation.
+func describeOperationSchemas(swaggerSpec models.SwaggerSpec, details models.Endpoint) string {
+	names := operationSchemas(swaggerSpec, details)
+	if len(names) == 0 {
+		return ""
+	}
+	uris := make([]string, len(names))
+	for i, name := range names {
+		uris[i] = schemaURI(name)
+	}
+	return fmt.Sprintf(" Data models: resource %s.", strings.Join(uris, ", "))
+}
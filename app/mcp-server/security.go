@@ -0,0 +1,171 @@
+package mcpserver
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// securitySchemes returns the security schemes declared by the spec, by name.
+func securitySchemes(swaggerSpec models.SwaggerSpec) map[string]models.SecurityScheme {
+	if swaggerSpec.Components != nil && len(swaggerSpec.Components.SecuritySchemes) > 0 {
+		return swaggerSpec.Components.SecuritySchemes
+	}
+	return swaggerSpec.SecurityDefinitions
+}
+
+// securityTypeNames are the security types, authorizationTypes the ones
+// sending the Authorization header.
+var (
+	authorizationTypes = []string{"basic", "bearer", "negotiate", "ntlm"}
+	securityTypeNames  = append(slices.Clone(authorizationTypes), "apiKey")
+)
+
+// securityTypes splits a security setting into the types applied together to
+// each request, e.g. "apiKey,bearer" for a gateway subscription key plus an
+// OAuth token.
+func securityTypes(security string) []string {
+	types := []string{}
+	for _, securityType := range splitList(security) {
+		if !slices.Contains(types, securityType) {
+			types = append(types, securityType)
+		}
+	}
+	return types
+}
+
+// hasSecurity reports whether security includes securityType.
+func hasSecurity(security, securityType string) bool {
+	return slices.Contains(securityTypes(security), securityType)
+}
+
+// checkSecurity refuses unknown security types and chains of several types
+// sending the Authorization header, a request only has one.
+func checkSecurity(security string) error {
+	authorization := []string{}
+	for _, securityType := range securityTypes(security) {
+		if !slices.Contains(securityTypeNames, securityType) {
+			return fmt.Errorf("unknown security type %s, expected one of %s", securityType, strings.Join(securityTypeNames, ", "))
+		}
+		if slices.Contains(authorizationTypes, securityType) {
+			authorization = append(authorization, securityType)
+		}
+	}
+	if len(authorization) > 1 {
+		return fmt.Errorf("security types %s all use the Authorization header and cannot be chained", strings.Join(authorization, " and "))
+	}
+	return nil
+}
+
+// applySpecSecurity picks the security type of an operation from the spec when
+// none is configured: the operation's own requirement, or the root-level one when
+// the operation declares none. The first requirement whose schemes all have
+// configured credentials wins and its schemes are chained, e.g. an apiKey and
+// an oauth2 scheme listed together. Otherwise the first scheme with configured
+// credentials is used alone.
+func applySpecSecurity(apiCfg models.ApiConfig, swaggerSpec models.SwaggerSpec, details models.Endpoint) models.ApiConfig {
+	if apiCfg.Security != "" {
+		return apiCfg
+	}
+	requirements := details.Security
+	if requirements == nil {
+		requirements = swaggerSpec.Security
+	}
+	schemes := securitySchemes(swaggerSpec)
+	requirementNames := func(requirement models.SecurityRequirement) []string {
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	for _, requirement := range requirements {
+		if len(requirement) < 2 {
+			continue
+		}
+		types, apiKeys := []string{}, []string{}
+		for _, name := range requirementNames(requirement) {
+			scheme, ok := schemes[name]
+			if !ok {
+				types = nil
+				break
+			}
+			security, apiKeyAuth, ok := schemeSecurity(scheme, apiCfg)
+			if !ok {
+				types = nil
+				break
+			}
+			types = append(types, security)
+			if security == "apiKey" && !slices.Contains(apiKeys, apiKeyAuth) {
+				apiKeys = append(apiKeys, apiKeyAuth)
+			}
+		}
+		if types != nil && checkSecurity(strings.Join(types, ",")) == nil {
+			apiCfg.Security = strings.Join(securityTypes(strings.Join(types, ",")), ",")
+			if len(apiKeys) > 0 {
+				apiCfg.ApiKeyAuth = strings.Join(apiKeys, ",")
+			}
+			return apiCfg
+		}
+	}
+	for _, requirement := range requirements {
+		for _, name := range requirementNames(requirement) {
+			scheme, ok := schemes[name]
+			if !ok {
+				continue
+			}
+			if security, apiKeyAuth, ok := schemeSecurity(scheme, apiCfg); ok {
+				apiCfg.Security = security
+				apiCfg.ApiKeyAuth = apiKeyAuth
+				return apiCfg
+			}
+		}
+	}
+	return apiCfg
+}
+
+// schemeSecurity maps a security scheme to the security type to use, when its credentials are configured.
+func schemeSecurity(scheme models.SecurityScheme, apiCfg models.ApiConfig) (string, string, bool) {
+	switch strings.ToLower(scheme.Type) {
+	case "basic":
+		return "basic", apiCfg.ApiKeyAuth, apiCfg.BasicAuth != ""
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "basic":
+			return "basic", apiCfg.ApiKeyAuth, apiCfg.BasicAuth != ""
+		case "bearer":
+			return "bearer", apiCfg.ApiKeyAuth, apiCfg.BearerAuth != ""
+		case "ntlm":
+			return "ntlm", apiCfg.ApiKeyAuth, apiCfg.NtlmAuth != ""
+		}
+	case "oauth2", "openidconnect":
+		return "bearer", apiCfg.ApiKeyAuth, apiCfg.BearerAuth != ""
+	case "apikey":
+		if apiCfg.ApiKeyAuth == "" {
+			return "", "", false
+		}
+		if hasApiKeyLocation(apiCfg.ApiKeyAuth) {
+			return "apiKey", apiCfg.ApiKeyAuth, true
+		}
+		// a bare key is sent where the scheme says
+		return "apiKey", fmt.Sprintf("%s:%s=%s", scheme.In, scheme.Name, apiCfg.ApiKeyAuth), scheme.Name != ""
+	}
+	return "", "", false
+}
+
+// hasApiKeyLocation reports whether apiKeyAuth is in passAs:name=value format.
+func hasApiKeyLocation(apiKeyAuth string) bool {
+	passAs, _, ok := strings.Cut(apiKeyAuth, ":")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(passAs)) {
+	case "header", "query", "cookie":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sensitiveValuesKey carries the values of the sensitive arguments of a call
+// so the handler can keep them out of its log lines.
+const sensitiveValuesKey = "__sensitiveValuesKey"
+
+const (
+	redactedValue = "[REDACTED]"
+
+	// shorter values would redact unrelated text
+	minRedactedLength = 3
+
+	sensitiveNotice = " Sensitive: ask the user to provide this value, never generate, guess or repeat it."
+)
+
+// sensitiveParams returns the parameters and body properties of an operation
+// that hold secrets: those named in configured and those of format password.
+func sensitiveParams(details models.Endpoint, swaggerSpec models.SwaggerSpec, configured []string) []string {
+	isConfigured := func(name string) bool {
+		return slices.ContainsFunc(configured, func(c string) bool { return strings.EqualFold(c, name) })
+	}
+	names := []string{}
+	add := func(name, format string) {
+		if (format == "password" || isConfigured(name)) && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	addDefinition := func(schema *models.SchemaRef) {
+		definition, _ := bodyDefinition(swaggerSpec, schema)
+		for propName, prop := range definition.Properties {
+			add(propName, prop.Format)
+		}
+	}
+	for _, param := range details.Parameters {
+		format := param.Format
+		if param.Schema != nil && format == "" {
+			format = param.Schema.Format
+		}
+		add(param.Name, format)
+		if param.In == "body" && param.Schema != nil {
+			addDefinition(param.Schema)
+		}
+	}
+	if details.RequestBody != nil {
+		for _, mediaType := range details.RequestBody.Content {
+			if mediaType.Schema != nil {
+				addDefinition(mediaType.Schema)
+			}
+		}
+	}
+	return names
+}
+
+// markSensitive tells the client to elicit the sensitive arguments of tool from the user.
+func markSensitive(tool *mcp.Tool, arguments []string) {
+	for _, name := range arguments {
+		if property, ok := tool.InputSchema.Properties[name].(map[string]interface{}); ok {
+			description, _ := property["description"].(string)
+			if description != "" && !strings.HasSuffix(description, ".") {
+				description += "."
+			}
+			property["description"] = description + sensitiveNotice
+		}
+	}
+}
+
+// sensitiveValues returns the values of the sensitive arguments of a call.
+func sensitiveValues(args map[string]interface{}, names []string) []string {
+	values := []string{}
+	for _, name := range names {
+		if arg, ok := args[name]; ok && arg != nil {
+			if value := fmt.Sprint(arg); len(value) >= minRedactedLength {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// redactValues replaces every occurrence of values in text.
+func redactValues(text string, values []string) string {
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, redactedValue)
+	}
+	return text
+}
+
+// redactLog hides the sensitive values of the current call in a log line.
+func redactLog(ctx context.Context, text string) string {
+	values, _ := ctx.Value(sensitiveValuesKey).([]string)
+	return redactValues(text, values)
+}
+
+// RedactCredential hides a configured credential, such as the basic or bearer
+// auth, in log lines.
+func RedactCredential(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// RedactPairs hides the values of a comma separated list of name=value pairs,
+// such as the api keys or the custom headers, keeping their names.
+func RedactPairs(pairs string) string {
+	redacted := []string{}
+	for _, pair := range splitList(pairs) {
+		if name, _, ok := strings.Cut(pair, "="); ok {
+			pair = name + "=" + redactedValue
+		}
+		redacted = append(redacted, pair)
+	}
+	return strings.Join(redacted, ",")
+}
+
+// redactArguments returns a copy of args with the sensitive arguments redacted.
+func redactArguments(args map[string]interface{}, names []string) map[string]interface{} {
+	if len(names) == 0 {
+		return args
+	}
+	redacted := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if slices.Contains(names, name) {
+			value = redactedValue
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// wrapSensitive keeps the values of the sensitive parameters out of the log
+// lines and the results of handler, so they are never echoed back.
+func wrapSensitive(params []string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		values := sensitiveValues(request.GetArguments(), params)
+		if len(values) == 0 {
+			return handler(ctx, request)
+		}
+		result, err := handler(context.WithValue(ctx, sensitiveValuesKey, values), request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		for i, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				text.Text = redactValues(text.Text, values)
+				result.Content[i] = text
+			}
+		}
+		return result, nil
+	}
+}
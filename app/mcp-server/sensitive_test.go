@@ -0,0 +1,29 @@
+package mcpserver
+
+import "testing"
+
+func TestRedactPairs(t *testing.T) {
+	tests := []struct {
+		pairs string
+		want  string
+	}{
+		{"", ""},
+		{"header:X-Api-Key=abc,query:token=xyz", "header:X-Api-Key=[REDACTED],query:token=[REDACTED]"},
+		{"Authorization=Bearer abc, X-Tenant=acme", "Authorization=[REDACTED],X-Tenant=[REDACTED]"},
+		{"malformed", "malformed"},
+	}
+	for _, test := range tests {
+		if got := RedactPairs(test.pairs); got != test.want {
+			t.Errorf("RedactPairs(%q) = %q, want %q", test.pairs, got, test.want)
+		}
+	}
+}
+
+func TestRedactCredential(t *testing.T) {
+	if got := RedactCredential(""); got != "" {
+		t.Errorf("RedactCredential(\"\") = %q, want \"\"", got)
+	}
+	if got := RedactCredential("user:secret"); got != redactedValue {
+		t.Errorf("RedactCredential = %q, want %q", got, redactedValue)
+	}
+}
@@ -0,0 +1,106 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var keyParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// serializeRule serializes the calls of the operations on Path, of every
+// method when Method is empty, under a concurrency key.
+type serializeRule struct {
+	Method string
+	Path   string
+	Key    string
+}
+
+// parseSerializeRules parses rules in [METHOD ]path[=key] format, separated by
+// commas. The key defaults to the path.
+func parseSerializeRules(rules string) []serializeRule {
+	parsed := []serializeRule{}
+	for _, rule := range splitList(rules) {
+		expr, key, _ := strings.Cut(rule, "=")
+		fields := strings.Fields(expr)
+		var parsedRule serializeRule
+		switch len(fields) {
+		case 1:
+			parsedRule.Path = fields[0]
+		case 2:
+			parsedRule.Method, parsedRule.Path = strings.ToLower(fields[0]), fields[1]
+		default:
+			fmt.Printf("Invalid serialize rule: %s\n", rule)
+			continue
+		}
+		parsedRule.Key = strings.TrimSpace(key)
+		if parsedRule.Key == "" {
+			parsedRule.Key = parsedRule.Path
+		}
+		parsed = append(parsed, parsedRule)
+	}
+	return parsed
+}
+
+// concurrencyKey returns the key the calls of an operation are serialized
+// under, from the x-concurrency-key extension or the first matching rule, or
+// "" when they may run in parallel.
+func concurrencyKey(rules []serializeRule, path, method, extension string) string {
+	if extension != "" {
+		return extension
+	}
+	for _, rule := range rules {
+		if rule.Path == path && (rule.Method == "" || strings.EqualFold(rule.Method, method)) {
+			return rule.Key
+		}
+	}
+	return ""
+}
+
+// callSerializer holds one lock per concurrency key so conflicting calls,
+// e.g. two agents writing the same configuration, never interleave.
+type callSerializer struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+var serializedCalls = &callSerializer{locks: map[string]chan struct{}{}}
+
+func (s *callSerializer) lock(key string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks[key] == nil {
+		s.locks[key] = make(chan struct{}, 1)
+	}
+	return s.locks[key]
+}
+
+// wrap runs the calls of handler one at a time per key. {name} references in
+// the key are replaced with the argument values, e.g. /accounts/{id} only
+// serializes the calls on the same account.
+func (s *callSerializer) wrap(key string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		callKey := keyParamPattern.ReplaceAllStringFunc(key, func(ref string) string {
+			name := keyParamPattern.FindStringSubmatch(ref)[1]
+			return fmt.Sprint(request.GetArguments()[name])
+		})
+		lock := s.lock(callKey)
+		select {
+		case lock <- struct{}{}:
+		default:
+			setProgressState(ctx, fmt.Sprintf("waiting for another call on %s to finish", callKey))
+			select {
+			case lock <- struct{}{}:
+			case <-ctx.Done():
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] cancelled while waiting for another call on %s to finish", callKey)), nil
+			}
+		}
+		defer func() { <-lock }()
+		return handler(ctx, request)
+	}
+}
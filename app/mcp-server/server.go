@@ -8,15 +8,20 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hrouis/swagger-mcp/app/models"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 const sseHeadersKey = "__sseHeadersKey"
@@ -93,45 +98,291 @@ func shouldIncludeMethod(method string, includeMethods, excludeMethods []string)
 }
 
 func CreateServer(swaggerSpec models.SwaggerSpec, config models.Config) {
-	mcpServer := server.NewMCPServer(
-		"swagegr-mcp",
-		"1.0.0",
-	)
+	if config.ApiCfg.MetricsAddr != "" {
+		go serveMetrics(config.ApiCfg.MetricsAddr)
+	}
+	if config.SseCfg.SseMode && config.SseCfg.SplitScopes {
+		serveSplitScopes(swaggerSpec, config)
+		return
+	}
+
+	mcpServer := newMCPServer("swagegr-mcp")
 
 	LoadSwaggerServer(mcpServer, swaggerSpec, config.ApiCfg)
 
 	if config.SseCfg.SseMode {
 		// Create and start SSE server
-		sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL(config.SseCfg.SseUrl), server.WithSSEContextFunc(func(ctx context.Context, r *http.Request) context.Context {
-			if len(config.ApiCfg.SseHeaders) == 0 {
-				return ctx
-			}
-			keys := strings.Split(config.ApiCfg.SseHeaders, ",")
-			sseHeaders := map[string]string{}
-			for _, key := range keys {
-				sseHeaders[key] = r.Header.Get(key)
-			}
-			return context.WithValue(ctx, sseHeadersKey, sseHeaders)
-		}))
+		sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL(config.SseCfg.SseUrl), server.WithSSEContextFunc(sseContextFunc(config.ApiCfg)))
 		endpoint, err := sseServer.CompleteSseEndpoint()
 		if err != nil {
 			log.Fatalf("Error creating SSE endpoint: %v", err)
 		}
 		log.Printf("Starting SSE server on %s, endpoint: %s", config.SseCfg.SseAddr, endpoint)
+		keys := clientKeysFor(config.SseCfg)
+		if config.SseCfg.AdminToken != "" || keys != nil {
+			mux := http.NewServeMux()
+			if config.SseCfg.AdminToken != "" {
+				mux.Handle("/admin/", newAdminHandler(config.SseCfg.AdminToken))
+			}
+			mux.Handle("/", requireClientKey(keys, sseServer))
+			if err := http.ListenAndServe(config.SseCfg.SseAddr, mux); err != nil {
+				log.Fatalf("Server error: %v", err)
+			}
+			return
+		}
 		if err := sseServer.Start(config.SseCfg.SseAddr); err != nil {
 			log.Fatalf("Server error: %v", err)
 		}
 	} else {
 		// Run as stdio server
-		if err := server.ServeStdio(mcpServer); err != nil {
+		err := serveStdio(mcpServer)
+		// the client closed stdin, keep the calls counted since the last write
+		flushUsageStats()
+		if err != nil {
 			log.Fatalf("Server error: %v", err)
 		}
 	}
 }
 
-func LoadSwaggerServer(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig) {
+const (
+	scopeRead  = "read"
+	scopeWrite = "write"
+)
+
+// serveSplitScopes serves the read-only tools on /mcp/read and the mutating
+// tools on /mcp/write, so clients can be given different privilege levels.
+func serveSplitScopes(swaggerSpec models.SwaggerSpec, config models.Config) {
+	mux := http.NewServeMux()
+	keys := clientKeysFor(config.SseCfg)
+	for _, scope := range []string{scopeRead, scopeWrite} {
+		apiCfg := config.ApiCfg
+		apiCfg.Scope = scope
+		mcpServer := newMCPServer("swagegr-mcp-" + scope)
+		LoadSwaggerServer(mcpServer, swaggerSpec, apiCfg)
+
+		basePath := "/mcp/" + scope
+		sseServer := server.NewSSEServer(mcpServer,
+			server.WithBaseURL(config.SseCfg.SseUrl),
+			server.WithStaticBasePath(basePath),
+			server.WithSSEContextFunc(sseContextFunc(apiCfg)),
+		)
+		endpoint, err := sseServer.CompleteSseEndpoint()
+		if err != nil {
+			log.Fatalf("Error creating SSE endpoint: %v", err)
+		}
+		log.Printf("Serving %s tools on SSE endpoint: %s", scope, endpoint)
+		mux.Handle(basePath+"/", requireClientKey(keys, sseServer))
+	}
+	if config.SseCfg.AdminToken != "" {
+		mux.Handle("/admin/", newAdminHandler(config.SseCfg.AdminToken))
+	}
+	log.Printf("Starting SSE server on %s", config.SseCfg.SseAddr)
+	if err := http.ListenAndServe(config.SseCfg.SseAddr, mux); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+// clientKeysFor opens the client key file of the SSE server, nil when keys are not required.
+func clientKeysFor(sseCfg models.SseConfig) *clientKeyStore {
+	if sseCfg.ClientKeys == "" {
+		return nil
+	}
+	keys, err := newClientKeyStore(sseCfg.ClientKeys)
+	if err != nil {
+		log.Fatalf("Error loading client keys: %v", err)
+	}
+	return keys
+}
+
+// requireClientKey guards handler with the client keys, when there are any to check.
+func requireClientKey(keys *clientKeyStore, handler http.Handler) http.Handler {
+	if keys == nil {
+		return handler
+	}
+	return keys.require(handler)
+}
+
+// sseContextFunc passes the configured SSE request headers on to the tool handlers.
+func sseContextFunc(apiCfg models.ApiConfig) server.SSEContextFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if len(apiCfg.SseHeaders) == 0 {
+			return ctx
+		}
+		keys := strings.Split(apiCfg.SseHeaders, ",")
+		sseHeaders := map[string]string{}
+		for _, key := range keys {
+			sseHeaders[key] = r.Header.Get(key)
+		}
+		return context.WithValue(ctx, sseHeadersKey, sseHeaders)
+	}
+}
+
+// inScope keeps the scopes disjoint: "read" serves the safe methods, "write" the mutating ones.
+func inScope(scope, method string) bool {
+	switch scope {
+	case scopeRead:
+		return !isMutatingMethod(method)
+	case scopeWrite:
+		return isMutatingMethod(method)
+	}
+	return true
+}
+
+// methodAnnotations tells clients what a call of the tool of an operation
+// does from its HTTP method, for their confirmation policies: GET, HEAD and
+// OPTIONS only read, PUT and DELETE are idempotent and may destroy data, as
+// may POST and PATCH by the MCP defaults.
+func methodAnnotations(method string) mcp.ToolAnnotation {
+	readOnly, destructive, idempotent := false, true, false
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		readOnly, destructive, idempotent = true, false, true
+	case http.MethodPut, http.MethodDelete:
+		idempotent = true
+	}
+	return mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(readOnly),
+		DestructiveHint: mcp.ToBoolPtr(destructive),
+		IdempotentHint:  mcp.ToBoolPtr(idempotent),
+		OpenWorldHint:   mcp.ToBoolPtr(true),
+	}
+}
+
+// shouldIncludeTool applies the exact tool name overrides on top of the path and
+// method filter result: excluded names are always dropped, included names are always kept.
+func shouldIncludeTool(toolName string, filtered bool, includeTools, excludeTools []string) bool {
+	if slices.Contains(excludeTools, toolName) {
+		return false
+	}
+	return filtered || slices.Contains(includeTools, toolName)
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// externalDocLinks returns the externalDocs links of the operation and of its tags.
+func externalDocLinks(details models.Endpoint, tags []models.Tag) []string {
+	links := []string{}
+	addLink := func(docs *models.ExternalDocs) {
+		if docs == nil || docs.URL == "" {
+			return
+		}
+		link := docs.URL
+		if docs.Description != "" {
+			link = fmt.Sprintf("%s (%s)", docs.URL, docs.Description)
+		}
+		if !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+	}
+	addLink(details.ExternalDocs)
+	for _, tag := range tags {
+		if slices.Contains(details.Tags, tag.Name) {
+			addLink(tag.ExternalDocs)
+		}
+	}
+	return links
+}
+
+// relatedTools lists the tools of the same resource family: the other methods of
+// the path, its parent path and its direct sub-paths.
+func relatedTools(path, method string, toolNames map[string]map[string]string) []string {
+	parent := parentPath(path)
+	related := []string{}
+	for otherPath, methods := range toolNames {
+		if otherPath != path && otherPath != parent && parentPath(otherPath) != path {
+			continue
+		}
+		for otherMethod, name := range methods {
+			if otherPath == path && otherMethod == method {
+				continue
+			}
+			related = append(related, name)
+		}
+	}
+	sort.Strings(related)
+	return slices.Compact(related)
+}
+
+func parentPath(path string) string {
+	trimmed := strings.TrimSuffix(path, "/")
+	if idx := strings.LastIndex(trimmed, "/"); idx > 0 {
+		return trimmed[:idx]
+	}
+	return "/"
+}
+
+// vendorBackendURL returns the backend URL declared by a gateway vendor
+// extension. constant is set when the address is used as-is, in which case
+// x-google-backend sends the path parameters as query parameters.
+func vendorBackendURL(details models.Endpoint, path string) (backendURL string, constant bool, ok bool) {
+	if backend := details.GoogleBackend; backend != nil && backend.Address != "" {
+		if strings.EqualFold(backend.PathTranslation, "APPEND_PATH_TO_ADDRESS") {
+			return strings.TrimSuffix(backend.Address, "/") + "/" + strings.TrimPrefix(path, "/"), false, true
+		}
+		return backend.Address, true, true
+	}
+	if integration := details.AmazonIntegration; integration != nil && integration.Uri != "" {
+		switch strings.ToLower(integration.Type) {
+		case "http", "http_proxy":
+			return integration.Uri, false, true
+		}
+	}
+	return "", false, false
+}
+
+// matchRoute returns the first route whose path prefix or tag matches the operation.
+func matchRoute(routes []models.RouteConfig, path string, tags []string) (models.RouteConfig, bool) {
+	for _, route := range routes {
+		if route.PathPrefix != "" && !strings.HasPrefix(path, route.PathPrefix) {
+			continue
+		}
+		if route.Tag != "" && !slices.Contains(tags, route.Tag) {
+			continue
+		}
+		if route.PathPrefix == "" && route.Tag == "" {
+			continue
+		}
+		return route, true
+	}
+	return models.RouteConfig{}, false
+}
+
+// applyRoute returns a copy of apiCfg using the route's base URL and, when set, its URL rewrites and credentials.
+func applyRoute(apiCfg models.ApiConfig, route models.RouteConfig) models.ApiConfig {
+	if route.BaseUrl != "" {
+		// the failover servers are the ones of the global base URL
+		apiCfg.BaseUrl = route.BaseUrl
+		apiCfg.FailoverUrls = ""
+	}
+	if route.UrlRewrites != "" {
+		apiCfg.UrlRewrites = route.UrlRewrites
+	}
+	if route.Security != "" {
+		apiCfg.Security = route.Security
+		apiCfg.BasicAuth = route.BasicAuth
+		apiCfg.ApiKeyAuth = route.ApiKeyAuth
+		apiCfg.BearerAuth = route.BearerAuth
+		apiCfg.NtlmAuth = route.NtlmAuth
+	}
+	return apiCfg
+}
+
+// OperationFilter returns the path, method and tool name filters of apiCfg as a
+// single predicate telling whether an operation is exposed as a tool.
+func OperationFilter(apiCfg models.ApiConfig, swaggerSpec models.SwaggerSpec) func(path, method string) bool {
 	includeRegexes := compileRegexes(apiCfg.IncludePaths)
 	excludeRegexes := compileRegexes(apiCfg.ExcludePaths)
+	includeOperationRegexes := compileRegexes(apiCfg.IncludeOperations)
+	excludeOperationRegexes := compileRegexes(apiCfg.ExcludeOperations)
 	includedMethods := []string{}
 	if len(strings.TrimSpace(apiCfg.IncludeMethods)) > 0 {
 		includedMethods = strings.Split(apiCfg.IncludeMethods, ",")
@@ -140,28 +391,225 @@ func LoadSwaggerServer(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSp
 	if len(strings.TrimSpace(apiCfg.ExcludeMethods)) > 0 {
 		excludedMethods = strings.Split(apiCfg.ExcludeMethods, ",")
 	}
+	includedTools := splitList(apiCfg.IncludeTools)
+	excludedTools := splitList(apiCfg.ExcludeTools)
+	toolNames, _ := operationToolNames(apiCfg, swaggerSpec)
+
+	return func(path, method string) bool {
+		if !inScope(apiCfg.Scope, method) || !inVersion(apiCfg.ApiVersion, path) {
+			return false
+		}
+		filtered := shouldIncludePath(path, includeRegexes, excludeRegexes) && shouldIncludeMethod(method, includedMethods, excludedMethods)
+		// operationIds are matched like paths, operations without one only match ^$
+		filtered = filtered && shouldIncludePath(swaggerSpec.Paths[path][method].OperationID, includeOperationRegexes, excludeOperationRegexes)
+		return shouldIncludeTool(toolNames[path][method], filtered, includedTools, excludedTools)
+	}
+}
+
+func LoadSwaggerServer(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig) {
+	if err := loadSwagger(mcpServer, swaggerSpec, apiCfg, nil); err != nil {
+		log.Fatalf("Error loading the spec: %v", err)
+	}
+}
+
+// loadSwagger registers the tools of swaggerSpec on mcpServer and notes the
+// ones specific to the spec in registered when it is not nil. The settings of
+// apiCfg are checked before anything is registered, an error leaves
+// mcpServer as it was.
+func loadSwagger(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig, registered *specTools) error {
+	if apiCfg.ToolNameTemplate != "" {
+		if _, err := parseToolNameTemplate(apiCfg.ToolNameTemplate); err != nil {
+			return fmt.Errorf("invalid tool name template: %v", err)
+		}
+	}
+	includeOperation := OperationFilter(apiCfg, swaggerSpec)
+
+	var manifest *ToolManifest
+	if apiCfg.ToolManifest != "" {
+		var err error
+		if manifest, err = LoadToolManifest(apiCfg.ToolManifest, apiCfg.ManifestKey); err != nil {
+			return fmt.Errorf("failed to load tool manifest: %v", err)
+		}
+	}
+
+	maintenance, err := parseMaintenanceWindows(apiCfg.MaintenanceWindows)
+	if err != nil {
+		return fmt.Errorf("invalid maintenance windows: %v", err)
+	}
+	var maxBodySize int64
+	if apiCfg.MaxBodySize != "" {
+		if maxBodySize, err = parseBodySize(apiCfg.MaxBodySize); err != nil {
+			return fmt.Errorf("invalid maximum body size: %v", err)
+		}
+	}
+	bodySizeRules, err := parseBodySizeRules(apiCfg.BodySizeLimits)
+	if err != nil {
+		return fmt.Errorf("invalid body size limits: %v", err)
+	}
+	timezone := time.UTC
+	if apiCfg.Timezone != "" {
+		if timezone, err = time.LoadLocation(apiCfg.Timezone); err != nil {
+			return fmt.Errorf("failed to load timezone: %v", err)
+		}
+	}
+
+	var playbooks map[string]string
+	if apiCfg.Playbooks != "" {
+		if playbooks, err = loadPlaybooks(apiCfg.Playbooks); err != nil {
+			return fmt.Errorf("failed to load playbooks: %v", err)
+		}
+	}
 
+	// the settings of every exposed operation, by path and method
+	opSettings := map[string]map[string]operationSettings{}
 	for path, methods := range swaggerSpec.Paths {
+		for method, details := range methods {
+			if !includeOperation(path, method) {
+				continue
+			}
+			settings, err := operationSettingsFor(apiCfg, swaggerSpec, path, method, details)
+			if err != nil {
+				return err
+			}
+			if opSettings[path] == nil {
+				opSettings[path] = map[string]operationSettings{}
+			}
+			opSettings[path][method] = settings
+		}
+	}
 
-		if !shouldIncludePath(path, includeRegexes, excludeRegexes) {
-			continue
+	var downloads *downloadRoots
+	if apiCfg.DownloadRoots != "" {
+		if downloads, err = newDownloadRoots(apiCfg.DownloadRoots, apiCfg.DownloadThreshold); err != nil {
+			return fmt.Errorf("invalid download roots: %v", err)
 		}
+	}
+
+	var usage *usageRecorder
+	if apiCfg.StatsFile != "" {
+		if usage, err = openUsageStats(apiCfg.StatsFile); err != nil {
+			return fmt.Errorf("failed to open usage stats: %v", err)
+		}
+	}
+	// tools registered, for the usage stats
+	toolCount := 0
+
+	var history *historyStore
+	if apiCfg.HistoryDb != "" {
+		if history, err = newHistoryStore(apiCfg.HistoryDb); err != nil {
+			return fmt.Errorf("failed to create history store: %v", err)
+		}
+	}
+
+	if hasSecurity(apiCfg.Security, "negotiate") {
+		// read the Kerberos config and tickets once up front, the calls reuse them
+		if _, err := kerberos.load(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	csrf := newCsrfManager(apiCfg)
+	if csrf != nil {
+		sessionEnds.add(mcpServer, "csrf", csrf.forget)
+	}
+	if history != nil {
+		history.addQueryHistoryTool(mcpServer)
+	}
+
+	headerCaptures := parseHeaderCaptures(apiCfg.HeaderCaptures)
+	var variables *variableStore
+	if apiCfg.SessionVariables || apiCfg.CaptureRules != "" || len(headerCaptureRules(headerCaptures)) > 0 {
+		variables = newVariableStore(append(parseCaptureRules(apiCfg.CaptureRules), headerCaptureRules(headerCaptures)...))
+		variables.addVariableTools(mcpServer)
+		sessionEnds.add(mcpServer, "variables", variables.forget)
+	}
+	if apiCfg.ContentHash {
+		addWatchTools(mcpServer)
+	}
+
+	var cache *responseCache
+	if apiCfg.EtagCache {
+		cache = newResponseCache()
+	}
+
+	var progress *progressReporter
+	if apiCfg.ProgressInterval > 0 {
+		progress = &progressReporter{interval: time.Duration(apiCfg.ProgressInterval) * time.Second}
+	}
 
+	paramAliases := parseParamAliases(apiCfg.ParamAliases)
+	elevated := compileRegexes(apiCfg.ElevatedTools)
+	serializeRules := parseSerializeRules(apiCfg.SerializeCalls)
+	derivedFields := parseDerivedFields(apiCfg.DerivedFields)
+	sensitiveNames := splitList(apiCfg.SensitiveParams)
+
+	var requests *requestLog
+	if apiCfg.RequestLog > 0 {
+		requests = openRequestLog(mcpServer, apiCfg.RequestLog)
+	}
+
+	var scrubber *responseScrubber
+	if apiCfg.ScrubResponses {
+		scrubber = newResponseScrubber(apiCfg.ScrubPatterns)
+	}
+
+	var differ *pollingDiffer
+	if apiCfg.PollingDiff {
+		differ = newPollingDiffer()
+	}
+
+	var quota *quotaTracker
+	if apiCfg.MaxCalls > 0 || apiCfg.MaxMutatingCalls > 0 {
+		quota = newQuotaTracker(apiCfg.MaxCalls, apiCfg.MaxMutatingCalls)
+		sessionEnds.add(mcpServer, "quota", quota.forget)
+	}
+
+	// tool names by tag, listed in the playbooks
+	tagTools := map[string][]string{}
+
+	// tool names of the exposed operations, by path and method
+	allToolNames, renamed := operationToolNames(apiCfg, swaggerSpec)
+	for _, rename := range renamed {
+		log.Printf("Warning: tool name collision, %s", rename)
+	}
+	toolNames := map[string]map[string]string{}
+	for path, methods := range swaggerSpec.Paths {
+		for method := range methods {
+			if includeOperation(path, method) {
+				if toolNames[path] == nil {
+					toolNames[path] = map[string]string{}
+				}
+				toolNames[path][method] = allToolNames[path][method]
+			}
+		}
+	}
+
+	warnPathOverlaps(toolNames)
+	var promptNames map[string]string
+	if apiCfg.ExamplePrompts {
+		promptNames = examplePromptNames(toolNames)
+	}
+
+	for path, methods := range swaggerSpec.Paths {
 		for method, details := range methods {
-			if !shouldIncludeMethod(method, includedMethods, excludedMethods) {
+			if !includeOperation(path, method) {
 				continue
 			}
+			toolName := toolNames[path][method]
 			expectedResponse := []string{}
 			toolOption := []mcp.ToolOption{}
 
 			var reqURL string
 			var baseURL string
 
-			if apiCfg.BaseUrl == "" {
+			opCfg := opSettings[path][method].apiCfg
+
+			if opCfg.BaseUrl == "" {
 				// Determine base URL based on version
 				if swaggerSpec.OpenAPI != "" {
 					// OpenAPI 3.0
-					if len(swaggerSpec.Servers) > 0 {
+					if len(details.Servers) > 0 {
+						baseURL = strings.TrimSuffix(details.Servers[0].URL, "/")
+					} else if len(swaggerSpec.Servers) > 0 {
 						baseURL = strings.TrimSuffix(swaggerSpec.Servers[0].URL, "/")
 					} else {
 						baseURL = "/" // Default to relative path if no servers defined
@@ -177,91 +625,193 @@ func LoadSwaggerServer(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSp
 					}
 				}
 			} else {
-				baseURL = apiCfg.BaseUrl
+				baseURL = opCfg.BaseUrl
 			}
 
 			reqURL = strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+			var failover *failoverGroup
+			if opCfg.FailoverUrls != "" {
+				failover = failoverFor(failoverBases(opCfg, swaggerSpec, details, baseURL))
+			}
+			pathParamsAsQuery := false
+			if apiCfg.VendorBackends {
+				if backendURL, constant, ok := vendorBackendURL(details, path); ok {
+					reqURL = backendURL
+					pathParamsAsQuery = constant
+					failover = nil
+				}
+			}
 
 			reqMethod := fmt.Sprint(method)
 			reqBody := make(map[string]interface{})
+			reqBodyOrder := []string{}
+			reqBodyOptional := map[string]bool{}
+			reqBodyNullable := map[string]bool{}
+			reqBodyEnums := map[string][]interface{}{}
+			reqBodyDefaults := map[string]interface{}{}
+			rawBodyParam := ""
+			rawBodyContentType := ""
+			rawBodyRequired := false
 			reqPathParam := []string{}
 			reqQueryParam := []string{}
 			reqHeader := []string{}
+			reqHeaderSpecs := map[string]models.Parameter{}
+			reqCookie := []string{}
+			reqParamSpecs := map[string]models.Parameter{}
+			presets := bodyPresets(details)
+			var variants *bodyVariants
+			var validator *bodySchema
+			if apiCfg.ValidateBody {
+				validator = newBodySchema()
+			}
+			aliases := newArgumentAliases(paramAliases)
+			notes := &limitationNotes{method: method, path: path, toolName: toolName}
 
 			for _, param := range details.Parameters {
 				if param.In == "header" {
-					if param.Required {
-						toolOption = append(toolOption, mcp.WithString(
-							fmt.Sprint(param.Name),
-							mcp.Description(fmt.Sprintf("The data for %s", param.Name)),
-							mcp.Required(),
-						))
-					} else {
-						toolOption = append(toolOption, mcp.WithString(
-							fmt.Sprint(param.Name),
-							mcp.Description(fmt.Sprintf("The data for %s", param.Name)),
-						))
-					}
+					notes.paramLimitation(param)
+					toolOption = append(toolOption, typedParamOption(aliases.name(param.Name), param))
+					reqHeaderSpecs[param.Name] = param
 					reqHeader = append(reqHeader, param.Name)
 				}
 			}
+			for _, param := range details.Parameters {
+				if param.In == "cookie" {
+					notes.paramLimitation(param)
+					toolOption = append(toolOption, typedParamOption(aliases.name(param.Name), param))
+					reqParamSpecs[param.Name] = param
+					reqCookie = append(reqCookie, param.Name)
+				}
+			}
 			for _, param := range details.Parameters {
 				if param.In == "query" {
-					if param.Required {
-						toolOption = append(toolOption, mcp.WithString(
-							fmt.Sprint(param.Name),
-							mcp.Description(fmt.Sprintf("The data for %s", param.Name)),
-							mcp.Required(),
-						))
+					if prop, ok := queryObject(swaggerSpec, param); ok {
+						for _, name := range objectQueryCollisions(param, prop, details.Parameters) {
+							notes.add("the property %s of the object parameter %s has the name of the query parameter %s, calls setting it in %s are refused", name, param.Name, name, param.Name)
+						}
+						toolOption = append(toolOption, objectParamOption(swaggerSpec, aliases.name(param.Name), param, prop))
+						// sent in its style rather than as a string
+						param.Type = "object"
 					} else {
-						toolOption = append(toolOption, mcp.WithString(
-							fmt.Sprint(param.Name),
-							mcp.Description(fmt.Sprintf("The data for %s", param.Name)),
-						))
+						notes.paramLimitation(param)
+						toolOption = append(toolOption, typedParamOption(aliases.name(param.Name), param))
 					}
+					reqParamSpecs[param.Name] = param
 					reqQueryParam = append(reqQueryParam, param.Name)
 				}
 			}
 
-			for _, param := range details.Parameters {
-				if param.In == "path" {
-					if param.Required {
-						toolOption = append(toolOption, mcp.WithString(
-							fmt.Sprint(param.Name),
-							mcp.Description(fmt.Sprintf("The data for %s", param.Name)),
-							mcp.Required(),
-						))
-					} else {
-						toolOption = append(toolOption, mcp.WithString(
-							fmt.Sprint(param.Name),
-							mcp.Description(fmt.Sprintf("The data for %s", param.Name)),
-						))
+			declaredPathParams, undeclared, unused := pathParams(path, details)
+			for _, name := range undeclared {
+				notes.add("the operation declares no parameter for the {%s} placeholder of its path, the argument takes a string", name)
+			}
+			for _, name := range unused {
+				notes.add("the path parameter %s has no placeholder in the path, it is left out", name)
+			}
+			for _, param := range declaredPathParams {
+				notes.paramLimitation(param)
+				toolOption = append(toolOption, typedParamOption(aliases.name(param.Name), param))
+				reqParamSpecs[param.Name] = param
+				reqPathParam = append(reqPathParam, param.Name)
+			}
+			if pathParamsAsQuery {
+				reqQueryParam = append(reqQueryParam, reqPathParam...)
+				reqPathParam = []string{}
+			}
+			// addBodyDefinition exposes the properties of a body schema, Swagger 2.0
+			// or OpenAPI 3.0, as tool arguments
+			addBodyDefinition := func(definition models.Definition) {
+				for propName, prop := range definition.Properties {
+					prop = resolveProperty(swaggerSpec, prop)
+					propOptions := []mcp.PropertyOption{
+						mcp.Description(propertyDescription(propName, prop)),
+					}
+					if prop.Default != nil {
+						// left out, the default is sent
+						reqBodyDefaults[propName] = prop.Default
+					} else if !slices.Contains(definition.Required, propName) {
+						reqBodyOptional[propName] = true
+					} else if len(presets) == 0 {
+						// with presets the body can come from an example instead
+						propOptions = append(propOptions, mcp.Required())
+					}
+					if prop.IsNullable() {
+						propOptions = append(propOptions, nullableOption())
+						reqBodyNullable[propName] = true
 					}
-					reqPathParam = append(reqPathParam, param.Name)
+					toolOption = append(toolOption, bodyPropertyOption(swaggerSpec, aliases.name(propName), prop, propOptions))
+					reqBody[propName] = prop.Type
+					if len(prop.Enum) > 0 && !isNestedType(prop.Type) {
+						reqBodyEnums[propName] = prop.Enum
+					}
+				}
+				reqBodyOrder = append(reqBodyOrder, definition.PropertyOrder...)
+				if validator != nil {
+					validator.add(definition, aliases)
 				}
+				notes.bodyLimitations(swaggerSpec, definition)
 			}
 			for _, param := range details.Parameters {
 				if param.In == "body" {
-					schemaName := ExtractSchemaName(param.Schema.Ref, param.Type)
-					if definition, found := swaggerSpec.Definitions[schemaName]; found {
-						for propName, prop := range definition.Properties {
-							toolOption = append(toolOption, mcp.WithString(
-								fmt.Sprint(propName),
-								mcp.Description(fmt.Sprintf("The data for %s, it should be in format of %s", propName, prop.Type)),
-								mcp.Required(),
-							))
-							reqBody[propName] = prop.Type
+					if param.Schema != nil && param.Schema.Ref == "" && isPrimitiveType(param.Schema.Type) {
+						rawBodyParam = param.Name
+						rawBodyContentType = "text/plain"
+						if len(details.Consumes) > 0 {
+							rawBodyContentType = details.Consumes[0]
 						}
+						rawBodyRequired = param.Required
+						toolOption = append(toolOption, rawBodyOption(param.Name, param.Schema.Type, param.Required && downloads == nil))
+						continue
+					}
+					variants = bodyVariantsOf(swaggerSpec, param.Schema)
+					if definition, found := bodyDefinition(swaggerSpec, param.Schema); found {
+						addBodyDefinition(definition)
+					}
+				}
+			}
+			for _, param := range details.Parameters {
+				// file parameters need a multipart body and are left out
+				if param.In == "formData" && paramType(param) == "file" {
+					notes.add("the file parameter %s needs a multipart upload, which is not supported, it is left out", param.Name)
+				}
+				if param.In == "formData" && paramType(param) != "file" {
+					toolOption = append(toolOption, typedParamOption(aliases.name(param.Name), param))
+					reqParamSpecs[param.Name] = param
+					reqBody[param.Name] = paramType(param)
+					reqBodyOrder = append(reqBodyOrder, param.Name)
+					if value := paramDefault(param); value != nil {
+						reqBodyDefaults[param.Name] = value
+					} else if !param.Required {
+						reqBodyOptional[param.Name] = true
+					}
+					if enum := paramEnum(param); len(enum) > 0 && paramType(param) != "array" {
+						reqBodyEnums[param.Name] = enum
 					}
 				}
 			}
 			if details.RequestBody != nil {
 				for contentType, mediaType := range details.RequestBody.Content {
 					fmt.Printf("  content type: %s\n", contentType)
+					if mediaType.Schema == nil {
+						continue
+					}
+					if mediaType.Schema.Ref == "" && isPrimitiveType(mediaType.Schema.Type) {
+						if rawBodyParam == "" {
+							rawBodyParam = "body"
+							rawBodyContentType = contentType
+							rawBodyRequired = details.RequestBody.Required
+							toolOption = append(toolOption, rawBodyOption(rawBodyParam, mediaType.Schema.Type, details.RequestBody.Required && downloads == nil))
+						}
+						continue
+					}
 					schemaName := ExtractSchemaName(mediaType.Schema.Ref, mediaType.Schema.Type)
 					fmt.Printf("  Schema: %s\n", schemaName)
-					if definition, found := swaggerSpec.Components.Schemas[schemaName]; found {
+					if variants == nil {
+						variants = bodyVariantsOf(swaggerSpec, mediaType.Schema)
+					}
+					if definition, found := bodyDefinition(swaggerSpec, mediaType.Schema); found {
 						for propName, prop := range definition.Properties {
+							prop = resolveProperty(swaggerSpec, prop)
 							fmt.Printf("    - %s: %s\n", propName, prop.Type)
 
 							if prop.Type == "array" {
@@ -271,22 +821,36 @@ func LoadSwaggerServer(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSp
 									for propName, prop := range items.Properties {
 										toolOption = append(toolOption, mcp.WithString(
 											fmt.Sprint(propName),
-											mcp.Description(fmt.Sprintf("The item  for %s, it should be in format of %s", propName, prop.Type)),
+											mcp.Description(schemaDescription(fmt.Sprintf("The item  for %s, it should be in format of %s", propName, prop.Type), prop.Title, prop.Description, prop.Type, prop.Format, prop.Enum, prop.Example)),
 											mcp.Required(),
 										))
 									}
 								}
 							}
-							toolOption = append(toolOption, mcp.WithString(
-								fmt.Sprint(propName),
-								mcp.Description(fmt.Sprintf("The data for %s, it should be in format of %s", propName, prop.Type)),
-								mcp.Required(),
-							))
-							reqBody[propName] = prop.Type
 						}
+						addBodyDefinition(definition)
+					}
+				}
+			}
+			if len(reqBody) == 0 && rawBodyParam == "" && variants == nil {
+				// no fields could be read from the body schema, it is passed whole
+				if name, schema, contentType := wholeBody(details); schema != nil {
+					rawBodyParam, rawBodyContentType = name, contentType
+					rawBodyRequired = details.RequestBody != nil && details.RequestBody.Required || slices.ContainsFunc(details.Parameters, func(param models.Parameter) bool { return param.In == "body" && param.Required })
+					toolOption = append(toolOption, wholeBodyOption(swaggerSpec, name, schema, rawBodyRequired && downloads == nil))
+					if _, found := lookupDefinition(swaggerSpec, schema.Ref); schema.Ref != "" && !found {
+						notes.add("the request body refers to the schema %s the spec does not define, it is passed whole as an object", ExtractSchemaName(schema.Ref, ""))
 					}
+				} else if details.RequestBody != nil {
+					notes.add("the request body declares no schema, the tool sends none")
 				}
 			}
+			if len(presets) > 0 {
+				toolOption = append(toolOption, presetOption(presets))
+			}
+			if variants != nil {
+				toolOption = append(toolOption, variants.option())
+			}
 			for status, resp := range details.Responses {
 				if resp.Schema != nil {
 					schemaName := ExtractSchemaName(resp.Schema.Ref, resp.Schema.Type)
@@ -299,38 +863,228 @@ func LoadSwaggerServer(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSp
 				}
 			}
 
-			toolOption = append(toolOption, mcp.WithDescription(fmt.Sprintf(`Use this tool only when the request exactly matches %s or %s. If you dont have any of the required parameters then always ask user for it, *Dont fill any paramter on your own or keep it empty*. If there is [Error], only state that error in your reponse and stop the reponse there itself. *Do not ever maintain records in your memory for eg list of users or orders*`,
-				details.Summary, details.Description)))
-
-			pathWithoutDot := strings.ReplaceAll(path, "/", "_")
-
-			toolName := fmt.Sprintf("%s_%s", method, strings.ReplaceAll(strings.ReplaceAll(pathWithoutDot, "}", ""), "{", ""))
+			description := fmt.Sprintf(`Use this tool only when the request exactly matches %s or %s. If you dont have any of the required parameters then always ask user for it, *Dont fill any paramter on your own or keep it empty*. If there is [Error], only state that error in your reponse and stop the reponse there itself. *Do not ever maintain records in your memory for eg list of users or orders*`,
+				details.Summary, details.Description)
+			if docs := externalDocLinks(details, swaggerSpec.Tags); len(docs) > 0 {
+				description += fmt.Sprintf(" Documentation: %s.", strings.Join(docs, ", "))
+			}
+			declaredSuccess := successResponses(details)
+			if responses := describeSuccessResponses(declaredSuccess); responses != "" {
+				description += fmt.Sprintf(" Success responses: %s.", responses)
+			}
+			if related := relatedTools(path, method, toolNames); len(related) > 0 {
+				description += fmt.Sprintf(" Related tools: %s.", strings.Join(related, ", "))
+			}
+			description += describePathOverlaps(path, method, toolNames)
+			if apiCfg.DocResources {
+				description += fmt.Sprintf(" Example responses: resource %s%s.", operationDocsURI, toolName)
+			}
+			if apiCfg.SchemaResources {
+				description += describeOperationSchemas(swaggerSpec, details)
+			}
+			if apiCfg.VersionedTools {
+				if version, _ := pathVersion(path); version != "" {
+					description += fmt.Sprintf(" This is the %s API.", version)
+					if others := otherVersionTools(path, method, toolNames); len(others) > 0 {
+						description += fmt.Sprintf(" Other versions: %s. Do not mix tools of different versions.", strings.Join(others, ", "))
+					}
+				}
+			}
+			toolOption = append(toolOption, mcp.WithDescription(description), mcp.WithToolAnnotation(methodAnnotations(method)))
+			// a response saved as a download has no structured content
+			var output map[string]interface{}
+			if downloads == nil {
+				output = outputSchema(swaggerSpec, details)
+			}
+			if output != nil {
+				toolOption = append(toolOption, outputSchemaOption(output))
+			}
+			if downloads != nil {
+				toolOption = append(toolOption, mcp.WithString(saveToArgument, mcp.Description(downloads.describe())))
+				if rawBodyParam != "" {
+					toolOption = append(toolOption, mcp.WithString(bodyFileArgument, mcp.Description(downloads.describeUpload())))
+				}
+			}
 
-			if len(toolName) >= 40 {
-				toolName = toolName[:40]
+			// DELETE and PUT first check the resource with the GET of the same path
+			_, hasGet := methods["get"]
+			checkExists := apiCfg.ExistenceCheck && hasGet && (strings.EqualFold(method, http.MethodDelete) || strings.EqualFold(method, http.MethodPut))
 
+			handler := createToolHandler(toolHandlerConfig{
+				reqPathParam:       reqPathParam,
+				reqQueryParam:      reqQueryParam,
+				reqParamSpecs:      reqParamSpecs,
+				reqURL:             reqURL,
+				reqBody:            reqBody,
+				reqBodyOrder:       reqBodyOrder,
+				reqBodyOptional:    reqBodyOptional,
+				reqBodyNullable:    reqBodyNullable,
+				reqBodyEnums:       reqBodyEnums,
+				reqBodyDefaults:    reqBodyDefaults,
+				rawBodyParam:       rawBodyParam,
+				rawBodyContentType: rawBodyContentType,
+				rawBodyRequired:    rawBodyRequired,
+				reqMethod:          reqMethod,
+				reqHeader:          reqHeader,
+				reqHeaderSpecs:     reqHeaderSpecs,
+				reqCookie:          reqCookie,
+				consumes:           operationConsumes(swaggerSpec, details),
+				produces:           details.Produces,
+				csrf:               csrf,
+				csrfURL:            csrfTokenURL(baseURL, opCfg.CsrfTokenUrl),
+				createdGetTool:     itemGetTool(path, toolNames),
+				declaredSuccess:    declaredSuccess,
+				cache:              cache,
+				downloads:          downloads,
+				toolName:           toolName,
+				headerCaptures:     headerCapturesFor(headerCaptures, path),
+				presets:            presets,
+				validator:          validator,
+				variants:           variants,
+				checkExists:        checkExists,
+				rewrites:           opSettings[path][method].rewrites,
+				failover:           failover,
+				specVersion:        specVersion(swaggerSpec),
+				bodyLimit:          bodySizeLimit(bodySizeRules, path, method, maxBodySize),
+				aliases:            aliases,
+				apiCfg:             opCfg,
+			})
+			handler = hookHandler(details.OperationID, handler)
+			if formats := dateParams(details, swaggerSpec); len(formats) > 0 {
+				handler = wrapDates(formats, timezone, handler)
+			}
+			if apiCfg.LatencySlo > 0 {
+				handler = wrapLatencySlo(toolName, time.Duration(apiCfg.LatencySlo)*time.Millisecond, handler)
+			}
+			if key := concurrencyKey(serializeRules, path, method, details.ConcurrencyKey); key != "" {
+				handler = serializedCalls.wrap(key, handler)
+			}
+			secrets := sensitiveParams(details, swaggerSpec, sensitiveNames)
+			secretArguments := make([]string, len(secrets))
+			for i, name := range secrets {
+				secretArguments[i] = aliases.name(name)
+			}
+			if len(secrets) > 0 {
+				handler = wrapSensitive(secrets, handler)
+			}
+			handler = aliases.wrap(handler)
+			if scrubber != nil {
+				handler = scrubber.wrap(toolName, handler)
+			}
+			if fields := derivedFieldsFor(derivedFields, toolName); len(fields) > 0 {
+				handler = wrapDerivedFields(fields, handler)
+			}
+			if output != nil {
+				handler = wrapStructuredOutput(handler)
+			}
+			if apiCfg.ContentHash {
+				handler = wrapContentHash(handler)
+			}
+			if variables != nil {
+				handler = variables.wrap(toolName, handler)
+			}
+			if history != nil {
+				handler = history.wrap(toolName, secretArguments, handler)
+			}
+			if requests != nil {
+				handler = requests.wrap(toolName, opCfg, handler)
+			}
+			if differ != nil {
+				handler = differ.wrap(toolName, method, handler)
+			}
+			if progress != nil {
+				handler = progress.wrap(handler)
+			}
+			if quota != nil {
+				handler = quota.wrap(method, handler)
+			}
+			if len(maintenance) > 0 {
+				handler = wrapMaintenance(maintenance, method, handler)
+			}
+			if apiCfg.MetricsAddr != "" {
+				handler = toolCallMetrics.wrap(toolName, handler)
+			}
+			if usage != nil {
+				handler = usage.wrap(toolName, handler)
+			}
+			handler = cancellable(handler)
+			tool := mcp.NewTool(toolName, toolOption...)
+			markSensitive(&tool, secretArguments)
+			if manifest != nil {
+				if err := manifest.check(tool); err != nil {
+					log.Printf("Refusing to register tool %s: %v", toolName, err)
+					continue
+				}
+			}
+			if isElevated(elevated, toolName) {
+				elevatedTools.add(mcpServer, server.ServerTool{Tool: tool, Handler: handler})
+				registered.noteElevated(toolName)
+			} else {
+				apiTools.add(mcpServer, server.ServerTool{Tool: tool, Handler: handler})
+				registered.noteTool(toolName)
+			}
+			toolCount++
+			noteLimitations(mcpServer, toolName, notes.notes)
+			if apiCfg.ExamplePrompts && len(presets) > 0 {
+				addExamplePrompt(mcpServer, newExamplePrompt(promptNames[toolName], toolName, details, presets, aliases, reqBody, rawBodyParam), registered)
+			}
+			if apiCfg.DocResources {
+				addOperationDocs(mcpServer, toolName, operationDocs(swaggerSpec, path, method, toolName, details))
+				registered.noteResource(operationDocsURI + toolName)
+			}
+			for _, tag := range details.Tags {
+				tagTools[tag] = append(tagTools[tag], toolName)
 			}
-			mcpServer.AddTool(
-				mcp.NewTool(toolName, toolOption...),
-				CreateMCPToolHandler(
-					reqPathParam, reqQueryParam, reqURL, reqBody, reqMethod, reqHeader, apiCfg,
-				),
-			)
 		}
 	}
+	if apiCfg.SchemaResources {
+		addSchemaResources(mcpServer, swaggerSpec, registered)
+	}
+	if apiCfg.SpecResources {
+		addSpecResources(mcpServer, swaggerSpec, registered)
+	}
+	if playbooks != nil {
+		addPlaybooks(mcpServer, playbooks, swaggerSpec.Tags, tagTools)
+	}
+	if usage != nil {
+		usage.noteSpec(swaggerSpec, toolCount)
+	}
+	return nil
 }
 
-func setRequestSecurity(req *http.Request, security string, basicAuth string, apiKeyAuth string, bearerAuth string) {
-	securityType := strings.TrimSpace(security)
+// operationSettings are the settings of one operation.
+type operationSettings struct {
+	apiCfg   models.ApiConfig
+	rewrites []urlRewrite
+}
+
+// operationSettingsFor applies the route of an operation and the security the
+// spec declares for it to apiCfg, and parses its URL rewrites.
+func operationSettingsFor(apiCfg models.ApiConfig, swaggerSpec models.SwaggerSpec, path, method string, details models.Endpoint) (operationSettings, error) {
+	opCfg := apiCfg
+	if route, routed := matchRoute(apiCfg.Routes, path, details.Tags); routed {
+		opCfg = applyRoute(apiCfg, route)
+	}
+	opCfg = applySpecSecurity(opCfg, swaggerSpec, details)
+	if err := checkSecurity(opCfg.Security); err != nil {
+		return operationSettings{}, fmt.Errorf("invalid security of %s %s: %v", strings.ToUpper(method), path, err)
+	}
+	rewrites, err := parseURLRewrites(opCfg.UrlRewrites)
+	if err != nil {
+		return operationSettings{}, fmt.Errorf("invalid URL rewrites of %s %s: %v", strings.ToUpper(method), path, err)
+	}
+	return operationSettings{apiCfg: opCfg, rewrites: rewrites}, nil
+}
 
+func setRequestSecurity(req *http.Request, security string, basicAuth string, apiKeyAuth string, bearerAuth string) {
 	// basic auth
-	if securityType == "basic" && basicAuth != "" {
+	if hasSecurity(security, "basic") && basicAuth != "" {
 		auth := base64.StdEncoding.EncodeToString([]byte(basicAuth))
 		req.Header.Set("Authorization", "Basic "+auth)
 	}
 
 	// bearer auth
-	if securityType == "bearer" && bearerAuth != "" {
+	if hasSecurity(security, "bearer") && bearerAuth != "" {
 		req.Header.Set("Authorization", "Bearer "+bearerAuth)
 	}
 
@@ -338,7 +1092,7 @@ func setRequestSecurity(req *http.Request, security string, basicAuth string, ap
 	// Example: header:token=abc,query:token=xyz,cookie:sid=ccc
 	queryValues := make(map[string]string)
 	cookieValues := []*http.Cookie{}
-	if securityType == "apiKey" && apiKeyAuth != "" {
+	if hasSecurity(security, "apiKey") && apiKeyAuth != "" {
 		for _, part := range strings.Split(apiKeyAuth, ",") {
 			part = strings.TrimSpace(part)
 			if part == "" {
@@ -382,49 +1136,361 @@ func setRequestSecurity(req *http.Request, security string, basicAuth string, ap
 	}
 }
 
-func CreateMCPToolHandler(
-	reqPathParam []string,
-	reqQueryParam []string,
-	reqURL string,
-	reqBody map[string]any,
-	reqMethod string,
-	reqHeader []string,
-	apiCfg models.ApiConfig,
-) server.ToolHandlerFunc {
+// describe returns the spec description, or fallback when there is none,
+// followed by the type, format, allowed values and example when known.
+func describe(fallback, description, schemaType, format string, enum []interface{}, example interface{}) string {
+	text := strings.TrimSpace(description)
+	if text == "" {
+		text = fallback
+	}
+	details := []string{}
+	if schemaType != "" {
+		details = append(details, "type: "+schemaType)
+	}
+	if format != "" {
+		details = append(details, "format: "+format)
+	}
+	if len(enum) > 0 {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = fmt.Sprint(v)
+		}
+		details = append(details, "one of: "+strings.Join(values, ", "))
+	}
+	if example != nil {
+		details = append(details, fmt.Sprintf("example: %v", example))
+	}
+	if len(details) == 0 {
+		return text
+	}
+	return fmt.Sprintf("%s (%s)", text, strings.Join(details, "; "))
+}
+
+func parameterDescription(param models.Parameter) string {
+	schemaType, format, enum, example := param.Type, param.Format, param.Enum, parameterExample(param)
+	description := param.Description
+	if param.Schema != nil {
+		// OpenAPI 3.0 keeps the parameter type in its schema
+		if schemaType == "" {
+			schemaType = param.Schema.Type
+		}
+		if format == "" {
+			format = param.Schema.Format
+		}
+		if len(enum) == 0 {
+			enum = param.Schema.Enum
+		}
+		if description == "" {
+			description = param.Schema.Description
+		}
+	}
+	return describe(fmt.Sprintf("The data for %s", param.Name), description, schemaType, format, enum, example)
+}
+
+func propertyDescription(propName string, prop models.Property) string {
+	text := schemaDescription(fmt.Sprintf("The data for %s, it should be in format of %s", propName, prop.Type), prop.Title, prop.Description, prop.Type, prop.Format, prop.Enum, prop.Example)
+	if prop.IsNullable() {
+		text += ". Pass null to set it to null"
+	}
+	return text
+}
+
+// schemaDescription describes a body property from its schema title and description.
+func schemaDescription(fallback, title, description, schemaType, format string, enum []interface{}, example interface{}) string {
+	title, description = strings.TrimSpace(title), strings.TrimSpace(description)
+	switch {
+	case title != "" && description != "":
+		description = fmt.Sprintf("%s: %s", title, description)
+	case title != "":
+		description = title
+	}
+	if description == "" {
+		// the fallback text already names the type
+		return describe(fallback, "", "", format, enum, example)
+	}
+	return describe(fallback, description, schemaType, format, enum, example)
+}
+
+// isPrimitiveType reports whether a schema type is sent as a raw value rather than a JSON object.
+func isPrimitiveType(schemaType string) bool {
+	switch schemaType {
+	case "string", "integer", "number", "boolean":
+		return true
+	}
+	return false
+}
+
+// rawBodyOption builds the tool argument carrying a primitive request body.
+func rawBodyOption(name, schemaType string, required bool) mcp.ToolOption {
+	propOptions := []mcp.PropertyOption{
+		mcp.Description(fmt.Sprintf("The raw request body, it should be in format of %s", schemaType)),
+	}
+	if required {
+		propOptions = append(propOptions, mcp.Required())
+	}
+	return mcp.WithString(name, propOptions...)
+}
+
+// nullableOption lets an argument also take JSON null.
+func nullableOption() mcp.PropertyOption {
+	return func(schema map[string]interface{}) {
+		schemaType, _ := schema["type"].(string)
+		if schemaType == "" {
+			schemaType = "string"
+		}
+		schema["type"] = []string{schemaType, "null"}
+	}
+}
+
+// charsetOf returns the charset parameter of a media type, or "" if there is none.
+func charsetOf(mediaType string) string {
+	_, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(params["charset"])
+}
+
+// declaredCharset returns the first charset found in the given media types.
+func declaredCharset(mediaTypes []string) string {
+	for _, mediaType := range mediaTypes {
+		if charset := charsetOf(mediaType); charset != "" {
+			return charset
+		}
+	}
+	return ""
+}
+
+// requestContentType returns the Content-Type for the request body, JSON or a
+// form, keeping the charset declared in the operation's consumes list.
+func requestContentType(consumes []string) string {
+	mediaType := "application/json"
+	if formEncoded(consumes) {
+		mediaType = formContentType
+	}
+	if charset := declaredCharset(consumes); charset != "" {
+		return mime.FormatMediaType(mediaType, map[string]string{"charset": charset})
+	}
+	return mediaType
+}
+
+// decodeResponseBody transcodes the response body to UTF-8 using the charset from
+// the Content-Type header, falling back to the charset declared in produces.
+func decodeResponseBody(body []byte, contentType string, produces []string) (string, error) {
+	charset := charsetOf(contentType)
+	if charset == "" {
+		charset = declaredCharset(produces)
+	}
+	if charset == "" || strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "utf8") {
+		return string(body), nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return "", fmt.Errorf("unsupported charset %s: %v", charset, err)
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s response: %v", charset, err)
+	}
+	return string(decoded), nil
+}
+
+// marshalOrdered marshals data as a JSON object whose keys follow order. Keys
+// missing from order are appended in sorted order.
+func marshalOrdered(data map[string]interface{}, order []string) ([]byte, error) {
+	keys := make([]string, 0, len(data))
+	seen := map[string]bool{}
+	for _, key := range order {
+		if _, ok := data[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	rest := []string{}
+	for key := range data {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyData, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueData, err := json.Marshal(data[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyData)
+		buf.WriteByte(':')
+		buf.Write(valueData)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toolHandlerConfig describes the HTTP request a tool handler makes and the
+// features applied to the call and its response.
+type toolHandlerConfig struct {
+	reqPathParam       []string
+	reqQueryParam      []string
+	reqParamSpecs      map[string]models.Parameter
+	reqURL             string
+	reqBody            map[string]any // property name => type of the body fields
+	reqBodyOrder       []string
+	reqBodyOptional    map[string]bool
+	reqBodyNullable    map[string]bool
+	reqBodyEnums       map[string][]interface{}
+	reqBodyDefaults    map[string]interface{}
+	rawBodyParam       string // the argument carrying a raw or whole body, if any
+	rawBodyContentType string
+	rawBodyRequired    bool // an omitted optional raw or whole body sends no body
+	reqMethod          string
+	reqHeader          []string
+	reqHeaderSpecs     map[string]models.Parameter
+	reqCookie          []string
+	consumes           []string
+	produces           []string
+	csrf               *csrfManager
+	csrfURL            string
+	createdGetTool     string
+	declaredSuccess    map[int]string
+	cache              *responseCache
+	downloads          *downloadRoots
+	toolName           string
+	headerCaptures     []headerCapture
+	presets            map[string]bodyPreset
+	validator          *bodySchema
+	variants           *bodyVariants
+	checkExists        bool
+	rewrites           []urlRewrite
+	failover           *failoverGroup
+	specVersion        string
+	bodyLimit          int64
+	aliases            *argumentAliases
+	apiCfg             models.ApiConfig
+}
+
+// createToolHandler returns the handler of a tool calling the operation cfg describes.
+func createToolHandler(cfg toolHandlerConfig) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		currentReqURL := reqURL
-		for _, paramName := range reqPathParam {
-			param, ok := request.Params.Arguments[paramName].(string)
+		currentReqURL := cfg.reqURL
+		// the servers to try, the one known to be up first
+		var bases []string
+		if cfg.failover != nil {
+			bases = cfg.failover.order()
+			currentReqURL, _ = rebase(cfg.reqURL, cfg.failover.bases[0], bases[0])
+		}
+		for _, paramName := range cfg.reqPathParam {
+			value, ok := argumentOrDefault(request.GetArguments(), cfg.reqParamSpecs[paramName])
 			if !ok {
 				return mcp.NewToolResultError(fmt.Sprintf("[Error] missing or invalid Path Parameter: %s", paramName)), nil
 			}
-			currentReqURL = strings.Replace(currentReqURL, fmt.Sprintf("{%s}", paramName), param, 1)
+			values, err := argumentValues(value, cfg.reqParamSpecs[paramName])
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Path Parameter %s: %v", paramName, err)), nil
+			}
+			param := strings.Join(values, ",")
+			currentReqURL = strings.Replace(currentReqURL, fmt.Sprintf("{%s}", paramName), expandPathParam(cfg.reqParamSpecs[paramName], param), 1)
+		}
+		if placeholder := pathPlaceholder.FindString(currentReqURL); placeholder != "" {
+			// never send a literal placeholder to the backend
+			return mcp.NewToolResultError(fmt.Sprintf("[Error] the request URL still has the %s placeholder, no argument fills it", placeholder)), nil
 		}
 
 		// query param
-		if len(reqQueryParam) > 0 {
+		if len(cfg.reqQueryParam) > 0 {
 			u, err := url.Parse(currentReqURL)
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to parse URL: %v", err)), nil
 			}
 			q := u.Query()
-			for _, name := range reqQueryParam {
-				val, ok := request.Params.Arguments[name].(string)
+			for _, name := range cfg.reqQueryParam {
+				value, ok := argumentOrDefault(request.GetArguments(), cfg.reqParamSpecs[name])
 				if !ok {
+					if !cfg.reqParamSpecs[name].Required {
+						continue
+					}
 					return mcp.NewToolResultError(fmt.Sprintf("[Error] missing or invalid Query Parameter: %s", name)), nil
 				}
-				q.Set(name, val)
+				if paramType(cfg.reqParamSpecs[name]) == "object" {
+					values, err := objectQueryValues(value, cfg.reqParamSpecs[name], cfg.reqQueryParam)
+					if err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Query Parameter %s: %v", name, err)), nil
+					}
+					// added, an exploded property never replaces another query value
+					for key, keyValues := range values {
+						for _, keyValue := range keyValues {
+							q.Add(key, keyValue)
+						}
+					}
+					continue
+				}
+				values, err := argumentValues(value, cfg.reqParamSpecs[name])
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Query Parameter %s: %v", name, err)), nil
+				}
+				q[name] = values
 			}
-			u.RawQuery = q.Encode()
+			u.RawQuery = encodeQuery(q, cfg.reqParamSpecs)
 			currentReqURL = u.String()
 		}
 
+		var preset map[string]interface{}
+		if name, _ := request.GetArguments()[exampleArgument].(string); name != "" {
+			selected, ok := cfg.presets[name]
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] unknown example: %s", name)), nil
+			}
+			preset = selected.Body
+		}
+
 		reqBodyData := make(map[string]interface{})
-		for paramName, paramType := range reqBody {
-			paramStr, exists := request.Params.Arguments[paramName].(string)
+		for paramName, value := range preset {
+			if _, declared := cfg.reqBody[paramName]; !declared {
+				reqBodyData[paramName] = value
+			}
+		}
+		for paramName, paramType := range cfg.reqBody {
+			if arg, present := request.GetArguments()[paramName]; present && cfg.reqBodyNullable[paramName] && (arg == nil || arg == "null") {
+				// explicit null, as opposed to leaving the field out
+				reqBodyData[paramName] = nil
+				continue
+			}
+			if value, ok := structuredArgument(request.GetArguments()[paramName], fmt.Sprint(paramType)); ok {
+				reqBodyData[paramName] = value
+				continue
+			}
+			arg := request.GetArguments()[paramName]
+			exists := arg != nil
+			paramStr, isString := arg.(string)
+			if value, ok := preset[paramName]; ok && !exists {
+				reqBodyData[paramName] = value
+				continue
+			}
+			if value, ok := cfg.reqBodyDefaults[paramName]; ok && !exists {
+				reqBodyData[paramName] = value
+				continue
+			}
+			if cfg.reqBodyOptional[paramName] && (!exists || cfg.apiCfg.OmitEmptyBody && (paramStr == "" || paramStr == "null")) {
+				// fields the schema does not require are left out rather than sent empty
+				continue
+			}
 			if !exists {
 				return mcp.NewToolResultError(fmt.Sprintf("[Error] missing Body Parameter: %s", paramName)), nil
 			}
+			if !isString {
+				// native JSON numbers and booleans, parsed below like their text form
+				paramStr, _ = argumentString(arg, "string")
+			}
 
 			switch paramType {
 			case "string":
@@ -437,7 +1503,7 @@ func CreateMCPToolHandler(
 				}
 				reqBodyData[paramName] = intValue
 
-			case "float":
+			case "float", "number":
 				floatValue, err := strconv.ParseFloat(paramStr, 64)
 				if err != nil {
 					return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid type for parameter %s, expected float", paramName)), nil
@@ -468,45 +1534,183 @@ func CreateMCPToolHandler(
 			default:
 				return mcp.NewToolResultError(fmt.Sprintf("[Error] unsupported parameter type: %s for %s", paramType, paramName)), nil
 			}
+			if err := checkEnum(fmt.Sprint(reqBodyData[paramName]), cfg.reqBodyEnums[paramName]); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Body Parameter %s: %v", paramName, err)), nil
+			}
+		}
+		if cfg.variants != nil && cfg.rawBodyParam == "" {
+			if err := cfg.variants.apply(request.GetArguments(), reqBodyData); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+			}
+		}
+		if cfg.validator != nil && cfg.rawBodyParam == "" {
+			if problems := cfg.validator.validate(reqBodyData); len(problems) > 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid request body, fix these fields and call the tool again:\n- %s", strings.Join(problems, "\n- "))), nil
+			}
+		}
+		rawValue := ""
+		noBody := false
+		bodyFile := ""
+		if cfg.downloads != nil && cfg.rawBodyParam != "" {
+			bodyFile, _ = request.GetArguments()[bodyFileArgument].(string)
+		}
+		if cfg.rawBodyParam != "" && bodyFile == "" {
+			switch value := request.GetArguments()[cfg.rawBodyParam].(type) {
+			case string:
+				rawValue = value
+			case nil:
+				if cfg.rawBodyRequired {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] missing Body Parameter: %s", cfg.rawBodyParam)), nil
+				}
+				noBody = true
+			default:
+				// a whole body passed as structured JSON
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Body Parameter %s: %v", cfg.rawBodyParam, err)), nil
+				}
+				rawValue = string(encoded)
+			}
+		}
+		encodeBody := func() ([]byte, error) {
+			if cfg.rawBodyParam != "" {
+				return []byte(rawValue), nil
+			}
+			if formEncoded(cfg.consumes) {
+				form, err := encodeForm(reqBodyData, cfg.reqBodyOrder, cfg.reqParamSpecs)
+				return []byte(form), err
+			}
+			if cfg.apiCfg.OrderedBody {
+				return marshalOrdered(reqBodyData, cfg.reqBodyOrder)
+			}
+			return json.Marshal(reqBodyData)
+		}
 
+		// the deployed routes may differ from the paths of the spec
+		currentReqURL = rewriteURL(cfg.rewrites, currentReqURL)
+
+		// anti-CSRF token for mutating calls
+		csrfToken := ""
+		if cfg.csrf != nil && isMutatingMethod(cfg.reqMethod) {
+			u, err := url.Parse(currentReqURL)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to parse URL: %v", err)), nil
+			}
+			setProgressState(ctx, "fetching the CSRF token")
+			csrfToken, err = cfg.csrf.token(ctx, cfg.apiCfg, cfg.csrfURL, u, false)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to get CSRF token: %v", err)), nil
+			}
+			if cfg.apiCfg.CsrfBodyField != "" && cfg.rawBodyParam == "" {
+				reqBodyData[cfg.apiCfg.CsrfBodyField] = csrfToken
+			}
 		}
-		reqBodyDataBytes, err := json.Marshal(reqBodyData)
+
+		reqBodyDataBytes, err := encodeBody()
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to marshal request body: %v", err)), nil
 		}
+		if bodyFile == "" {
+			if err := checkBodySize(int64(len(reqBodyDataBytes)), cfg.bodyLimit); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+			}
+		}
+
+		serviceURL := currentReqURL
+		currentReqURL, service, err := services.resolve(ctx, serviceURL)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+		}
 
-		fmt.Printf("Request  : %s %s\n", strings.ToUpper(reqMethod), currentReqURL)
-		req, err := http.NewRequest(strings.ToUpper(reqMethod), currentReqURL, bytes.NewBuffer(reqBodyDataBytes))
+		fmt.Printf("Request  : %s %s\n", strings.ToUpper(cfg.reqMethod), redactLog(ctx, currentReqURL))
+		timeout := time.Duration(cfg.apiCfg.RequestTimeout) * time.Second
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		var reqBodyReader io.Reader = bytes.NewBuffer(reqBodyDataBytes)
+		var upload *uploadBody
+		if bodyFile != "" {
+			// streamed from disk, large uploads are never held in memory
+			if upload, err = cfg.downloads.openUpload(ctx, bodyFile); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to open %s: %v", bodyFile, err)), nil
+			}
+			if err := checkBodySize(upload.size, cfg.bodyLimit); err != nil {
+				upload.Close()
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %s: %v", bodyFile, err)), nil
+			}
+			reqBodyReader = upload
+		}
+		req, err := http.NewRequestWithContext(ctx, strings.ToUpper(cfg.reqMethod), currentReqURL, reqBodyReader)
 		if err != nil {
+			if upload != nil {
+				upload.Close()
+			}
 			return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to create HTTP request: %v", err)), nil
 		}
+		if upload != nil {
+			req.ContentLength = upload.size
+			req.GetBody = cfg.downloads.getBody(ctx, bodyFile)
+		}
 
-		for _, headerName := range reqHeader {
-			headerValue, ok := request.Params.Arguments[headerName].(string)
+		// headers set through arguments win over the configured and default ones
+		argumentHeaders := map[string]bool{}
+		for _, headerName := range cfg.reqHeader {
+			value, ok := argumentOrDefault(request.GetArguments(), cfg.reqHeaderSpecs[headerName])
 			if !ok {
+				if !cfg.reqHeaderSpecs[headerName].Required {
+					continue
+				}
 				return mcp.NewToolResultError(fmt.Sprintf("[Error] missing or invalid Header: %s", headerName)), nil
 			}
-			req.Header.Add(headerName, headerValue)
+			values, err := argumentValues(value, cfg.reqHeaderSpecs[headerName])
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Header %s: %v", headerName, err)), nil
+			}
+			req.Header.Set(headerName, strings.Join(values, ","))
+			argumentHeaders[http.CanonicalHeaderKey(headerName)] = true
+		}
+		for _, cookieName := range cfg.reqCookie {
+			value, ok := argumentOrDefault(request.GetArguments(), cfg.reqParamSpecs[cookieName])
+			if !ok {
+				if !cfg.reqParamSpecs[cookieName].Required {
+					continue
+				}
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] missing or invalid Cookie: %s", cookieName)), nil
+			}
+			values, err := argumentValues(value, cfg.reqParamSpecs[cookieName])
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] invalid Cookie %s: %v", cookieName, err)), nil
+			}
+			addCookie(req, cookieName, strings.Join(values, ","))
 		}
-		req.Header.Set("Content-Type", "application/json")
-
 		// request security
-		setRequestSecurity(req, apiCfg.Security, apiCfg.BasicAuth, apiCfg.ApiKeyAuth, apiCfg.BearerAuth)
+		setRequestSecurity(req, cfg.apiCfg.Security, cfg.apiCfg.BasicAuth, cfg.apiCfg.ApiKeyAuth, cfg.apiCfg.BearerAuth)
 
 		// set custom headers from ApiConfig.Headers (format: name1=value1,name2=value2)
-		if apiCfg.Headers != "" {
-			for _, pair := range strings.Split(apiCfg.Headers, ",") {
+		if cfg.apiCfg.Headers != "" {
+			for _, pair := range strings.Split(cfg.apiCfg.Headers, ",") {
 				if pair = strings.TrimSpace(pair); pair == "" {
 					continue
 				}
 				if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
-					if key := strings.TrimSpace(kv[0]); key != "" {
+					if key := strings.TrimSpace(kv[0]); key != "" && !argumentHeaders[http.CanonicalHeaderKey(key)] {
 						req.Header.Add(key, strings.TrimSpace(kv[1]))
 					}
 				}
 			}
 		}
 
+		// the content type of the body unless given
+		if req.Header.Get("Content-Type") == "" && !noBody {
+			if cfg.rawBodyParam != "" {
+				req.Header.Set("Content-Type", cfg.rawBodyContentType)
+			} else {
+				req.Header.Set("Content-Type", requestContentType(cfg.consumes))
+			}
+		}
+
 		// headers from sse
 		sseHeadersValue := ctx.Value(sseHeadersKey)
 		if sseHeadersValue != nil {
@@ -517,19 +1721,197 @@ func CreateMCPToolHandler(
 			}
 		}
 
-		client := &http.Client{}
+		if csrfToken != "" {
+			req.Header.Set(csrfHeaderName(cfg.apiCfg), csrfToken)
+		}
+
+		cacheable := cfg.cache != nil && strings.EqualFold(cfg.reqMethod, http.MethodGet)
+		cacheID := ""
+		if cacheable {
+			cacheID = cfg.cache.prepare(req)
+		}
+
+		httpClient := &http.Client{}
+		if cfg.csrf != nil {
+			httpClient = cfg.csrf.client(ctx)
+		}
+		client, err := newAuthClient(httpClient, cfg.apiCfg)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to set up authentication: %v", err)), nil
+		}
+		noteUpstream(ctx, req.URL.Host)
+		if cfg.checkExists {
+			if result := checkResourceExists(ctx, client, req); result != nil {
+				req.Body.Close()
+				return result, nil
+			}
+		}
+		setProgressState(ctx, fmt.Sprintf("waiting for the response to %s %s", req.Method, req.URL.Path))
+		noteRequest(ctx, req, uploadlessBody(upload, reqBodyDataBytes))
 		resp, err := client.Do(req)
+		if err != nil && service != "" && isConnectError(err) {
+			// the service may have moved, look it up again and retry once
+			services.forget(service)
+			if retryURL, _, resolveErr := services.resolve(ctx, serviceURL); resolveErr == nil {
+				if u, parseErr := url.Parse(retryURL); parseErr == nil {
+					req = req.Clone(ctx)
+					req.URL, req.Host = u, u.Host
+					currentReqURL = retryURL
+					noteUpstream(ctx, u.Host)
+					setProgressState(ctx, fmt.Sprintf("retrying at the new address of %s", service))
+					if req.Body, err = req.GetBody(); err == nil {
+						noteRequest(ctx, req, uploadlessBody(upload, reqBodyDataBytes))
+						resp, err = client.Do(req)
+					}
+				}
+			}
+		}
+		// the server is unreachable, try the next one
+		for i := 1; err != nil && i < len(bases) && isConnectError(err); i++ {
+			cfg.failover.down(bases[i-1], err)
+			nextURL, ok := rebase(currentReqURL, bases[i-1], bases[i])
+			u, parseErr := url.Parse(nextURL)
+			if !ok || parseErr != nil {
+				break
+			}
+			req = req.Clone(ctx)
+			req.URL, req.Host = u, u.Host
+			currentReqURL = nextURL
+			if req.GetBody != nil {
+				if req.Body, err = req.GetBody(); err != nil {
+					break
+				}
+			}
+			noteUpstream(ctx, u.Host)
+			setProgressState(ctx, fmt.Sprintf("failing over to %s", bases[i]))
+			noteRequest(ctx, req, uploadlessBody(upload, reqBodyDataBytes))
+			if resp, err = client.Do(req); err == nil {
+				cfg.failover.up(bases[i])
+			}
+		}
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to make HTTP request: %v", err)), nil
+			return mcp.NewToolResultError(requestErrorResult(err)), nil
+		}
+
+		// the token may have expired, fetch a new one and retry once
+		if resp.StatusCode == http.StatusForbidden && csrfToken != "" {
+			resp.Body.Close()
+			csrfToken, err = cfg.csrf.token(ctx, cfg.apiCfg, cfg.csrfURL, req.URL, true)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to refresh CSRF token: %v", err)), nil
+			}
+			if cfg.apiCfg.CsrfBodyField != "" && cfg.rawBodyParam == "" {
+				reqBodyData[cfg.apiCfg.CsrfBodyField] = csrfToken
+			}
+			if reqBodyDataBytes, err = encodeBody(); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to marshal request body: %v", err)), nil
+			}
+			retry := req.Clone(ctx)
+			if upload != nil {
+				if retry.Body, err = retry.GetBody(); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to open %s: %v", bodyFile, err)), nil
+				}
+			} else {
+				retry.Body = io.NopCloser(bytes.NewReader(reqBodyDataBytes))
+				retry.ContentLength = int64(len(reqBodyDataBytes))
+			}
+			retry.Header.Set(csrfHeaderName(cfg.apiCfg), csrfToken)
+			setProgressState(ctx, "retrying with a refreshed CSRF token")
+			noteRequest(ctx, retry, uploadlessBody(upload, reqBodyDataBytes))
+			resp, err = client.Do(retry)
+			if err != nil {
+				return mcp.NewToolResultError(requestErrorResult(err)), nil
+			}
 		}
 
 		defer resp.Body.Close()
 
+		setProgressState(ctx, fmt.Sprintf("reading the HTTP %d response", resp.StatusCode))
 		body, err := io.ReadAll(resp.Body)
-		if err != nil {
+		var partial *partialResponse
+		if err != nil && timeout > 0 && len(body) > 0 && ctx.Err() == context.DeadlineExceeded {
+			// a slow list is more useful cut short than not at all
+			received := len(body)
+			salvaged, items := salvagePartialBody(body)
+			body = salvaged
+			marker := partialResult(received, items, timeout)
+			partial = &marker
+			fmt.Printf("Response timed out after %d bytes, returning the partial body\n", received)
+		} else if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to read HTTP Response: %v", err)), nil
 		}
-		fmt.Printf("Response : %s\n", string(body))
-		return mcp.NewToolResultText(string(body)), nil
+		cacheHit := cacheable && resp.StatusCode == http.StatusNotModified
+		if cacheable && partial == nil {
+			body = cfg.cache.update(cacheID, resp, body)
+		}
+		if status, ok := ctx.Value(responseStatusKey).(*int); ok {
+			*status = resp.StatusCode
+		}
+		noteUpstreamStatus(ctx, resp.StatusCode)
+		noteResponse(ctx, resp, body)
+
+		// binary or large results go to a file in the download roots
+		if cfg.downloads != nil && partial == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			contentType := resp.Header.Get("Content-Type")
+			saveTo, _ := request.GetArguments()[saveToArgument].(string)
+			if saveTo != "" || cfg.downloads.shouldSave(contentType, body) {
+				saved, err := cfg.downloads.save(saveTo, cfg.toolName, contentType, body)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to save response: %v", err)), nil
+				}
+				savedData, _ := json.Marshal(map[string]interface{}{"download": saved})
+				result := mcp.NewToolResultText(fmt.Sprintf("Saved the %d byte response to %s", saved.Bytes, saved.Path))
+				result.Content = append(result.Content, mcp.NewTextContent(string(savedData)))
+				if cfg.apiCfg.Provenance {
+					addProvenance(ctx, result, currentReqURL, req.Method, resp.StatusCode, cacheHit, cfg.specVersion)
+				}
+				return result, nil
+			}
+		}
+
+		text, err := decodeResponseBody(body, resp.Header.Get("Content-Type"), cfg.produces)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("[Error] failed to decode HTTP Response: %v", err)), nil
+		}
+		if page, ok := htmlErrorPage(resp.StatusCode, resp.Header.Get("Content-Type"), text, cfg.produces); ok {
+			fmt.Printf("Response : %s\n", redactLog(ctx, page))
+			result := mcp.NewToolResultError(fmt.Sprintf("[Error] %s", page))
+			if cfg.apiCfg.Provenance {
+				addProvenance(ctx, result, currentReqURL, req.Method, resp.StatusCode, cacheHit, cfg.specVersion)
+			}
+			return result, nil
+		}
+		fmt.Printf("Response : %s\n", redactLog(ctx, text))
+		outcome := detectOutcome(resp.StatusCode, body, cfg.declaredSuccess)
+		if outcome != nil && outcome.EmptyBody {
+			text = fmt.Sprintf("Success, the API returned HTTP %d with an empty body", resp.StatusCode)
+		}
+		result := mcp.NewToolResultText(text)
+		if partial != nil {
+			partialData, _ := json.Marshal(partial)
+			result.Content = append(result.Content, mcp.NewTextContent(string(partialData)))
+		}
+		if outcome != nil {
+			outcomeData, _ := json.Marshal(map[string]interface{}{"response": outcome})
+			result.Content = append(result.Content, mcp.NewTextContent(string(outcomeData)))
+		}
+		if headers := capturedHeaders(resp.Header, cfg.headerCaptures); len(headers) > 0 {
+			headersData, _ := json.Marshal(map[string]interface{}{"response_headers": headers})
+			result.Content = append(result.Content, mcp.NewTextContent(string(headersData)))
+		}
+		if pagination := detectPagination(resp.Header, body, cfg.reqQueryParam, cfg.aliases); pagination != nil {
+			paginationData, _ := json.Marshal(map[string]interface{}{"pagination": pagination})
+			result.Content = append(result.Content, mcp.NewTextContent(string(paginationData)))
+		}
+		if strings.EqualFold(cfg.reqMethod, http.MethodPost) {
+			if created := detectCreatedResource(currentReqURL, resp.StatusCode, resp.Header, body, cfg.createdGetTool); created != nil {
+				createdData, _ := json.Marshal(map[string]interface{}{"created_resource": created})
+				result.Content = append(result.Content, mcp.NewTextContent(string(createdData)))
+			}
+		}
+		if cfg.apiCfg.Provenance {
+			addProvenance(ctx, result, currentReqURL, req.Method, resp.StatusCode, cacheHit, cfg.specVersion)
+		}
+		return result, nil
 	}
 }
@@ -0,0 +1,228 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/hrouis/swagger-mcp/app/swagger"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// listedTool loads spec on a new server and returns its only tool.
+func listedTool(t *testing.T, document string, apiCfg models.ApiConfig) mcp.Tool {
+	t.Helper()
+	spec, err := swagger.ParseSwagger([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	LoadSwaggerServer(mcpServer, spec, apiCfg)
+	response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	result := response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+	if len(result.Tools) != 1 {
+		t.Fatalf("expected one tool, got %d", len(result.Tools))
+	}
+	return result.Tools[0]
+}
+
+// calledTool loads spec on a new server, calls its tool named name with
+// arguments and returns the result.
+func calledTool(t *testing.T, document string, apiCfg models.ApiConfig, name, arguments string) mcp.CallToolResult {
+	t.Helper()
+	spec, err := swagger.ParseSwagger([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	LoadSwaggerServer(mcpServer, spec, apiCfg)
+	params, _ := json.Marshal(map[string]interface{}{"name": name, "arguments": json.RawMessage(arguments)})
+	response := mcpServer.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+string(params)+`}`))
+	result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("expected a tool result, got %#v", response)
+	}
+	return result
+}
+
+func TestBodyArgumentsMatchAcrossSpecVersions(t *testing.T) {
+	apiCfg := models.ApiConfig{BaseUrl: "https://api.example.com", ParamAliases: "pet-name=petName"}
+	swagger2 := listedTool(t, `{
+		"swagger": "2.0",
+		"paths": {"/pets": {"post": {"operationId": "addPet", "parameters": [{"name": "pet", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Pet"}}], "responses": {"201": {"description": "created"}}}}},
+		"definitions": {"Pet": {"type": "object", "required": ["pet-name"], "properties": {
+			"pet-name": {"type": "string"},
+			"tag": {"type": "string", "enum": ["cat", "dog"]},
+			"age": {"type": "integer", "default": 1}
+		}}}
+	}`, apiCfg)
+	openapi3 := listedTool(t, `{
+		"openapi": "3.0.0",
+		"paths": {"/pets": {"post": {"operationId": "addPet", "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}, "responses": {"201": {"description": "created"}}}}},
+		"components": {"schemas": {"Pet": {"type": "object", "required": ["pet-name"], "properties": {
+			"pet-name": {"type": "string"},
+			"tag": {"type": "string", "enum": ["cat", "dog"]},
+			"age": {"type": "integer", "default": 1}
+		}}}}
+	}`, apiCfg)
+
+	if !reflect.DeepEqual(swagger2.InputSchema.Properties, openapi3.InputSchema.Properties) {
+		t.Errorf("the arguments differ:\nswagger 2.0: %v\nopenapi 3.0: %v", swagger2.InputSchema.Properties, openapi3.InputSchema.Properties)
+	}
+	for _, tool := range []mcp.Tool{swagger2, openapi3} {
+		if _, ok := tool.InputSchema.Properties["petName"]; !ok {
+			t.Errorf("the aliased argument is missing: %v", tool.InputSchema.Properties)
+		}
+		if !slices.Equal(tool.InputSchema.Required, []string{"petName"}) {
+			t.Errorf("required = %v, want [petName]", tool.InputSchema.Required)
+		}
+	}
+}
+
+func TestMarshalOrdered(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  map[string]interface{}
+		order []string
+		want  string
+	}{
+		{"schema order", map[string]interface{}{"b": 1, "a": "x", "c": true}, []string{"c", "a", "b"}, `{"c":true,"a":"x","b":1}`},
+		{"unordered keys sorted last", map[string]interface{}{"z": 1, "y": 2, "a": 3}, []string{"z"}, `{"z":1,"a":3,"y":2}`},
+		{"ordered keys missing", map[string]interface{}{"a": 1}, []string{"b", "a", "c"}, `{"a":1}`},
+		{"duplicate order", map[string]interface{}{"a": 1, "b": 2}, []string{"b", "b", "a"}, `{"b":2,"a":1}`},
+		{"nested values", map[string]interface{}{"a": map[string]interface{}{"y": 1, "x": 2}, "b": []interface{}{1, "s"}}, nil, `{"a":{"x":2,"y":1},"b":[1,"s"]}`},
+		{"empty", map[string]interface{}{}, []string{"a"}, `{}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := marshalOrdered(test.data, test.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.want {
+				t.Errorf("marshalOrdered = %s, want %s", data, test.want)
+			}
+		})
+	}
+}
+
+func TestOptionalRawBodyMayBeOmitted(t *testing.T) {
+	type sent struct {
+		body        string
+		contentType string
+	}
+	requests := make(chan sent, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- sent{string(body), r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+	apiCfg := models.ApiConfig{BaseUrl: backend.URL}
+
+	tests := []struct {
+		name      string
+		document  string
+		arguments string
+		want      sent
+		wantError bool
+	}{
+		{
+			name:      "optional text body omitted",
+			document:  `{"openapi": "3.0.0", "paths": {"/note": {"post": {"requestBody": {"required": false, "content": {"text/plain": {"schema": {"type": "string"}}}}, "responses": {"204": {"description": "saved"}}}}}}`,
+			arguments: `{}`,
+		},
+		{
+			name:      "optional text body given",
+			document:  `{"openapi": "3.0.0", "paths": {"/note": {"post": {"requestBody": {"content": {"text/plain": {"schema": {"type": "string"}}}}, "responses": {"204": {"description": "saved"}}}}}}`,
+			arguments: `{"body": "hello"}`,
+			want:      sent{"hello", "text/plain"},
+		},
+		{
+			name:      "required text body omitted",
+			document:  `{"openapi": "3.0.0", "paths": {"/note": {"post": {"requestBody": {"required": true, "content": {"text/plain": {"schema": {"type": "string"}}}}, "responses": {"204": {"description": "saved"}}}}}}`,
+			arguments: `{}`,
+			wantError: true,
+		},
+		{
+			name:      "optional primitive body parameter omitted",
+			document:  `{"swagger": "2.0", "paths": {"/note": {"post": {"consumes": ["text/plain"], "parameters": [{"name": "note", "in": "body", "schema": {"type": "string"}}], "responses": {"204": {"description": "saved"}}}}}}`,
+			arguments: `{}`,
+		},
+		{
+			name:      "optional whole body omitted",
+			document:  `{"openapi": "3.0.0", "paths": {"/note": {"post": {"requestBody": {"content": {"application/json": {"schema": {"type": "array", "items": {"type": "string"}}}}}, "responses": {"204": {"description": "saved"}}}}}}`,
+			arguments: `{}`,
+		},
+		{
+			name:      "required whole body omitted",
+			document:  `{"openapi": "3.0.0", "paths": {"/note": {"post": {"requestBody": {"required": true, "content": {"application/json": {"schema": {"type": "array", "items": {"type": "string"}}}}}, "responses": {"204": {"description": "saved"}}}}}}`,
+			arguments: `{}`,
+			wantError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := calledTool(t, test.document, apiCfg, "post__note", test.arguments)
+			if test.wantError {
+				if !result.IsError {
+					t.Fatalf("expected an error, got %#v", result.Content)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error: %#v", result.Content)
+			}
+			if got := <-requests; got != test.want {
+				t.Errorf("sent %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOptionalQueryParametersMayBeOmitted(t *testing.T) {
+	queries := make(chan string, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.RawQuery
+		w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+	document := `{"swagger": "2.0", "paths": {"/users": {"get": {"parameters": [
+		{"name": "team", "in": "query", "required": true, "type": "string"},
+		{"name": "q", "in": "query", "type": "string"},
+		{"name": "limit", "in": "query", "type": "integer", "default": 20},
+		{"name": "filter", "in": "query", "type": "object", "style": "form", "explode": true, "properties": {"role": {"type": "string"}}}
+	], "responses": {"200": {"description": "ok"}}}}}}`
+
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+		wantError bool
+	}{
+		{"optional omitted", `{"team": "core"}`, "limit=20&team=core", false},
+		{"optional given", `{"team": "core", "q": "ann", "limit": 5, "filter": {"role": "admin"}}`, "limit=5&q=ann&role=admin&team=core", false},
+		{"required omitted", `{"q": "ann"}`, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := calledTool(t, document, models.ApiConfig{BaseUrl: backend.URL}, "get__users", test.arguments)
+			if result.IsError != test.wantError {
+				t.Fatalf("error = %v, want %v: %#v", result.IsError, test.wantError, result.Content)
+			}
+			if test.wantError {
+				return
+			}
+			if got := <-queries; got != test.want {
+				t.Errorf("query = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package mcpserver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessionCleanups drops the per-session state of the stores of a server when
+// one of its sessions ends, so long-running SSE servers do not keep it forever.
+type sessionCleanups struct {
+	mu       sync.Mutex
+	cleanups map[*server.MCPServer]map[string]func(session string)
+}
+
+var sessionEnds = &sessionCleanups{cleanups: map[*server.MCPServer]map[string]func(session string){}}
+
+// add runs cleanup for every session of mcpServer that ends. A store loaded
+// again for the same server replaces the cleanup of its previous instance.
+func (c *sessionCleanups) add(mcpServer *server.MCPServer, store string, cleanup func(session string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cleanups[mcpServer] == nil {
+		c.cleanups[mcpServer] = map[string]func(session string){}
+	}
+	c.cleanups[mcpServer][store] = cleanup
+}
+
+func (c *sessionCleanups) end(mcpServer *server.MCPServer, session string) {
+	c.mu.Lock()
+	cleanups := make([]func(session string), 0, len(c.cleanups[mcpServer]))
+	for _, cleanup := range c.cleanups[mcpServer] {
+		cleanups = append(cleanups, cleanup)
+	}
+	c.mu.Unlock()
+	for _, cleanup := range cleanups {
+		cleanup(session)
+	}
+}
+
+// addSessionHooks runs the cleanups of mcpServer when one of its sessions unregisters.
+func (c *sessionCleanups) addSessionHooks(hooks *server.Hooks, mcpServer *server.MCPServer) {
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		c.end(mcpServer, session.SessionID())
+	})
+}
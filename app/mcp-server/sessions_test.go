@@ -0,0 +1,70 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// testSession is a client session that drops its notifications.
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newTestSession(id string) *testSession {
+	return &testSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 16)}
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return s.id }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func TestVariablesAreDroppedWhenTheSessionEnds(t *testing.T) {
+	mcpServer := newMCPServer("test")
+	variables := newVariableStore(nil)
+	sessionEnds.add(mcpServer, "variables", variables.forget)
+
+	session := newTestSession("s1")
+	if err := mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+	variables.set("s1", "id", "42")
+	variables.set("s2", "id", "7")
+	mcpServer.UnregisterSession(context.Background(), "s1")
+
+	if vars := variables.all("s1"); len(vars) != 0 {
+		t.Errorf("the variables of the ended session are kept: %v", vars)
+	}
+	if vars := variables.all("s2"); vars["id"] != "7" {
+		t.Errorf("the variables of another session are dropped: %v", vars)
+	}
+	if _, ok := variables.sessions["s1"]; ok {
+		t.Error("the ended session still has an entry")
+	}
+}
+
+func TestQuotaCountersAreDroppedWhenTheSessionEnds(t *testing.T) {
+	mcpServer := newMCPServer("test")
+	quota := newQuotaTracker(1, 0)
+	sessionEnds.add(mcpServer, "quota", quota.forget)
+
+	if err := mcpServer.RegisterSession(context.Background(), newTestSession("s1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := quota.take("s1", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := quota.take("s1", false); err == nil {
+		t.Fatal("expected the budget to be exhausted")
+	}
+	mcpServer.UnregisterSession(context.Background(), "s1")
+
+	if _, ok := quota.sessions["s1"]; ok {
+		t.Error("the ended session still has counters")
+	}
+}
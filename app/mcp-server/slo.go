@@ -0,0 +1,122 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	latencyWindow     = 50 // latest calls the p95 is computed over
+	latencyMinSamples = 20 // calls needed before a tool is judged against its SLO
+)
+
+// toolLatency keeps the durations of the latest calls of a tool.
+type toolLatency struct {
+	slo       time.Duration
+	samples   []time.Duration
+	next      int
+	breaching bool
+}
+
+// p95 returns the 95th percentile of the recorded durations.
+func (l *toolLatency) p95() time.Duration {
+	sorted := append([]time.Duration(nil), l.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*95-1)/100]
+}
+
+// latencyStats is the latency of a tool as reported by the admin API.
+type latencyStats struct {
+	Calls     int   `json:"calls"`
+	P95Ms     int64 `json:"p95_ms"`
+	SloMs     int64 `json:"slo_ms"`
+	Breaching bool  `json:"breaching"`
+}
+
+// latencyTracker measures the p95 latency of the tools against their SLO.
+type latencyTracker struct {
+	mu    sync.Mutex
+	tools map[string]*toolLatency
+}
+
+var toolLatencies = &latencyTracker{tools: map[string]*toolLatency{}}
+
+// record adds a call duration and returns the p95 when the tool consistently
+// exceeds its SLO.
+func (t *latencyTracker) record(toolName string, slo, duration time.Duration) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	latency := t.tools[toolName]
+	if latency == nil {
+		latency = &toolLatency{}
+		t.tools[toolName] = latency
+	}
+	latency.slo = slo
+	if len(latency.samples) < latencyWindow {
+		latency.samples = append(latency.samples, duration)
+	} else {
+		latency.samples[latency.next] = duration
+		latency.next = (latency.next + 1) % latencyWindow
+	}
+	if len(latency.samples) < latencyMinSamples {
+		return 0, false
+	}
+	p95 := latency.p95().Round(time.Millisecond)
+	breaching := p95 > slo
+	if breaching != latency.breaching {
+		if breaching {
+			log.Printf("Tool %s exceeds its latency SLO: p95 %s over the last %d calls, SLO %s", toolName, p95, len(latency.samples), slo)
+		} else {
+			log.Printf("Tool %s is back within its latency SLO: p95 %s, SLO %s", toolName, p95, slo)
+		}
+		latency.breaching = breaching
+	}
+	return p95, breaching
+}
+
+// stats returns the latency of every measured tool.
+func (t *latencyTracker) stats() map[string]latencyStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := map[string]latencyStats{}
+	for name, latency := range t.tools {
+		stats[name] = latencyStats{
+			Calls:     len(latency.samples),
+			P95Ms:     latency.p95().Milliseconds(),
+			SloMs:     latency.slo.Milliseconds(),
+			Breaching: latency.breaching,
+		}
+	}
+	return stats
+}
+
+// wrapLatencySlo times the calls of handler and warns in the result when the
+// p95 latency of the tool is above slo, so a slow backend is not mistaken for
+// a broken tool.
+func wrapLatencySlo(toolName string, slo time.Duration, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, request)
+		if ctx.Err() != nil {
+			// cancelled calls say nothing about the backend
+			return result, err
+		}
+		p95, breaching := toolLatencies.record(toolName, slo, time.Since(start))
+		if err != nil || result == nil || !breaching {
+			return result, err
+		}
+		warning, _ := json.Marshal(map[string]string{
+			"latency_warning": fmt.Sprintf("the backend of this tool is slow: p95 latency %s over recent calls, above the %s target. Slow answers are expected, do not retry because of them.", p95, slo),
+		})
+		result.Content = append(result.Content, mcp.NewTextContent(string(warning)))
+		return result, nil
+	}
+}
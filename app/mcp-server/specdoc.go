@@ -0,0 +1,51 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	specDocumentURI = "swagger-mcp://spec"
+	specResolvedURI = "swagger-mcp://spec/resolved"
+)
+
+// addSpecResources registers the spec document the tools were generated
+// from, as loaded, and the spec as the server understood it once parsed: YAML
+// converted, OpenAPI 3.1 downgraded, path item parameters moved into the
+// operations and allOf schemas merged. The document resource is missing when
+// the spec comes from a provider that does not keep it.
+func addSpecResources(mcpServer *server.MCPServer, swaggerSpec models.SwaggerSpec, registered *specTools) {
+	if len(swaggerSpec.Document) > 0 {
+		mimeType := "application/yaml"
+		if trimmed := bytes.TrimSpace(swaggerSpec.Document); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			mimeType = "application/json"
+		}
+		addTextResource(mcpServer, specDocumentURI, "API spec", "The OpenAPI or Swagger document the tools of this server were generated from, as loaded, with its external $refs inlined", mimeType, string(swaggerSpec.Document))
+		registered.noteResource(specDocumentURI)
+	}
+	if resolved, err := json.MarshalIndent(swaggerSpec, "", "  "); err == nil {
+		addTextResource(mcpServer, specResolvedURI, "Resolved API spec", "The API spec as the server parsed it: the operations, parameters and schemas the tools are built from, with path item parameters moved into the operations and allOf schemas merged", "application/json", string(resolved))
+		registered.noteResource(specResolvedURI)
+	}
+}
+
+// addTextResource registers a resource serving text.
+func addTextResource(mcpServer *server.MCPServer, uri, name, description, mimeType, text string) {
+	mcpServer.AddResource(
+		mcp.NewResource(uri, name,
+			mcp.WithResourceDescription(description),
+			mcp.WithMIMEType(mimeType),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: text},
+			}, nil
+		},
+	)
+}
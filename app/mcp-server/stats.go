@@ -0,0 +1,230 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// statsFlushInterval is how often the usage stats are written to their file.
+const statsFlushInterval = 10 * time.Second
+
+// UsageStats is the opt-in usage summary kept in the --statsFile: call counts
+// and outcomes per tool and the size of the spec. Arguments, responses,
+// backend hosts and credentials are never recorded.
+type UsageStats struct {
+	Since   time.Time             `json:"since"`
+	Updated time.Time             `json:"updated"`
+	Spec    SpecStats             `json:"spec"`
+	Tools   map[string]*ToolStats `json:"tools"`
+}
+
+// SpecStats is the size of the spec last loaded.
+type SpecStats struct {
+	Operations int `json:"operations"`
+	Tools      int `json:"tools"`
+	Schemas    int `json:"schemas"`
+}
+
+// ToolStats counts the calls of one tool by outcome: the status class of the
+// backend response, network, validation or local, as in the /metrics counters.
+type ToolStats struct {
+	Calls       uint64            `json:"calls"`
+	Errors      uint64            `json:"errors"`
+	Outcomes    map[string]uint64 `json:"outcomes"`
+	TotalMillis int64             `json:"totalMillis"`
+}
+
+// ReadUsageStats reads the stats file at path.
+func ReadUsageStats(path string) (UsageStats, error) {
+	stats := UsageStats{Tools: map[string]*ToolStats{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read stats: %v", err)
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, fmt.Errorf("invalid stats file %s: %v", path, err)
+	}
+	if stats.Tools == nil {
+		stats.Tools = map[string]*ToolStats{}
+	}
+	return stats, nil
+}
+
+// usageRecorder aggregates the stats in memory and writes them to the file
+// every statsFlushInterval when they changed.
+type usageRecorder struct {
+	path  string
+	mu    sync.Mutex
+	stats UsageStats
+	dirty bool
+}
+
+var (
+	usageRecordersMu sync.Mutex
+	usageRecorders   = map[string]*usageRecorder{}
+)
+
+// openUsageStats returns the recorder of the stats file at path, shared by
+// every spec loaded with it. Counts already in the file are carried on.
+func openUsageStats(path string) (*usageRecorder, error) {
+	usageRecordersMu.Lock()
+	defer usageRecordersMu.Unlock()
+	if recorder, ok := usageRecorders[path]; ok {
+		return recorder, nil
+	}
+	stats := UsageStats{Tools: map[string]*ToolStats{}}
+	if _, err := os.Stat(path); err == nil {
+		if stats, err = ReadUsageStats(path); err != nil {
+			return nil, err
+		}
+	}
+	if stats.Since.IsZero() {
+		stats.Since = time.Now().UTC()
+	}
+	recorder := &usageRecorder{path: path, stats: stats}
+	usageRecorders[path] = recorder
+	go recorder.flushLoop()
+	return recorder, nil
+}
+
+func (r *usageRecorder) flushLoop() {
+	for range time.Tick(statsFlushInterval) {
+		if err := r.flush(); err != nil {
+			log.Printf("Failed to write usage stats: %v", err)
+		}
+	}
+}
+
+// flush writes the stats when they changed since the last write.
+func (r *usageRecorder) flush() error {
+	r.mu.Lock()
+	if !r.dirty {
+		r.mu.Unlock()
+		return nil
+	}
+	r.stats.Updated = time.Now().UTC()
+	data, err := json.MarshalIndent(r.stats, "", "  ")
+	r.dirty = false
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	// write next to the file and rename, stats never hold half a file
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// flushUsageStats writes the stats of every recorder, for a server stopping.
+func flushUsageStats() {
+	usageRecordersMu.Lock()
+	defer usageRecordersMu.Unlock()
+	for _, recorder := range usageRecorders {
+		if err := recorder.flush(); err != nil {
+			log.Printf("Failed to write usage stats: %v", err)
+		}
+	}
+}
+
+// noteSpec records the size of a loaded spec.
+func (r *usageRecorder) noteSpec(swaggerSpec models.SwaggerSpec, tools int) {
+	operations := 0
+	for _, methods := range swaggerSpec.Paths {
+		operations += len(methods)
+	}
+	schemas := len(swaggerSpec.Definitions)
+	if swaggerSpec.Components != nil {
+		schemas += len(swaggerSpec.Components.Schemas)
+	}
+	r.mu.Lock()
+	r.stats.Spec = SpecStats{Operations: operations, Tools: tools, Schemas: schemas}
+	r.dirty = true
+	r.mu.Unlock()
+}
+
+// wrap counts the calls of handler under toolName.
+func (r *usageRecorder) wrap(toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, call := withUpstreamCall(ctx)
+		start := time.Now()
+		result, err := handler(ctx, request)
+		class := statusClass(call, result, err)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		tool := r.stats.Tools[toolName]
+		if tool == nil {
+			tool = &ToolStats{Outcomes: map[string]uint64{}}
+			r.stats.Tools[toolName] = tool
+		}
+		tool.Calls++
+		if err != nil || result == nil || result.IsError {
+			tool.Errors++
+		}
+		tool.Outcomes[class]++
+		tool.TotalMillis += time.Since(start).Milliseconds()
+		r.dirty = true
+		return result, err
+	}
+}
+
+// anonymousToolName replaces a tool name, which tells the paths of the API,
+// with a short stable hash.
+func anonymousToolName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return "tool-" + hex.EncodeToString(sum[:4])
+}
+
+// WriteUsageStats prints the stats as a table, the busiest tools first. With
+// anonymize the tool names are replaced with hashes so the output can be
+// shared.
+func WriteUsageStats(w io.Writer, stats UsageStats, anonymize bool) {
+	fmt.Fprintf(w, "Since %s, updated %s\n", stats.Since.Format(time.RFC3339), stats.Updated.Format(time.RFC3339))
+	fmt.Fprintf(w, "Spec: %d operations, %d tools, %d schemas\n\n", stats.Spec.Operations, stats.Spec.Tools, stats.Spec.Schemas)
+	names := make([]string, 0, len(stats.Tools))
+	for name := range stats.Tools {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if stats.Tools[names[i]].Calls != stats.Tools[names[j]].Calls {
+			return stats.Tools[names[i]].Calls > stats.Tools[names[j]].Calls
+		}
+		return names[i] < names[j]
+	})
+	fmt.Fprintf(w, "TOOL\tCALLS\tERRORS\tMEAN MS\tOUTCOMES\n")
+	for _, name := range names {
+		tool := stats.Tools[name]
+		classes := make([]string, 0, len(tool.Outcomes))
+		for class := range tool.Outcomes {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		outcomes := make([]string, len(classes))
+		for i, class := range classes {
+			outcomes[i] = fmt.Sprintf("%s=%d", class, tool.Outcomes[class])
+		}
+		mean := int64(0)
+		if tool.Calls > 0 {
+			mean = tool.TotalMillis / int64(tool.Calls)
+		}
+		if anonymize {
+			name = anonymousToolName(name)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%d\t%s\n", name, tool.Calls, 100*float64(tool.Errors)/float64(max(tool.Calls, 1)), mean, strings.Join(outcomes, " "))
+	}
+}
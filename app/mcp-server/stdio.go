@@ -0,0 +1,102 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioTransport is the original stdout once GuardStdout reserved it for the
+// MCP messages of the stdio transport.
+var stdioTransport *os.File
+
+// GuardStdout reserves stdout for the stdio transport. Anything else written
+// to stdout, the startup output of this program as well as the prints or logs
+// of a dependency, would corrupt the JSON-RPC stream, so stdout is replaced
+// with a pipe copied line by line to stderr, and the transport keeps the
+// original. Call it before anything else is written to stdout.
+func GuardStdout() error {
+	transport, err := redirectStdout()
+	if err != nil {
+		return fmt.Errorf("failed to reserve stdout for the stdio transport: %v", err)
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		transport.Close()
+		return fmt.Errorf("failed to reserve stdout for the stdio transport: %v", err)
+	}
+	if err := replaceStdout(writer); err != nil {
+		transport.Close()
+		reader.Close()
+		writer.Close()
+		return fmt.Errorf("failed to reserve stdout for the stdio transport: %v", err)
+	}
+	os.Stdout = writer
+	stdioTransport = transport
+	go forwardStrayOutput(reader, os.Stderr)
+	return nil
+}
+
+// maxStrayLine is the longest line forwardStrayOutput copies, the rest of a
+// longer line, such as a large response body printed on one line, is dropped.
+const maxStrayLine = 1024 * 1024
+
+// forwardStrayOutput copies what was written to the guarded stdout to stderr,
+// prefixed so it is not mistaken for an error of the program. It reads until
+// the pipe is closed, a reader that stopped would block every later write.
+func forwardStrayOutput(reader io.Reader, stderr io.Writer) {
+	lines := bufio.NewReaderSize(reader, maxStrayLine)
+	truncated := false
+	for {
+		line, more, err := lines.ReadLine()
+		if err != nil {
+			return
+		}
+		switch {
+		case truncated:
+		case more:
+			fmt.Fprintf(stderr, "stdout: %s... (truncated)\n", line)
+		default:
+			fmt.Fprintf(stderr, "stdout: %s\n", line)
+		}
+		truncated = more
+	}
+}
+
+// checkStdioTransport verifies that stdout was reserved before the transport
+// starts writing to it, and returns the writer of the MCP messages.
+func checkStdioTransport() io.Writer {
+	if stdioTransport == nil {
+		log.Printf("Warning: stdout is not reserved for the stdio transport, output of other components may corrupt the MCP messages")
+		return os.Stdout
+	}
+	if os.Stdout == stdioTransport {
+		log.Fatalf("Error starting stdio server: stdout was restored over the MCP transport")
+	}
+	return stdioTransport
+}
+
+// serveStdio serves the MCP messages on stdin and the reserved stdout, like
+// server.ServeStdio does on the process stdout.
+func serveStdio(mcpServer *server.MCPServer) error {
+	stdout := checkStdioTransport()
+	stdio := server.NewStdioServer(mcpServer)
+	stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	return stdio.Listen(ctx, os.Stdin, stdout)
+}
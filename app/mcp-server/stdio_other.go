@@ -0,0 +1,15 @@
+//go:build !unix
+
+package mcpserver
+
+import "os"
+
+// redirectStdout returns stdout itself for the transport, only writes through
+// os.Stdout can be redirected on this platform.
+func redirectStdout() (*os.File, error) {
+	return os.Stdout, nil
+}
+
+func replaceStdout(writer *os.File) error {
+	return nil
+}
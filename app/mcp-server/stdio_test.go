@@ -0,0 +1,46 @@
+package mcpserver
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForwardStrayOutputKeepsReadingAfterLongLines(t *testing.T) {
+	reader, writer := io.Pipe()
+	var stderr bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		forwardStrayOutput(reader, &stderr)
+		close(done)
+	}()
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(writer, "before\n"+strings.Repeat("x", 3*maxStrayLine)+"\nafter\n")
+		writer.Close()
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("writing to the guarded stdout blocked after a long line")
+	}
+	<-done
+
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("forwarded %d lines, want 3", len(lines))
+	}
+	if lines[0] != "stdout: before" || lines[2] != "stdout: after" {
+		t.Errorf("forwarded %q and %q around the long line", lines[0], lines[2])
+	}
+	if want := "stdout: " + strings.Repeat("x", maxStrayLine) + "... (truncated)"; lines[1] != want {
+		t.Errorf("the long line is forwarded as %d bytes, want it truncated to %d", len(lines[1]), len(want))
+	}
+}
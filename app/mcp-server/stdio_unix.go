@@ -0,0 +1,24 @@
+//go:build unix
+
+package mcpserver
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// redirectStdout returns a copy of the stdout file descriptor for the transport.
+func redirectStdout() (*os.File, error) {
+	fd, err := unix.Dup(int(os.Stdout.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "/dev/stdout"), nil
+}
+
+// replaceStdout points file descriptor 1 to writer, so the writes of code
+// holding the old os.Stdout or the descriptor itself are caught too.
+func replaceStdout(writer *os.File) error {
+	return unix.Dup2(int(writer.Fd()), int(os.Stdout.Fd()))
+}
@@ -0,0 +1,148 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// responseStatusKey carries an *int the tool handlers store the HTTP status code in.
+const responseStatusKey = "__responseStatusKey"
+
+// TestSuite is a declarative list of tool invocations and their expected outcome.
+type TestSuite struct {
+	Name  string     `yaml:"name"`
+	Cases []TestCase `yaml:"cases"`
+}
+
+type TestCase struct {
+	Name      string                 `yaml:"name"`
+	Tool      string                 `yaml:"tool"`
+	Arguments map[string]interface{} `yaml:"arguments"`
+	Expect    TestExpectation        `yaml:"expect"`
+	Save      map[string]string      `yaml:"save"` // JSONPath in the result => variable usable as {{name}} in later cases
+}
+
+type TestExpectation struct {
+	Status   int                    `yaml:"status"`
+	Error    bool                   `yaml:"error"`
+	Contains []string               `yaml:"contains"`
+	Fields   map[string]interface{} `yaml:"fields"` // JSONPath => expected value
+}
+
+// RunTestSuite runs the YAML suite at suitePath against the tools generated
+// from swaggerSpec and writes a report to out. It returns the number of failed cases.
+func RunTestSuite(swaggerSpec models.SwaggerSpec, apiCfg models.ApiConfig, suitePath string, out io.Writer) (int, error) {
+	data, err := os.ReadFile(suitePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read test suite: %v", err)
+	}
+	var suite TestSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return 0, fmt.Errorf("invalid test suite: %v", err)
+	}
+
+	mcpServer := server.NewMCPServer("swagegr-mcp", "1.0.0")
+	LoadSwaggerServer(mcpServer, swaggerSpec, apiCfg)
+
+	fmt.Fprintf(out, "Running %s (%d cases)\n", suite.Name, len(suite.Cases))
+	vars := newVariableStore(nil)
+	failed := 0
+	for i, tc := range suite.Cases {
+		name := tc.Name
+		if name == "" {
+			name = fmt.Sprintf("case %d", i+1)
+		}
+		problems := runTestCase(mcpServer, vars, tc)
+		if len(problems) == 0 {
+			fmt.Fprintf(out, "PASS %s\n", name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "FAIL %s\n", name)
+		for _, problem := range problems {
+			fmt.Fprintf(out, "     %s\n", problem)
+		}
+	}
+	fmt.Fprintf(out, "%d passed, %d failed\n", len(suite.Cases)-failed, failed)
+	return failed, nil
+}
+
+func runTestCase(mcpServer *server.MCPServer, vars *variableStore, tc TestCase) []string {
+	args, err := vars.substitute("", tc.Arguments)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": tc.Tool, "arguments": args},
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("failed to build request: %v", err)}
+	}
+
+	status := 0
+	ctx := context.WithValue(context.Background(), responseStatusKey, &status)
+	response := mcpServer.HandleMessage(ctx, message)
+	rpcResponse, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		if rpcError, ok := response.(mcp.JSONRPCError); ok {
+			return []string{fmt.Sprintf("tool call failed: %s", rpcError.Error.Message)}
+		}
+		return []string{"unexpected response to tool call"}
+	}
+	toolResult, ok := rpcResponse.Result.(mcp.CallToolResult)
+	if !ok {
+		return []string{"unexpected tool result"}
+	}
+	result := &toolResult
+
+	problems := []string{}
+	text := resultText(result)
+	if result.IsError != tc.Expect.Error {
+		problems = append(problems, fmt.Sprintf("expected error=%t, got error=%t: %s", tc.Expect.Error, result.IsError, text))
+	}
+	if tc.Expect.Status != 0 && tc.Expect.Status != status {
+		problems = append(problems, fmt.Sprintf("expected status %d, got %d", tc.Expect.Status, status))
+	}
+	for _, expected := range tc.Expect.Contains {
+		if !strings.Contains(text, expected) {
+			problems = append(problems, fmt.Sprintf("expected result to contain %q", expected))
+		}
+	}
+
+	var data interface{}
+	if len(tc.Expect.Fields) > 0 || len(tc.Save) > 0 {
+		if len(result.Content) > 0 {
+			if first, ok := result.Content[0].(mcp.TextContent); ok {
+				_ = json.Unmarshal([]byte(first.Text), &data)
+			}
+		}
+	}
+	for path, expected := range tc.Expect.Fields {
+		actual, found := lookupPath(data, path)
+		if !found {
+			problems = append(problems, fmt.Sprintf("expected field %s to be present", path))
+		} else if actual != fmt.Sprint(expected) {
+			problems = append(problems, fmt.Sprintf("expected field %s to be %v, got %s", path, expected, actual))
+		}
+	}
+	for path, name := range tc.Save {
+		if value, found := lookupPath(data, path); found {
+			vars.set("", name, value)
+		} else {
+			problems = append(problems, fmt.Sprintf("field %s to save as %s not found", path, name))
+		}
+	}
+	return problems
+}
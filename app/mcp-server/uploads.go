@@ -0,0 +1,68 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// bodyFileArgument is the tool argument naming a file streamed as the raw request body.
+const bodyFileArgument = "body_file"
+
+// uploadBody streams a file as a request body and reports how much of it was sent.
+type uploadBody struct {
+	ctx  context.Context
+	file *os.File
+	size int64
+	sent int64
+}
+
+func (u *uploadBody) Read(p []byte) (int, error) {
+	n, err := u.file.Read(p)
+	u.sent += int64(n)
+	if u.size > 0 {
+		setProgressState(u.ctx, fmt.Sprintf("uploading %s, %d%% of %d bytes sent", u.file.Name(), u.sent*100/u.size, u.size))
+	}
+	return n, err
+}
+
+func (u *uploadBody) Close() error {
+	return u.file.Close()
+}
+
+// describeUpload returns the description of the body_file tool argument.
+func (d *downloadRoots) describeUpload() string {
+	return fmt.Sprintf("File to send as the request body instead of the body argument, for large uploads. Relative to %s or an absolute path inside one of: %s", d.dirs[0], strings.Join(d.dirs, ", "))
+}
+
+// openUpload opens a file inside the roots to stream it as a request body
+// without loading it in memory.
+func (d *downloadRoots) openUpload(ctx context.Context, path string) (*uploadBody, error) {
+	file, err := d.resolve(path, "", "")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	return &uploadBody{ctx: ctx, file: f, size: info.Size()}, nil
+}
+
+// getBody reopens the upload for a retry of the request.
+func (d *downloadRoots) getBody(ctx context.Context, path string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return d.openUpload(ctx, path)
+	}
+}
@@ -0,0 +1,143 @@
+package mcpserver
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// bodySchema checks an assembled request body against the properties of the
+// operation's body schema before it is sent.
+type bodySchema struct {
+	properties map[string]models.Property
+	patterns   map[string]*regexp.Regexp
+	required   []string
+	arguments  map[string]string // property => tool argument, when they differ
+}
+
+func newBodySchema() *bodySchema {
+	return &bodySchema{properties: map[string]models.Property{}, patterns: map[string]*regexp.Regexp{}, arguments: map[string]string{}}
+}
+
+// add takes in the properties of a body schema definition.
+func (b *bodySchema) add(definition models.Definition, aliases *argumentAliases) {
+	for propName, prop := range definition.Properties {
+		b.properties[propName] = prop
+		if prop.Pattern != "" {
+			if pattern, err := regexp.Compile(prop.Pattern); err == nil {
+				b.patterns[propName] = pattern
+			} else {
+				fmt.Printf("Ignoring invalid pattern of %s: %v\n", propName, err)
+			}
+		}
+		if argument := aliases.name(propName); argument != propName {
+			b.arguments[propName] = argument
+		}
+	}
+	for _, name := range definition.Required {
+		if !slices.Contains(b.required, name) {
+			b.required = append(b.required, name)
+		}
+	}
+}
+
+// validate returns one message per invalid field of body, sorted by field.
+func (b *bodySchema) validate(body map[string]interface{}) []string {
+	problems := []string{}
+	for _, name := range b.required {
+		if _, ok := body[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: is required", b.label(name)))
+		}
+	}
+	for name, value := range body {
+		prop, ok := b.properties[name]
+		if !ok {
+			continue
+		}
+		if value == nil {
+			if !prop.IsNullable() {
+				problems = append(problems, fmt.Sprintf("%s: must not be null", b.label(name)))
+			}
+			continue
+		}
+		if prop.Type != "" && !hasSchemaType(value, prop.Type) {
+			problems = append(problems, fmt.Sprintf("%s: expected %s, got %s", b.label(name), prop.Type, jsonType(value)))
+			continue
+		}
+		if len(prop.Enum) > 0 && !slices.ContainsFunc(prop.Enum, func(allowed interface{}) bool { return fmt.Sprint(allowed) == fmt.Sprint(value) }) {
+			allowed := make([]string, len(prop.Enum))
+			for i, v := range prop.Enum {
+				allowed[i] = fmt.Sprint(v)
+			}
+			problems = append(problems, fmt.Sprintf("%s: %v is not one of %s", b.label(name), value, strings.Join(allowed, ", ")))
+		}
+		if pattern, ok := b.patterns[name]; ok {
+			if str, isString := value.(string); isString && !pattern.MatchString(str) {
+				problems = append(problems, fmt.Sprintf("%s: %q does not match the pattern %s", b.label(name), str, prop.Pattern))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func (b *bodySchema) label(name string) string {
+	if argument, ok := b.arguments[name]; ok {
+		return fmt.Sprintf("%s (argument %s)", name, argument)
+	}
+	return name
+}
+
+// hasSchemaType reports whether a body value is of the JSON Schema type.
+func hasSchemaType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		switch v := value.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return v == math.Trunc(v)
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int64, float64:
+			return true
+		}
+		return false
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case int, int64, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,227 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// captureRule saves the value found at Path in a tool result as variable Name.
+type captureRule struct {
+	Tool string // empty matches every tool
+	Path string
+	Name string
+}
+
+// variableStore keeps per-session variables that can be referenced as
+// {{name}} in tool arguments.
+type variableStore struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]string
+	rules    []captureRule
+}
+
+func newVariableStore(rules []captureRule) *variableStore {
+	return &variableStore{sessions: map[string]map[string]string{}, rules: rules}
+}
+
+// parseCaptureRules parses rules in [tool:]$.path=name format, separated by commas.
+func parseCaptureRules(rules string) []captureRule {
+	parsed := []captureRule{}
+	for _, rule := range splitList(rules) {
+		expr, name, ok := strings.Cut(rule, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			fmt.Printf("Invalid capture rule: %s\n", rule)
+			continue
+		}
+		tool := ""
+		if idx := strings.Index(expr, ":$"); idx >= 0 {
+			tool, expr = expr[:idx], expr[idx+1:]
+		}
+		if tool == "*" {
+			tool = ""
+		}
+		parsed = append(parsed, captureRule{Tool: strings.TrimSpace(tool), Path: strings.TrimSpace(expr), Name: strings.TrimSpace(name)})
+	}
+	return parsed
+}
+
+func (v *variableStore) set(session, name, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.sessions[session] == nil {
+		v.sessions[session] = map[string]string{}
+	}
+	v.sessions[session][name] = value
+}
+
+// forget drops the variables of a session that ended.
+func (v *variableStore) forget(session string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.sessions, session)
+}
+
+func (v *variableStore) all(session string) map[string]string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	vars := map[string]string{}
+	for name, value := range v.sessions[session] {
+		vars[name] = value
+	}
+	return vars
+}
+
+// substitute replaces {{name}} references in the string arguments.
+func (v *variableStore) substitute(session string, args map[string]interface{}) (map[string]interface{}, error) {
+	vars := v.all(session)
+	substituted := make(map[string]interface{}, len(args))
+	for key, arg := range args {
+		str, ok := arg.(string)
+		if !ok {
+			substituted[key] = arg
+			continue
+		}
+		var missing string
+		substituted[key] = variablePattern.ReplaceAllStringFunc(str, func(ref string) string {
+			name := variablePattern.FindStringSubmatch(ref)[1]
+			value, found := vars[name]
+			if !found {
+				missing = name
+			}
+			return value
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("variable %s is not set", missing)
+		}
+	}
+	return substituted, nil
+}
+
+// capture applies the capture rules of toolName to a JSON result.
+func (v *variableStore) capture(session, toolName, text string) {
+	var data interface{}
+	if json.Unmarshal([]byte(text), &data) != nil {
+		return
+	}
+	for _, rule := range v.rules {
+		if rule.Tool != "" && rule.Tool != toolName {
+			continue
+		}
+		if value, ok := lookupPath(data, rule.Path); ok {
+			v.set(session, rule.Name, value)
+		}
+	}
+}
+
+// lookupPath resolves a simple JSONPath such as $.items[0].id and returns the value as text.
+func lookupPath(data interface{}, path string) (string, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := data
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '[' }) {
+		if index, err := strconv.Atoi(strings.TrimSuffix(part, "]")); err == nil && strings.HasSuffix(part, "]") {
+			list, ok := current.([]interface{})
+			if !ok || index < 0 || index >= len(list) {
+				return "", false
+			}
+			current = list[index]
+			continue
+		}
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if current, ok = obj[part]; !ok {
+			return "", false
+		}
+	}
+	switch value := current.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	default:
+		data, err := json.Marshal(value)
+		return string(data), err == nil
+	}
+}
+
+// wrap substitutes variables in the arguments of handler and captures values from its results.
+func (v *variableStore) wrap(toolName string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := sessionID(ctx)
+		args, err := v.substitute(session, request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+		}
+		request.Params.Arguments = args
+		result, err := handler(ctx, request)
+		if err == nil && result != nil && !result.IsError {
+			// structured sections such as response_headers follow the body
+			for _, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					v.capture(session, toolName, text.Text)
+				}
+			}
+		}
+		return result, err
+	}
+}
+
+// addVariableTools registers the set_variable and get_variable meta tools.
+func (v *variableStore) addVariableTools(mcpServer *server.MCPServer) {
+	mcpServer.AddTool(
+		mcp.NewTool("set_variable",
+			mcp.WithDescription("Save a value under a name for this session. Reference it as {{name}} in the arguments of any other tool instead of repeating the value."),
+			mcp.WithString("name", mcp.Description("The variable name"), mcp.Required()),
+			mcp.WithString("value", mcp.Description("The value to save"), mcp.Required()),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name, _ := request.GetArguments()["name"].(string)
+			value, ok := request.GetArguments()["value"].(string)
+			if strings.TrimSpace(name) == "" || !ok {
+				return mcp.NewToolResultError("[Error] name and value are required"), nil
+			}
+			v.set(sessionID(ctx), strings.TrimSpace(name), value)
+			return mcp.NewToolResultText(fmt.Sprintf("saved {{%s}}", strings.TrimSpace(name))), nil
+		},
+	)
+	mcpServer.AddTool(
+		mcp.NewTool("get_variable",
+			mcp.WithDescription("Read a variable saved in this session, or list all of them when no name is given."),
+			mcp.WithString("name", mcp.Description("The variable name")),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			vars := v.all(sessionID(ctx))
+			if name, _ := request.GetArguments()["name"].(string); name != "" {
+				value, ok := vars[name]
+				if !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("[Error] variable %s is not set", name)), nil
+				}
+				return mcp.NewToolResultText(value), nil
+			}
+			names := make([]string, 0, len(vars))
+			for name := range vars {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			lines := make([]string, len(names))
+			for i, name := range names {
+				lines[i] = fmt.Sprintf("%s=%s", name, vars[name])
+			}
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+		},
+	)
+}
@@ -0,0 +1,194 @@
+package mcpserver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// variantArgument selects the variant of a polymorphic body without a discriminator.
+const variantArgument = "_variant"
+
+// bodyVariant is one schema of a oneOf or anyOf request body.
+type bodyVariant struct {
+	Name       string // discriminator value, or the schema name when there is none
+	Properties []string
+	Required   []string
+}
+
+// bodyVariants describes a polymorphic request body. The tool gets the
+// properties of all variants as optional arguments plus one argument picking
+// the variant, which decides the fields sent and the fields required.
+type bodyVariants struct {
+	Argument string // tool argument selecting the variant
+	Property string // body field carrying the discriminator, empty without one
+	Variants []bodyVariant
+	union    models.Definition
+}
+
+// inlineDefinition converts an inline body schema to a definition.
+func inlineDefinition(schema *models.SchemaRef) models.Definition {
+	definition := models.Definition{
+		Type:          schema.Type,
+		Title:         schema.Title,
+		Required:      schema.Required,
+		Ref:           schema.Ref,
+		Discriminator: schema.Discriminator,
+		Properties:    map[string]models.Property{},
+	}
+	for propName, prop := range schema.Properties {
+		definition.Properties[propName] = schemaProperty(prop)
+	}
+	for _, part := range schema.AllOf {
+		definition.AllOf = append(definition.AllOf, inlineDefinition(part))
+	}
+	for _, part := range schema.OneOf {
+		definition.OneOf = append(definition.OneOf, inlineDefinition(part))
+	}
+	for _, part := range schema.AnyOf {
+		definition.AnyOf = append(definition.AnyOf, inlineDefinition(part))
+	}
+	return definition
+}
+
+// resolveDefinition follows the $ref of a definition and merges its allOf parts.
+func resolveDefinition(swaggerSpec models.SwaggerSpec, definition models.Definition, depth int) models.Definition {
+	if definition.Ref != "" {
+		target, found := lookupDefinition(swaggerSpec, definition.Ref)
+		if !found || depth > maxNestedDepth {
+			return models.Definition{Type: "object"}
+		}
+		if target.Title == "" {
+			target.Title = ExtractSchemaName(definition.Ref, "")
+		}
+		definition = target
+	}
+	if len(definition.AllOf) == 0 {
+		return definition
+	}
+	merged := models.Definition{Title: definition.Title, OneOf: definition.OneOf, AnyOf: definition.AnyOf, Discriminator: definition.Discriminator}
+	for _, part := range definition.AllOf {
+		merged.Merge(resolveDefinition(swaggerSpec, part, depth+1))
+	}
+	merged.Merge(definition)
+	return merged
+}
+
+// bodyVariantsOf returns the variants of a request body declared with oneOf or
+// anyOf, or nil for other bodies.
+func bodyVariantsOf(swaggerSpec models.SwaggerSpec, schema *models.SchemaRef) *bodyVariants {
+	definition := resolveDefinition(swaggerSpec, inlineDefinition(schema), 0)
+	parts := definition.OneOf
+	if len(parts) == 0 {
+		parts = definition.AnyOf
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	variants := &bodyVariants{Argument: variantArgument, union: models.Definition{Type: "object"}}
+	values := map[string]string{} // variant ref => discriminator value
+	if definition.Discriminator != nil && definition.Discriminator.PropertyName != "" {
+		variants.Argument = definition.Discriminator.PropertyName
+		variants.Property = definition.Discriminator.PropertyName
+		for value, ref := range definition.Discriminator.Mapping {
+			// a $ref, or the bare schema name
+			values[ExtractSchemaName(ref, "")] = value
+		}
+	}
+	for i, part := range parts {
+		name := ""
+		if part.Ref != "" {
+			name = ExtractSchemaName(part.Ref, "")
+		}
+		resolved := resolveDefinition(swaggerSpec, part, 0)
+		if value, ok := values[name]; ok {
+			name = value
+		}
+		if name == "" {
+			name = resolved.Title
+		}
+		if name == "" {
+			name = fmt.Sprintf("variant_%d", i+1)
+		}
+		// properties declared next to oneOf belong to every variant
+		own := models.Definition{}
+		own.Merge(resolved)
+		own.Merge(models.Definition{Properties: definition.Properties, PropertyOrder: definition.PropertyOrder, Required: definition.Required})
+		variant := bodyVariant{Name: name}
+		for _, propName := range own.PropertyOrder {
+			if propName == variants.Property {
+				continue
+			}
+			variant.Properties = append(variant.Properties, propName)
+			if slices.Contains(own.Required, propName) {
+				variant.Required = append(variant.Required, propName)
+			}
+		}
+		variants.Variants = append(variants.Variants, variant)
+		for _, propName := range variant.Properties {
+			if _, exists := variants.union.Properties[propName]; !exists {
+				variants.union.Merge(models.Definition{Properties: map[string]models.Property{propName: own.Properties[propName]}})
+			}
+		}
+	}
+	return variants
+}
+
+func (v *bodyVariants) names() []string {
+	names := make([]string, len(v.Variants))
+	for i, variant := range v.Variants {
+		names[i] = variant.Name
+	}
+	return names
+}
+
+// option builds the tool argument selecting the variant, describing the fields of each.
+func (v *bodyVariants) option() mcp.ToolOption {
+	described := make([]string, len(v.Variants))
+	for i, variant := range v.Variants {
+		described[i] = fmt.Sprintf("%s: %s", variant.Name, strings.Join(variant.Properties, ", "))
+		if len(variant.Required) > 0 {
+			described[i] += fmt.Sprintf(" (required: %s)", strings.Join(variant.Required, ", "))
+		}
+	}
+	return mcp.WithString(v.Argument,
+		mcp.Description(fmt.Sprintf("The kind of request body to send, it decides which of the other body arguments apply. %s", strings.Join(described, "; "))),
+		mcp.Enum(v.names()...),
+		mcp.Required(),
+	)
+}
+
+// apply narrows the assembled body to the selected variant: fields of other
+// variants are refused, its required fields checked and the discriminator set.
+func (v *bodyVariants) apply(args map[string]interface{}, body map[string]interface{}) error {
+	selected, _ := args[v.Argument].(string)
+	index := slices.IndexFunc(v.Variants, func(variant bodyVariant) bool { return variant.Name == selected })
+	if index < 0 {
+		return fmt.Errorf("%s must be one of: %s", v.Argument, strings.Join(v.names(), ", "))
+	}
+	variant := v.Variants[index]
+	for name := range body {
+		if _, given := args[name]; given && !slices.Contains(variant.Properties, name) {
+			return fmt.Errorf("%s does not apply to %s %s, it takes: %s", name, v.Argument, variant.Name, strings.Join(variant.Properties, ", "))
+		}
+		if !slices.Contains(variant.Properties, name) {
+			delete(body, name)
+		}
+	}
+	missing := []string{}
+	for _, name := range variant.Required {
+		if _, ok := body[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing Body Parameter: %s (required by %s %s)", strings.Join(missing, ", "), v.Argument, variant.Name)
+	}
+	if v.Property != "" {
+		body[v.Property] = variant.Name
+	}
+	return nil
+}
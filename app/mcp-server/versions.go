@@ -0,0 +1,52 @@
+package mcpserver
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+(\.[0-9]+)*$`)
+
+// pathVersion returns the version segment of path, e.g. v2 for /api/v2/users,
+// and the path without it. The version is empty for unversioned paths.
+func pathVersion(path string) (string, string) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if versionSegment.MatchString(segment) {
+			return segment, strings.Join(append(segments[:i:i], segments[i+1:]...), "/")
+		}
+	}
+	return "", path
+}
+
+// inVersion reports whether an operation on path belongs to the exposed API
+// version. Unversioned paths are always exposed.
+func inVersion(apiVersion, path string) bool {
+	if apiVersion == "" {
+		return true
+	}
+	version, _ := pathVersion(path)
+	return version == "" || strings.EqualFold(version, apiVersion)
+}
+
+// otherVersionTools lists the tools of the same operation in the other API versions.
+func otherVersionTools(path, method string, toolNames map[string]map[string]string) []string {
+	version, unversioned := pathVersion(path)
+	if version == "" {
+		return nil
+	}
+	others := []string{}
+	for otherPath, methods := range toolNames {
+		otherVersion, otherUnversioned := pathVersion(otherPath)
+		if otherVersion == "" || otherVersion == version || otherUnversioned != unversioned {
+			continue
+		}
+		if name, ok := methods[method]; ok {
+			others = append(others, fmt.Sprintf("%s (%s)", name, otherVersion))
+		}
+	}
+	sort.Strings(others)
+	return others
+}
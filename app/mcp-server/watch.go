@@ -0,0 +1,305 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultWatchInterval = time.Minute
+	minWatchInterval     = 5 * time.Second
+	defaultWatchDuration = time.Hour
+	maxWatchDuration     = 24 * time.Hour
+
+	// watches a session may run at once
+	maxSessionWatches = 10
+)
+
+// contentHash returns the hash of the response text of a result.
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// wrapContentHash adds a content_hash section to the successful results of
+// handler, the hash of the response, so agents can tell whether it changed
+// without comparing whole responses.
+func wrapContentHash(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+			return result, err
+		}
+		first, ok := result.Content[0].(mcp.TextContent)
+		if !ok {
+			return result, nil
+		}
+		hashData, _ := json.Marshal(map[string]interface{}{"content_hash": contentHash(first.Text)})
+		result.Content = append(result.Content, mcp.NewTextContent(string(hashData)))
+		return result, nil
+	}
+}
+
+// resultHash returns the content_hash section wrapContentHash added to result.
+func resultHash(result *mcp.CallToolResult) (string, bool) {
+	for _, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok || !strings.HasPrefix(text.Text, `{"content_hash":`) {
+			continue
+		}
+		var section struct {
+			ContentHash string `json:"content_hash"`
+		}
+		if json.Unmarshal([]byte(text.Text), &section) == nil {
+			return section.ContentHash, true
+		}
+	}
+	return "", false
+}
+
+// endpointWatch calls a read-only tool of a session every interval until it
+// is stopped or expires.
+type endpointWatch struct {
+	ID        string                 `json:"watch_id"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Interval  float64                `json:"interval_seconds"`
+	Until     time.Time              `json:"until"`
+
+	session string
+	cancel  context.CancelFunc
+}
+
+// watchRegistry keeps the running watches of all sessions.
+type watchRegistry struct {
+	mu      sync.Mutex
+	watches map[string]*endpointWatch
+	next    int
+}
+
+var endpointWatches = &watchRegistry{watches: map[string]*endpointWatch{}}
+
+// start registers watch unless its session runs too many already.
+func (r *watchRegistry) start(watch *endpointWatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, running := range r.watches {
+		if running.session == watch.session {
+			count++
+		}
+	}
+	if count >= maxSessionWatches {
+		return fmt.Errorf("this session already runs %d watches, stop one with unwatch_endpoint first", maxSessionWatches)
+	}
+	r.next++
+	watch.ID = fmt.Sprintf("watch-%d", r.next)
+	r.watches[watch.ID] = watch
+	return nil
+}
+
+// stop ends the watch id of session.
+func (r *watchRegistry) stop(session, id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	watch, ok := r.watches[id]
+	if !ok || watch.session != session {
+		return false
+	}
+	watch.cancel()
+	delete(r.watches, id)
+	return true
+}
+
+// remove forgets a watch that ended by itself.
+func (r *watchRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.watches, id)
+}
+
+// addWatchTools registers the watch_endpoint and unwatch_endpoint meta tools.
+func addWatchTools(mcpServer *server.MCPServer) {
+	mcpServer.AddTool(
+		mcp.NewTool("watch_endpoint",
+			mcp.WithDescription("Watch a read-only API tool: call it now and then every interval, and get a notifications/message when its response changes, with the new response. Use it to monitor a resource instead of polling it yourself. Returns the watch_id and the current response."),
+			mcp.WithString("tool", mcp.Required(), mcp.Description("Name of the read-only (GET) tool to watch")),
+			mcp.WithObject("arguments", mcp.Description("Arguments of the tool calls")),
+			mcp.WithNumber("interval_seconds", mcp.Description("Seconds between calls, at least 5 (default 60)")),
+			mcp.WithNumber("duration_seconds", mcp.Description("Seconds after which the watch stops, at most 86400 (default 3600)")),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name, _ := request.GetArguments()["tool"].(string)
+			arguments, _ := request.GetArguments()["arguments"].(map[string]interface{})
+			interval, err := watchSeconds(request, "interval_seconds", defaultWatchInterval, minWatchInterval, 0)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+			}
+			duration, err := watchSeconds(request, "duration_seconds", defaultWatchDuration, interval, maxWatchDuration)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+			}
+			mcpServer := server.ServerFromContext(ctx)
+			session := server.ClientSessionFromContext(ctx)
+			if mcpServer == nil || session == nil {
+				return mcp.NewToolResultError("[Error] watches need a client session"), nil
+			}
+			tool, ok := apiTools.lookup(mcpServer, name)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] unknown tool: %s", name)), nil
+			}
+			if tool.Tool.Annotations.ReadOnlyHint == nil || !*tool.Tool.Annotations.ReadOnlyHint {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %s is not read-only, only GET tools can be watched", name)), nil
+			}
+
+			watchCtx, cancel := context.WithTimeout(mcpServer.WithContext(context.Background(), session), duration)
+			watch := &endpointWatch{
+				Tool:      name,
+				Arguments: arguments,
+				Interval:  interval.Seconds(),
+				Until:     time.Now().Add(duration).UTC().Truncate(time.Second),
+				session:   session.SessionID(),
+				cancel:    cancel,
+			}
+			result, err := callWatched(watchCtx, tool, arguments)
+			if err != nil || result.IsError {
+				cancel()
+				if err != nil {
+					return nil, err
+				}
+				return result, nil
+			}
+			hash, _ := resultHash(result)
+			if err := endpointWatches.start(watch); err != nil {
+				cancel()
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] %v", err)), nil
+			}
+			go watch.run(watchCtx, mcpServer, tool, hash, interval)
+
+			watchData, _ := json.Marshal(map[string]interface{}{"watch": watch, "content_hash": hash})
+			result.Content = append([]mcp.Content{mcp.NewTextContent(string(watchData))}, result.Content...)
+			return result, nil
+		},
+	)
+	mcpServer.AddTool(
+		mcp.NewTool("unwatch_endpoint",
+			mcp.WithDescription("Stop a watch started with watch_endpoint."),
+			mcp.WithString("watch_id", mcp.Required(), mcp.Description("The watch_id watch_endpoint returned")),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			id, _ := request.GetArguments()["watch_id"].(string)
+			if !endpointWatches.stop(sessionID(ctx), id) {
+				return mcp.NewToolResultError(fmt.Sprintf("[Error] unknown watch: %s", id)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Stopped watch %s", id)), nil
+		},
+	)
+}
+
+// watchSeconds reads a number of seconds argument, fallback when absent.
+// Values below lowest are refused, like values above highest when it is set.
+func watchSeconds(request mcp.CallToolRequest, name string, fallback, lowest, highest time.Duration) (time.Duration, error) {
+	value, ok := request.GetArguments()[name]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+	seconds, ok := value.(float64)
+	duration := time.Duration(seconds * float64(time.Second))
+	if !ok || duration < lowest {
+		return 0, fmt.Errorf("invalid %s: %v, it must be at least %v", name, value, lowest.Seconds())
+	}
+	if highest > 0 && duration > highest {
+		return 0, fmt.Errorf("invalid %s: %v, it must be at most %v", name, value, highest.Seconds())
+	}
+	return duration, nil
+}
+
+// callWatched calls the watched tool like a client would.
+func callWatched(ctx context.Context, tool server.ServerTool, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool.Tool.Name
+	request.Params.Arguments = arguments
+	result, err := tool.Handler(ctx, request)
+	if err == nil && result == nil {
+		err = errors.New("the tool returned no result")
+	}
+	return result, err
+}
+
+// run calls the tool every interval and notifies the session when the hash
+// of the response, or the error the call fails with, changes.
+func (w *endpointWatch) run(ctx context.Context, mcpServer *server.MCPServer, tool server.ServerTool, hash string, interval time.Duration) {
+	defer endpointWatches.remove(w.ID)
+	defer w.cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastError := ""
+	for {
+		select {
+		case <-ctx.Done():
+			w.notify(mcpServer, map[string]interface{}{"event": "stopped"})
+			return
+		case <-ticker.C:
+		}
+		result, err := callWatched(ctx, tool, w.Arguments)
+		if ctx.Err() != nil {
+			continue
+		}
+		if err != nil || result.IsError {
+			message := ""
+			if err != nil {
+				message = err.Error()
+			} else {
+				message = resultText(result)
+			}
+			if message != lastError && !w.notify(mcpServer, map[string]interface{}{"event": "error", "error": message}) {
+				return
+			}
+			lastError = message
+			continue
+		}
+		lastError = ""
+		current, _ := resultHash(result)
+		if current == hash {
+			continue
+		}
+		event := map[string]interface{}{"event": "changed", "previous_hash": hash, "content_hash": current}
+		if first, ok := result.Content[0].(mcp.TextContent); ok {
+			event["response"] = first.Text
+		}
+		if !w.notify(mcpServer, event) {
+			return
+		}
+		hash = current
+	}
+}
+
+// notify sends event to the session of the watch as a log message. It is
+// false when the session is gone.
+func (w *endpointWatch) notify(mcpServer *server.MCPServer, event map[string]interface{}) bool {
+	event["watch_id"] = w.ID
+	event["tool"] = w.Tool
+	err := mcpServer.SendNotificationToSpecificClient(w.session, "notifications/message", map[string]any{
+		"level":  "info",
+		"logger": "watch_endpoint",
+		"data":   event,
+	})
+	if errors.Is(err, server.ErrSessionNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Printf("Failed to notify watch %s: %v", w.ID, err)
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// route is one operation of the spec served by the mock backend.
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	status  int
+	body    interface{}
+	hasBody bool
+}
+
+var pathParamPattern = regexp.MustCompile(`\{[^}]+\}`)
+
+// BasePath returns the path prefix the spec's operations are served under.
+func BasePath(swaggerSpec models.SwaggerSpec) string {
+	if swaggerSpec.OpenAPI != "" {
+		if len(swaggerSpec.Servers) > 0 {
+			if u, err := url.Parse(swaggerSpec.Servers[0].URL); err == nil {
+				return strings.TrimSuffix(u.Path, "/")
+			}
+		}
+		return ""
+	}
+	return strings.TrimSuffix(swaggerSpec.BasePath, "/")
+}
+
+// NewHandler returns an http.Handler answering every operation of the spec
+// with its success status and example response.
+func NewHandler(swaggerSpec models.SwaggerSpec) http.Handler {
+	basePath := BasePath(swaggerSpec)
+	routes := []route{}
+	for path, methods := range swaggerSpec.Paths {
+		literals := pathParamPattern.Split(basePath+"/"+strings.TrimPrefix(path, "/"), -1)
+		for i := range literals {
+			literals[i] = regexp.QuoteMeta(literals[i])
+		}
+		pattern := regexp.MustCompile("^" + strings.Join(literals, `[^/]+`) + "/?$")
+		for method, details := range methods {
+			status, body, hasBody := successResponse(swaggerSpec, details)
+			routes = append(routes, route{
+				method:  strings.ToUpper(method),
+				pattern: pattern,
+				status:  status,
+				body:    body,
+				hasBody: hasBody,
+			})
+		}
+	}
+	// literal segments win over path parameters, e.g. /users/me before /users/{id}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return strings.Count(routes[i].pattern.String(), `[^/]+`) < strings.Count(routes[j].pattern.String(), `[^/]+`)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("Mock request: %s %s", r.Method, r.URL.Path)
+		pathMatched := false
+		for _, rt := range routes {
+			if !rt.pattern.MatchString(r.URL.Path) {
+				continue
+			}
+			pathMatched = true
+			if rt.method != r.Method {
+				continue
+			}
+			if !rt.hasBody {
+				w.WriteHeader(rt.status)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(rt.status)
+			json.NewEncoder(w).Encode(rt.body)
+			return
+		}
+		if pathMatched {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		http.NotFound(w, r)
+	})
+}
+
+// Serve runs the mock backend on addr until it fails.
+func Serve(swaggerSpec models.SwaggerSpec, addr string) error {
+	log.Printf("Starting mock backend on %s, base path: %q", addr, BasePath(swaggerSpec))
+	return http.ListenAndServe(addr, NewHandler(swaggerSpec))
+}
+
+// successResponse picks the lowest 2xx response of the operation and its example body.
+func successResponse(swaggerSpec models.SwaggerSpec, details models.Endpoint) (int, interface{}, bool) {
+	codes := []int{}
+	for code := range details.Responses {
+		if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 {
+			codes = append(codes, n)
+		}
+	}
+	sort.Ints(codes)
+	if len(codes) == 0 {
+		if resp, ok := details.Responses["default"]; ok {
+			body, found := exampleBody(swaggerSpec, resp)
+			return http.StatusOK, body, found
+		}
+		return http.StatusOK, nil, false
+	}
+	resp := details.Responses[strconv.Itoa(codes[0])]
+	body, found := exampleBody(swaggerSpec, resp)
+	if !found && codes[0] != http.StatusNoContent {
+		return codes[0], map[string]interface{}{}, true
+	}
+	return codes[0], body, found
+}
+
+// exampleBody returns the example declared for the response, or a value
+// built from its schema when there is none.
+func exampleBody(swaggerSpec models.SwaggerSpec, resp models.Response) (interface{}, bool) {
+	example, _, found := ResponseExample(swaggerSpec, resp)
+	return example, found
+}
+
+// ResponseExample returns the example declared for a JSON response, or one
+// synthesized from its schema, respecting enums and formats. declared tells the
+// two apart; found is false when the response has neither.
+func ResponseExample(swaggerSpec models.SwaggerSpec, resp models.Response) (example interface{}, declared bool, found bool) {
+	for mime, example := range resp.Examples {
+		if strings.Contains(mime, "json") {
+			return example, true, true
+		}
+	}
+	for mime, mediaType := range resp.Content {
+		if !strings.Contains(mime, "json") {
+			continue
+		}
+		if mediaType.Example != nil {
+			return mediaType.Example, true, true
+		}
+		for _, named := range mediaType.Examples {
+			if example, ok := named.(map[string]interface{}); ok {
+				if value, ok := example["value"]; ok {
+					return value, true, true
+				}
+			}
+		}
+		if mediaType.Schema != nil {
+			return schemaExample(swaggerSpec, mediaType.Schema, 0), false, true
+		}
+	}
+	if resp.Schema != nil {
+		return schemaExample(swaggerSpec, resp.Schema, 0), false, true
+	}
+	return nil, false, false
+}
+
+// schemaExample builds a value for a schema from its examples, falling back to
+// values matching its enum, format and type.
+func schemaExample(swaggerSpec models.SwaggerSpec, schema *models.SchemaRef, depth int) interface{} {
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Ref != "" {
+		name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+		definition, found := swaggerSpec.Definitions[name]
+		if !found && swaggerSpec.Components != nil {
+			definition, found = swaggerSpec.Components.Schemas[name]
+		}
+		if !found || depth > 5 {
+			return map[string]interface{}{}
+		}
+		obj := map[string]interface{}{}
+		for propName, prop := range definition.Properties {
+			if prop.Example != nil {
+				obj[propName] = prop.Example
+			} else {
+				obj[propName] = typedExample(prop.Type, prop.Format, prop.Enum)
+			}
+		}
+		return obj
+	}
+	switch schema.Type {
+	case "array":
+		if schema.Items != nil {
+			return []interface{}{schemaExample(swaggerSpec, schema.Items, depth+1)}
+		}
+		return []interface{}{}
+	case "object", "":
+		obj := map[string]interface{}{}
+		for propName, prop := range schema.Properties {
+			obj[propName] = schemaExample(swaggerSpec, prop, depth+1)
+		}
+		return obj
+	}
+	return typedExample(schema.Type, schema.Format, schema.Enum)
+}
+
+// formatExamples are realistic values of the common string formats.
+var formatExamples = map[string]string{
+	"date-time": "2024-01-15T09:30:00Z",
+	"date":      "2024-01-15",
+	"time":      "09:30:00",
+	"email":     "user@example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://example.com/resource",
+	"url":       "https://example.com/resource",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "ZXhhbXBsZQ==",
+	"password":  "********",
+}
+
+// typedExample returns the first enum value, or a value of the type and format.
+func typedExample(schemaType, format string, enum []interface{}) interface{} {
+	if len(enum) > 0 {
+		return enum[0]
+	}
+	switch schemaType {
+	case "string":
+		if example, ok := formatExamples[format]; ok {
+			return example
+		}
+	case "number":
+		return 1.5
+	case "integer":
+		return 1
+	}
+	return zeroValue(schemaType)
+}
+
+func zeroValue(schemaType string) interface{} {
+	switch schemaType {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		return []interface{}{}
+	case "object":
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// URL returns the base URL to point the MCP server at for a mock backend listening on addr.
+func URL(swaggerSpec models.SwaggerSpec, addr string) string {
+	host := addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	return fmt.Sprintf("http://%s%s", host, BasePath(swaggerSpec))
+}
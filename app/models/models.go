@@ -1,5 +1,13 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
 type Server struct {
 	URL         string `json:"url"`
 	Description string `json:"description,omitempty"`
@@ -7,9 +15,10 @@ type Server struct {
 
 type SwaggerSpec struct {
 	// Swagger 2.0 fields
-	Host     string `json:"host,omitempty"`
-	BasePath string `json:"basePath,omitempty"`
-	Swagger  string `json:"swagger,omitempty"`
+	Host     string   `json:"host,omitempty"`
+	BasePath string   `json:"basePath,omitempty"`
+	Swagger  string   `json:"swagger,omitempty"`
+	Consumes []string `json:"consumes,omitempty"` // Applies to operations without their own consumes
 
 	// OpenAPI 3.0 fields
 	OpenAPI    string      `json:"openapi,omitempty"`
@@ -17,40 +26,232 @@ type SwaggerSpec struct {
 	Components *Components `json:"components,omitempty"`
 
 	// Common fields
+	Info        *Info                          `json:"info,omitempty"`
+	Tags        []Tag                          `json:"tags,omitempty"`
 	Paths       map[string]map[string]Endpoint `json:"paths"`
 	Definitions map[string]Definition          `json:"definitions,omitempty"` // Swagger 2.0
+	Security    []SecurityRequirement          `json:"security,omitempty"`    // Applies to operations without their own security
+
+	SecurityDefinitions map[string]SecurityScheme `json:"securityDefinitions,omitempty"` // Swagger 2.0
+
+	// Document is the spec document as loaded, JSON or YAML, with its
+	// external refs inlined
+	Document []byte `json:"-"`
+}
+
+// SecurityRequirement maps security scheme names to their scopes. Any one
+// requirement of a list is enough.
+type SecurityRequirement map[string][]string
+
+type SecurityScheme struct {
+	Type   string `json:"type"`             // basic, apiKey, oauth2 (Swagger 2.0) or http, apiKey, oauth2, openIdConnect (OpenAPI 3.0)
+	Scheme string `json:"scheme,omitempty"` // OpenAPI 3.0 http scheme: basic or bearer
+	In     string `json:"in,omitempty"`     // apiKey location: header, query or cookie
+	Name   string `json:"name,omitempty"`   // apiKey parameter name
+}
+
+type Info struct {
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type Tag struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+}
+
+type ExternalDocs struct {
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
 }
 
 type Components struct {
-	Schemas map[string]Definition `json:"schemas,omitempty"` // OpenAPI 3.0
+	Schemas         map[string]Definition     `json:"schemas,omitempty"`         // OpenAPI 3.0
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"` // OpenAPI 3.0
+	Examples        map[string]interface{}    `json:"examples,omitempty"`        // OpenAPI 3.0, reusable named examples
 }
 
 type Definition struct {
 	Type       string              `json:"type"`
 	Properties map[string]Property `json:"properties"`
+	Required   []string            `json:"required,omitempty"`
+
+	// allOf composition, merged into Properties when the spec is parsed
+	Ref   string       `json:"$ref,omitempty"`
+	AllOf []Definition `json:"allOf,omitempty"`
+
+	// polymorphic schemas, one of the variants is sent
+	Title         string         `json:"title,omitempty"`
+	OneOf         []Definition   `json:"oneOf,omitempty"`
+	AnyOf         []Definition   `json:"anyOf,omitempty"`
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
+
+	// PropertyOrder keeps the property names in the order they appear in the spec
+	PropertyOrder []string `json:"-"`
+}
+
+func (d *Definition) UnmarshalJSON(data []byte) error {
+	type definition Definition
+	var def definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return err
+	}
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	order, err := objectKeys(raw.Properties)
+	if err != nil {
+		return err
+	}
+	def.PropertyOrder = order
+	*d = Definition(def)
+	return nil
+}
+
+// Merge adds the properties and required fields of other to d. Properties
+// both declare take the definition of other, keeping their position.
+func (d *Definition) Merge(other Definition) {
+	if d.Properties == nil {
+		d.Properties = map[string]Property{}
+	}
+	order := other.PropertyOrder
+	if len(order) != len(other.Properties) {
+		order = make([]string, 0, len(other.Properties))
+		for name := range other.Properties {
+			order = append(order, name)
+		}
+		sort.Strings(order)
+	}
+	for _, name := range order {
+		if _, exists := d.Properties[name]; !exists {
+			d.PropertyOrder = append(d.PropertyOrder, name)
+		}
+		d.Properties[name] = other.Properties[name]
+	}
+	for _, name := range other.Required {
+		if !slices.Contains(d.Required, name) {
+			d.Required = append(d.Required, name)
+		}
+	}
+	if d.Type == "" {
+		d.Type = other.Type
+	}
+}
+
+// objectKeys returns the keys of a JSON object in document order.
+func objectKeys(data json.RawMessage) ([]string, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected JSON object, got %v", tok)
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
 }
 
 type Property struct {
-	Type string `json:"type"`
+	Type        string        `json:"type"`
+	Title       string        `json:"title,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Example     interface{}   `json:"example,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`
+	Nullable    bool          `json:"nullable,omitempty"`   // OpenAPI 3.0
+	XNullable   bool          `json:"x-nullable,omitempty"` // Swagger 2.0 vendor extension
+
+	// nested schemas of object and array properties
+	Ref        string              `json:"$ref,omitempty"`
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
+	Items      *Property           `json:"items,omitempty"`
+	AllOf      []Property          `json:"allOf,omitempty"`
+}
+
+// IsNullable reports whether the property accepts an explicit null.
+func (p Property) IsNullable() bool {
+	return p.Nullable || p.XNullable
 }
 
 type Endpoint struct {
-	Summary     string              `json:"summary"`
-	Description string              `json:"description"`
-	Parameters  []Parameter         `json:"parameters"`
-	RequestBody *RequestBody         `json:"requestBody"`
-	Responses   map[string]Response `json:"responses"`
-	Consumes    []string            `json:"consumes"`
-	Produces    []string            `json:"produces"`
+	OperationID  string              `json:"operationId,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	Summary      string              `json:"summary"`
+	Description  string              `json:"description"`
+	Parameters   []Parameter         `json:"parameters"`
+	RequestBody  *RequestBody        `json:"requestBody"`
+	Responses    map[string]Response `json:"responses"`
+	Consumes     []string            `json:"consumes"`
+	Produces     []string            `json:"produces"`
+	Servers      []Server            `json:"servers,omitempty"` // OpenAPI 3.0 operation level servers
+	ExternalDocs *ExternalDocs       `json:"externalDocs,omitempty"`
+
+	// Security is nil when the operation does not declare it, and empty when it
+	// explicitly needs no authentication
+	Security []SecurityRequirement `json:"security,omitempty"`
+
+	// Gateway vendor extensions declaring the real backend
+	GoogleBackend     *GoogleBackend     `json:"x-google-backend,omitempty"`
+	AmazonIntegration *AmazonIntegration `json:"x-amazon-apigateway-integration,omitempty"`
+
+	// Calls of operations sharing a concurrency key are serialized, {name}
+	// references are replaced with the argument values
+	ConcurrencyKey string `json:"x-concurrency-key,omitempty"`
+}
+
+type GoogleBackend struct {
+	Address         string `json:"address"`
+	PathTranslation string `json:"path_translation,omitempty"`
+}
+
+type AmazonIntegration struct {
+	Type string `json:"type"`
+	Uri  string `json:"uri,omitempty"`
 }
 
 type Parameter struct {
-	Name        string     `json:"name"`
-	In          string     `json:"in"`
-	Required    bool       `json:"required"`
-	Type        string     `json:"type"`
-	Schema      *SchemaRef `json:"schema,omitempty"`
-	Description string     `json:"description"`
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Required    bool          `json:"required"`
+	Type        string        `json:"type"`
+	Schema      *SchemaRef    `json:"schema,omitempty"`
+	Description string        `json:"description"`
+	Format      string        `json:"format,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Example     interface{}   `json:"example,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+
+	// Swagger 2.0 array parameters: the item type and csv, ssv, tsv, pipes or multi
+	Items            *SchemaRef `json:"items,omitempty"`
+	CollectionFormat string     `json:"collectionFormat,omitempty"`
+
+	// OpenAPI 3.0 named examples, {"summary": ..., "value": ...} or a $ref to components/examples
+	Examples map[string]interface{} `json:"examples,omitempty"`
+
+	// OpenAPI 3.0 serialization: simple, label or matrix for path parameters
+	Style         string `json:"style,omitempty"`
+	AllowReserved bool   `json:"allowReserved,omitempty"`
+	Explode       *bool  `json:"explode,omitempty"` // repeat array query parameters, the default of form style
 }
 
 type RequestBody struct {
@@ -66,9 +267,11 @@ type MediaType struct {
 }
 
 type Response struct {
-	Description string     `json:"description"`
-	Schema      *SchemaRef `json:"schema,omitempty"`
-	Type        string     `json:"type,omitempty"`
+	Description string                 `json:"description"`
+	Schema      *SchemaRef             `json:"schema,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Examples    map[string]interface{} `json:"examples,omitempty"` // Swagger 2.0, by mime type
+	Content     map[string]MediaType   `json:"content,omitempty"`  // OpenAPI 3.0
 }
 
 type SchemaRef struct {
@@ -78,8 +281,35 @@ type SchemaRef struct {
 	Required    []string              `json:"required,omitempty"`
 	Items       *SchemaRef            `json:"items,omitempty"`
 	Ref         string                `json:"$ref,omitempty"`
+	Title       string                `json:"title,omitempty"`
 	Description string                `json:"description,omitempty"`
 	Example     interface{}           `json:"example,omitempty"`
+	Default     interface{}           `json:"default,omitempty"`
+	Enum        []interface{}         `json:"enum,omitempty"`
+	Nullable    bool                  `json:"nullable,omitempty"`
+	AllOf       []*SchemaRef          `json:"allOf,omitempty"`
+
+	OneOf         []*SchemaRef   `json:"oneOf,omitempty"`
+	AnyOf         []*SchemaRef   `json:"anyOf,omitempty"`
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
+}
+
+// Discriminator names the property telling the variants of a polymorphic schema
+// apart, and optionally maps its values to the variant schemas.
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+// UnmarshalJSON also accepts the Swagger 2.0 form, the bare property name.
+func (d *Discriminator) UnmarshalJSON(data []byte) error {
+	var propertyName string
+	if err := json.Unmarshal(data, &propertyName); err == nil {
+		d.PropertyName = propertyName
+		return nil
+	}
+	type discriminator Discriminator
+	return json.Unmarshal(data, (*discriminator)(d))
 }
 
 // SseConfig stores SSE (Server-Sent Events) related parameters
@@ -87,21 +317,99 @@ type SseConfig struct {
 	SseMode bool   `json:"sseMode"` // Whether to run in SSE mode
 	SseAddr string `json:"sseAddr"` // SSE server listen address
 	SseUrl  string `json:"sseUrl"`  // Base URL for the SSE server
+
+	SplitScopes bool   `json:"splitScopes"` // Serve read-only tools on /mcp/read and mutating tools on /mcp/write
+	AdminToken  string `json:"adminToken"`  // Bearer token of the /admin/tools API switching tools on and off, disabled when empty
+	ClientKeys  string `json:"clientKeys"`  // File of the hashed per-client keys SSE requests must carry, open access when empty
 }
 
 // ApiConfig stores API related parameters
 type ApiConfig struct {
-	BaseUrl        string `json:"baseUrl"`        // Base URL for API requests
-	IncludePaths   string `json:"includePaths"`   // List of paths or regex patterns to include
-	ExcludePaths   string `json:"excludePaths"`   // List of paths or regex patterns to exclude
-	IncludeMethods string `json:"includeMethods"` // List of HTTP methods to include
-	ExcludeMethods string `json:"excludeMethods"` // List of HTTP methods to exclude
-	Security       string `json:"security"`       // API security type
-	BasicAuth      string `json:"basicAuth"`      // Basic auth credentials
-	ApiKeyAuth     string `json:"apiKeyAuth"`     // API key authentication information
-	BearerAuth     string `json:"bearerAuth"`     // Bearer token
-	SseHeaders     string `json:"sseHeaders"`     // Read headers from sse request, and pass to API request (format: name1,name2)
-	Headers        string `json:"headers"`        // Additional headers to include in requests (format: name1=value1,name2=value2)
+	BaseUrl            string `json:"baseUrl"`            // Base URL for API requests
+	IncludePaths       string `json:"includePaths"`       // List of paths or regex patterns to include
+	ExcludePaths       string `json:"excludePaths"`       // List of paths or regex patterns to exclude
+	IncludeMethods     string `json:"includeMethods"`     // List of HTTP methods to include
+	ExcludeMethods     string `json:"excludeMethods"`     // List of HTTP methods to exclude
+	IncludeOperations  string `json:"includeOperations"`  // List of operationId regex patterns to include
+	ExcludeOperations  string `json:"excludeOperations"`  // List of operationId regex patterns to exclude
+	IncludeTools       string `json:"includeTools"`       // Exact tool names to always include, regardless of the path and method filters
+	ExcludeTools       string `json:"excludeTools"`       // Exact tool names to always exclude
+	Security           string `json:"security"`           // API security type, or comma-separated types applied together (e.g. apiKey,bearer)
+	BasicAuth          string `json:"basicAuth"`          // Basic auth credentials
+	ApiKeyAuth         string `json:"apiKeyAuth"`         // API key authentication information
+	BearerAuth         string `json:"bearerAuth"`         // Bearer token
+	NegotiateSpn       string `json:"negotiateSpn"`       // Service principal for negotiate auth, derived from the host when empty
+	NtlmAuth           string `json:"ntlmAuth"`           // NTLM credentials in DOMAIN\user:password format
+	SseHeaders         string `json:"sseHeaders"`         // Read headers from sse request, and pass to API request (format: name1,name2)
+	Headers            string `json:"headers"`            // Additional headers to include in requests (format: name1=value1,name2=value2)
+	OrderedBody        bool   `json:"orderedBody"`        // Marshal request body fields in schema property order
+	OmitEmptyBody      bool   `json:"omitEmptyBody"`      // Omit empty or null optional body fields instead of sending them
+	VendorBackends     bool   `json:"vendorBackends"`     // Send requests to the backend declared by x-google-backend or x-amazon-apigateway-integration
+	HistoryDb          string `json:"historyDb"`          // Bolt database file recording tool results for the query_history tool
+	SessionVariables   bool   `json:"sessionVariables"`   // Add the set_variable and get_variable tools, values are referenced as {{name}} in arguments
+	CaptureRules       string `json:"captureRules"`       // Values saved from tool results (format: [tool:]$.path=name, comma separated)
+	Scope              string `json:"scope,omitempty"`    // "read" only exposes GET/HEAD/OPTIONS operations, "write" only the mutating ones
+	MaxCalls           int    `json:"maxCalls"`           // Maximum tool calls per MCP session, 0 for unlimited
+	MaxMutatingCalls   int    `json:"maxMutatingCalls"`   // Maximum POST/PUT/PATCH/DELETE tool calls per MCP session, 0 for unlimited
+	ScrubResponses     bool   `json:"scrubResponses"`     // Remove prompt injection attempts such as "ignore previous instructions" from responses
+	ScrubPatterns      string `json:"scrubPatterns"`      // Extra comma-separated regexes removed from responses, on top of the defaults
+	EtagCache          bool   `json:"etagCache"`          // Cache GET responses carrying ETag/Last-Modified and revalidate them with conditional requests
+	DownloadRoots      string `json:"downloadRoots"`      // Directories or file:// roots binary and large responses are written to
+	DownloadThreshold  int    `json:"downloadThreshold"`  // Responses larger than this many bytes are written to a root too, 0 for binary only
+	HeaderCaptures     string `json:"headerCaptures"`     // Response headers copied into results or variables (format: [pathRegex:]Header[=variable], comma separated)
+	ParamAliases       string `json:"paramAliases"`       // Tool argument names for spec parameters (format: wireName=argument, comma separated)
+	ProgressInterval   int    `json:"progressInterval"`   // Seconds between progress notifications of slow tool calls, 0 disables them
+	ToolManifest       string `json:"toolManifest"`       // Approved tool manifest, tools missing from it or whose definition changed are not registered
+	ManifestKey        string `json:"manifestKey"`        // HMAC key the tool manifest is signed with
+	ApiVersion         string `json:"apiVersion"`         // Only expose operations of this version path segment (e.g. v2), unversioned paths are kept
+	VersionedTools     bool   `json:"versionedTools"`     // Suffix tool names with the version path segment and link the other versions of each operation
+	ExistenceCheck     bool   `json:"existenceCheck"`     // GET the resource before a DELETE or PUT and fail when it does not exist
+	DerivedFields      string `json:"derivedFields"`      // Fields computed from JSON responses (format: [tool:]name=expression, comma separated)
+	ValidateBody       bool   `json:"validateBody"`       // Check request bodies against the schema types, required fields, enums and patterns before sending
+	SensitiveParams    string `json:"sensitiveParams"`    // Comma-separated parameter and body field names holding secrets, on top of those of format password
+	PollingDiff        bool   `json:"pollingDiff"`        // Answer repeated identical GET calls with "unchanged" or the changed fields instead of the whole result
+	DocResources       bool   `json:"docResources"`       // Serve the documentation of each operation, with example responses, as an MCP resource
+	Playbooks          string `json:"playbooks"`          // Directory of <tag>.md playbooks served as MCP prompts and resources
+	LatencySlo         int    `json:"latencySlo"`         // p95 latency target of the tools in milliseconds, results warn when it is exceeded, 0 disables it
+	UrlRewrites        string `json:"urlRewrites"`        // Regex rewrites of the request URL applied in order (format: pattern=>replacement, semicolon separated)
+	ElevatedTools      string `json:"elevatedTools"`      // Comma-separated regexes of tool names hidden from sessions unless an admin grants them access for a limited time
+	SerializeCalls     string `json:"serializeCalls"`     // Operations whose calls run one at a time per key (format: [METHOD ]path[=key], comma separated)
+	Provenance         bool   `json:"provenance"`         // Attach the source URL, time, status, cache use and spec version to results
+	RequestTimeout     int    `json:"requestTimeout"`     // Seconds a call may take, a response body cut short is returned as partial, 0 disables it
+	MetricsAddr        string `json:"metricsAddr"`        // Listen address of the OpenMetrics /metrics endpoint counting tool calls, disabled when empty
+	MaintenanceWindows string `json:"maintenanceWindows"` // Freeze periods refusing mutating tools (format: [CRON_TZ=zone] cron duration, semicolon separated)
+	MaxBodySize        string `json:"maxBodySize"`        // Largest request body sent by any tool (e.g. 512KB, 10MB), no limit when empty
+	BodySizeLimits     string `json:"bodySizeLimits"`     // Per operation body size limits overriding maxBodySize (format: [METHOD ]path=size, comma separated, 0 for no limit)
+	StatsFile          string `json:"statsFile"`          // Opt-in local file aggregating tool call counts, outcomes and spec size, never payloads
+	Timezone           string `json:"timezone"`           // IANA timezone of date and date-time arguments given without an offset, UTC when empty
+	OperationIdNames   bool   `json:"operationIdNames"`   // Name tools after the operationId of their operation, method_path when it has none
+	FailoverUrls       string `json:"failoverUrls"`       // Base URLs tried in order when the base URL is unreachable (comma separated), or servers for the other servers of the spec
+	RequestLog         int    `json:"requestLog"`         // Number of recent requests and responses served, redacted, as the swagger-mcp://requests resource, 0 disables it
+	ToolNameTemplate   string `json:"toolNameTemplate"`   // Go template of the tool names, e.g. {{.Tag}}_{{.OperationId}}, over Method, Path, OperationId, Tag, Version and Default
+	ExamplePrompts     bool   `json:"examplePrompts"`     // Serve an example_<tool> prompt pre-filling the arguments of the tools from their request body examples
+	SpecResources      bool   `json:"specResources"`      // Serve the spec document and the parsed spec as the swagger-mcp://spec resources
+	ContentHash        bool   `json:"contentHash"`        // Add the hash of the response to results and the watch_endpoint tool notifying when it changes
+	SchemaResources    bool   `json:"schemaResources"`    // Serve each schema of the spec as a swagger-mcp://schemas/<name> resource, referenced from the tool descriptions
+
+	Routes []RouteConfig `json:"routes,omitempty"` // Per path prefix or tag base URL and credential overrides
+
+	CsrfTokenUrl  string `json:"csrfTokenUrl"`  // Endpoint returning the anti-CSRF token, absolute or relative to the base URL
+	CsrfCookie    string `json:"csrfCookie"`    // Cookie holding the anti-CSRF token
+	CsrfHeader    string `json:"csrfHeader"`    // Header the token is sent in on mutating calls (default X-CSRF-Token)
+	CsrfBodyField string `json:"csrfBodyField"` // Body field the token is also sent in on mutating calls
+}
+
+// RouteConfig routes operations matching a path prefix or tag to their own base URL and credentials
+type RouteConfig struct {
+	PathPrefix  string `json:"pathPrefix,omitempty"`  // Path prefix the operation path must start with
+	Tag         string `json:"tag,omitempty"`         // Tag the operation must carry
+	BaseUrl     string `json:"baseUrl"`               // Base URL for matching operations
+	UrlRewrites string `json:"urlRewrites,omitempty"` // URL rewrites replacing the global ones for matching operations
+	Security    string `json:"security,omitempty"`    // API security types, comma separated, override the global ones when set
+	BasicAuth   string `json:"basicAuth,omitempty"`   // Basic auth credentials
+	ApiKeyAuth  string `json:"apiKeyAuth,omitempty"`  // API key authentication information
+	BearerAuth  string `json:"bearerAuth,omitempty"`  // Bearer token
+	NtlmAuth    string `json:"ntlmAuth,omitempty"`    // NTLM credentials
 }
 
 // Config stores all command line parameters
@@ -0,0 +1,15 @@
+package models
+
+import _ "embed"
+
+// RoutesSchema is the JSON Schema of the routes file, the format of RouteConfig.
+//
+//go:embed routes.schema.json
+var RoutesSchema []byte
+
+// ValidateRoutes checks a routes file against RoutesSchema: unknown keys, values
+// of the wrong type, routes that never match and credentials that do not go
+// with the route's security type are reported with their line and column.
+func ValidateRoutes(data []byte) ([]string, error) {
+	return ValidateJSON(data, RoutesSchema)
+}
@@ -0,0 +1,303 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsonNode is a parsed JSON value that remembers where it starts in the
+// document, and where the keys of an object start, to locate problems.
+type jsonNode struct {
+	kind   string // object, array, string, number, boolean or null
+	value  interface{}
+	offset int64
+	fields map[string]*jsonNode
+	keys   map[string]int64
+	items  []*jsonNode
+}
+
+// skipSeparators moves offset past the whitespace, commas and colons
+// json.Decoder reports a token to start after.
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func parseNode(decoder *json.Decoder, data []byte) (*jsonNode, error) {
+	node := &jsonNode{offset: skipSeparators(data, decoder.InputOffset())}
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			node.kind, node.fields, node.keys = "object", map[string]*jsonNode{}, map[string]int64{}
+			for decoder.More() {
+				keyOffset := skipSeparators(data, decoder.InputOffset())
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				field, err := parseNode(decoder, data)
+				if err != nil {
+					return nil, err
+				}
+				node.fields[key.(string)] = field
+				node.keys[key.(string)] = keyOffset
+			}
+		} else {
+			node.kind = "array"
+			for decoder.More() {
+				item, err := parseNode(decoder, data)
+				if err != nil {
+					return nil, err
+				}
+				node.items = append(node.items, item)
+			}
+		}
+		// the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.kind, node.value = "string", value
+	case json.Number:
+		node.kind, node.value = "number", value
+	case bool:
+		node.kind, node.value = "boolean", value
+	case nil:
+		node.kind = "null"
+	}
+	return node, nil
+}
+
+// schemaProblem is a value that does not match the schema.
+type schemaProblem struct {
+	offset  int64
+	path    string
+	message string
+}
+
+// ValidateJSON checks a JSON document against a JSON Schema and returns one
+// message per problem, located by line, column and path, e.g.
+// "line 3, column 5: [0].bearerToken: unknown key, did you mean bearerAuth?".
+// It supports the keywords the schemas of this program use: type, enum,
+// const, pattern, properties, additionalProperties, required, items, anyOf,
+// allOf, if/then and dependentSchemas, plus errorMessage replacing the
+// message of a value failing type, const, enum or pattern.
+func ValidateJSON(data []byte, schemaData []byte) ([]string, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	root, err := parseNode(decoder, data)
+	if err == nil && decoder.More() {
+		err = fmt.Errorf("unexpected data after the document")
+	}
+	if err != nil {
+		offset := decoder.InputOffset()
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			offset = syntaxErr.Offset
+		}
+		line, column := position(data, offset)
+		return []string{fmt.Sprintf("line %d, column %d: %v", line, column, err)}, nil
+	}
+	problems := checkSchema(schema, root, "")
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].offset < problems[j].offset })
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		line, column := position(data, problem.offset)
+		path := problem.path
+		if path == "" {
+			path = "(root)"
+		}
+		messages[i] = fmt.Sprintf("line %d, column %d: %s: %s", line, column, strings.TrimPrefix(path, "."), problem.message)
+	}
+	return messages, nil
+}
+
+// position returns the 1-based line and column of offset in data.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+func checkSchema(schema map[string]interface{}, node *jsonNode, path string) []schemaProblem {
+	problem := func(message string, args ...interface{}) []schemaProblem {
+		return []schemaProblem{{offset: node.offset, path: path, message: fmt.Sprintf(message, args...)}}
+	}
+	// invalid reports a value failing one of the value keywords
+	invalid := func(message string, args ...interface{}) []schemaProblem {
+		if errorMessage, ok := schema["errorMessage"].(string); ok {
+			return problem("%s", errorMessage)
+		}
+		return problem(message, args...)
+	}
+	if schemaType, ok := schema["type"].(string); ok && !hasJSONType(node, schemaType) {
+		return invalid("expected %s, got %s", schemaType, node.kind)
+	}
+	if constant, ok := schema["const"]; ok && !sameJSONValue(node, constant) {
+		return invalid("must be %v, got %v", constant, node.value)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsJSONValue(enum, node) {
+		allowed := make([]string, len(enum))
+		for i, value := range enum {
+			allowed[i] = fmt.Sprint(value)
+		}
+		return invalid("%v is not one of %s", node.value, strings.Join(allowed, ", "))
+	}
+	if pattern, ok := schema["pattern"].(string); ok && node.kind == "string" {
+		if matched, err := regexp.MatchString(pattern, node.value.(string)); err == nil && !matched {
+			return invalid("%q does not match %s", node.value, pattern)
+		}
+	}
+
+	problems := []schemaProblem{}
+	if node.kind == "object" {
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(node.fields))
+		for key := range node.fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propSchema, known := properties[key].(map[string]interface{})
+			if known {
+				problems = append(problems, checkSchema(propSchema, node.fields[key], path+"."+key)...)
+				continue
+			}
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				message := "unknown key"
+				for name := range properties {
+					if strings.EqualFold(strings.ReplaceAll(name, "_", ""), strings.ReplaceAll(key, "_", "")) {
+						message += ", did you mean " + name + "?"
+					}
+				}
+				problems = append(problems, schemaProblem{offset: node.keys[key], path: path + "." + key, message: message})
+			}
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := node.fields[fmt.Sprint(name)]; !ok {
+				problems = append(problems, problem("missing %s", name)...)
+			}
+		}
+		dependents, _ := schema["dependentSchemas"].(map[string]interface{})
+		for _, key := range keys {
+			if dependent, ok := dependents[key].(map[string]interface{}); ok {
+				for _, found := range checkSchema(dependent, node, path) {
+					found.message += fmt.Sprintf(" (because %s is set)", key)
+					problems = append(problems, found)
+				}
+			}
+		}
+	}
+	if itemSchema, ok := schema["items"].(map[string]interface{}); ok && node.kind == "array" {
+		for i, item := range node.items {
+			problems = append(problems, checkSchema(itemSchema, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, part := range allOf {
+			if partSchema, ok := part.(map[string]interface{}); ok {
+				problems = append(problems, checkSchema(partSchema, node, path)...)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && len(anyOf) > 0 {
+		failures := []string{}
+		for _, part := range anyOf {
+			partSchema, _ := part.(map[string]interface{})
+			found := checkSchema(partSchema, node, path)
+			if len(found) == 0 {
+				failures = nil
+				break
+			}
+			failures = append(failures, found[0].message)
+		}
+		if failures != nil {
+			if description, ok := schema["description"].(string); ok {
+				problems = append(problems, problem("%s", description)...)
+			} else {
+				problems = append(problems, problem("matches none of: %s", strings.Join(failures, "; "))...)
+			}
+		}
+	}
+	if condition, ok := schema["if"].(map[string]interface{}); ok && len(checkSchema(condition, node, path)) == 0 {
+		if then, ok := schema["then"].(map[string]interface{}); ok {
+			for _, found := range checkSchema(then, node, path) {
+				found.message += conditionText(condition)
+				problems = append(problems, found)
+			}
+		}
+	}
+	return problems
+}
+
+// conditionText describes what an if schema tests for: its description, or
+// the constant properties it checks.
+func conditionText(condition map[string]interface{}) string {
+	if description, ok := condition["description"].(string); ok {
+		return " (because " + description + ")"
+	}
+	properties, _ := condition["properties"].(map[string]interface{})
+	parts := []string{}
+	for name, prop := range properties {
+		if propSchema, ok := prop.(map[string]interface{}); ok {
+			if constant, ok := propSchema["const"]; ok {
+				parts = append(parts, fmt.Sprintf("%s is %v", name, constant))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sort.Strings(parts)
+	return " (because " + strings.Join(parts, " and ") + ")"
+}
+
+func hasJSONType(node *jsonNode, schemaType string) bool {
+	if schemaType == "integer" {
+		number, ok := node.value.(json.Number)
+		_, err := number.Int64()
+		return ok && err == nil
+	}
+	return node.kind == schemaType
+}
+
+func sameJSONValue(node *jsonNode, value interface{}) bool {
+	switch node.kind {
+	case "string", "boolean":
+		return node.value == value
+	case "number":
+		expected, ok := value.(float64)
+		actual, err := node.value.(json.Number).Float64()
+		return ok && err == nil && actual == expected
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func containsJSONValue(values []interface{}, node *jsonNode) bool {
+	for _, value := range values {
+		if sameJSONValue(node, value) {
+			return true
+		}
+	}
+	return false
+}
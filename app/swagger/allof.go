@@ -0,0 +1,62 @@
+package swagger
+
+import (
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// maxAllOfDepth bounds the chains of schemas composed from other composed schemas.
+const maxAllOfDepth = 10
+
+// mergeAllOf replaces the named schemas composed with allOf by a schema holding
+// the properties and required fields of all their parts, so the tool arguments
+// of a body include the inherited properties.
+func mergeAllOf(swaggerSpec models.SwaggerSpec) {
+	schemas := []map[string]models.Definition{swaggerSpec.Definitions}
+	if swaggerSpec.Components != nil {
+		schemas = append(schemas, swaggerSpec.Components.Schemas)
+	}
+	lookup := func(ref string) (models.Definition, bool) {
+		name := ExtractSchemaName(ref, "")
+		for _, named := range schemas {
+			if definition, found := named[name]; found {
+				return definition, true
+			}
+		}
+		return models.Definition{}, false
+	}
+	for _, named := range schemas {
+		merged := map[string]models.Definition{}
+		for name, definition := range named {
+			if len(definition.AllOf) > 0 {
+				merged[name] = flattenAllOf(definition, lookup, 0)
+			}
+		}
+		// assign after the loop, the other schemas still read the originals
+		for name, definition := range merged {
+			named[name] = definition
+		}
+	}
+}
+
+// flattenAllOf merges the allOf parts of definition, then its own properties.
+func flattenAllOf(definition models.Definition, lookup func(ref string) (models.Definition, bool), depth int) models.Definition {
+	if definition.Ref != "" {
+		target, found := lookup(definition.Ref)
+		if !found || depth > maxAllOfDepth {
+			return models.Definition{Type: "object"}
+		}
+		definition = target
+	}
+	if len(definition.AllOf) == 0 || depth > maxAllOfDepth {
+		return definition
+	}
+	merged := models.Definition{Title: definition.Title, OneOf: definition.OneOf, AnyOf: definition.AnyOf, Discriminator: definition.Discriminator}
+	for _, part := range definition.AllOf {
+		merged.Merge(flattenAllOf(part, lookup, depth+1))
+	}
+	merged.Merge(definition)
+	if merged.Type == "" {
+		merged.Type = "object"
+	}
+	return merged
+}
@@ -0,0 +1,185 @@
+package swagger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// specCacheFormat changes whenever parsing produces a different spec from the
+// same document, so entries written by an older version are not reused.
+const specCacheFormat = 1
+
+var (
+	specCacheMu  sync.RWMutex
+	specCacheDir string
+)
+
+// cachedSpec is a parsed spec as stored in the cache. What its JSON loses is
+// kept next to it: the property order of the definitions, and where security
+// is declared empty, which omitempty would turn into not declared.
+type cachedSpec struct {
+	Spec           models.SwaggerSpec  `json:"spec"`
+	PropertyOrders map[string][]string `json:"propertyOrders,omitempty"`
+	NoSecurity     []string            `json:"noSecurity,omitempty"`
+}
+
+// SetSpecCache makes the built-in providers keep the specs they parse in dir,
+// keyed by the hash of the document, so the next start with the same document
+// skips the conversion, the OpenAPI 3.1 downgrade and the allOf and example
+// resolution. The document and its external refs are still fetched to be
+// hashed, and the tools still built from the spec. An empty dir turns the
+// cache off.
+func SetSpecCache(dir string) {
+	specCacheMu.Lock()
+	defer specCacheMu.Unlock()
+	specCacheDir = dir
+}
+
+// parseCached parses body with ParseSwagger, or reads the result of an
+// earlier parse of the same document from the spec cache.
+func parseCached(body []byte) (models.SwaggerSpec, error) {
+	specCacheMu.RLock()
+	dir := specCacheDir
+	specCacheMu.RUnlock()
+	if dir == "" {
+		return ParseSwagger(body)
+	}
+	path := filepath.Join(dir, specCacheKey(body)+".json")
+	if data, err := os.ReadFile(path); err == nil {
+		var cached cachedSpec
+		if err := json.Unmarshal(data, &cached); err == nil {
+			restorePropertyOrders(cached.Spec, cached.PropertyOrders)
+			restoreNoSecurity(&cached.Spec, cached.NoSecurity)
+			cached.Spec.Document = body
+			return cached.Spec, nil
+		}
+		log.Printf("Warning: ignoring unreadable spec cache entry %s", path)
+	}
+	spec, err := ParseSwagger(body)
+	if err != nil {
+		return spec, err
+	}
+	if err := writeSpecCache(path, spec); err != nil {
+		log.Printf("Warning: failed to write spec cache: %v", err)
+	}
+	return spec, nil
+}
+
+// specCacheKey hashes the document along with the cache format.
+func specCacheKey(body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(strconv.Itoa(specCacheFormat) + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// writeSpecCache stores spec at path, through a temporary file so a
+// concurrent start never reads half an entry.
+func writeSpecCache(path string, spec models.SwaggerSpec) error {
+	data, err := json.Marshal(cachedSpec{Spec: spec, PropertyOrders: propertyOrders(spec), NoSecurity: noSecurity(spec)})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".spec-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// propertyOrders returns the property order of every definition of spec, by
+// its location.
+func propertyOrders(spec models.SwaggerSpec) map[string][]string {
+	orders := map[string][]string{}
+	walkSpecDefinitions(spec, func(key string, definition *models.Definition) {
+		if len(definition.PropertyOrder) > 0 {
+			orders[key] = definition.PropertyOrder
+		}
+	})
+	return orders
+}
+
+// restorePropertyOrders sets the property orders propertyOrders returned.
+func restorePropertyOrders(spec models.SwaggerSpec, orders map[string][]string) {
+	walkSpecDefinitions(spec, func(key string, definition *models.Definition) {
+		definition.PropertyOrder = orders[key]
+	})
+}
+
+// noSecurity lists where spec declares an empty security requirement list:
+// "" for the whole spec, "method path" for an operation.
+func noSecurity(spec models.SwaggerSpec) []string {
+	keys := []string{}
+	if spec.Security != nil && len(spec.Security) == 0 {
+		keys = append(keys, "")
+	}
+	for path, methods := range spec.Paths {
+		for method, details := range methods {
+			if details.Security != nil && len(details.Security) == 0 {
+				keys = append(keys, method+" "+path)
+			}
+		}
+	}
+	return keys
+}
+
+// restoreNoSecurity empties the security requirement lists noSecurity listed.
+func restoreNoSecurity(spec *models.SwaggerSpec, keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			spec.Security = []models.SecurityRequirement{}
+			continue
+		}
+		method, path, _ := strings.Cut(key, " ")
+		if details, ok := spec.Paths[path][method]; ok {
+			details.Security = []models.SecurityRequirement{}
+			spec.Paths[path][method] = details
+		}
+	}
+}
+
+// walkSpecDefinitions calls visit with the definitions of spec and the
+// schemas they are composed of.
+func walkSpecDefinitions(spec models.SwaggerSpec, visit func(key string, definition *models.Definition)) {
+	walkDefinitions(spec.Definitions, "definitions/", visit)
+	if spec.Components != nil {
+		walkDefinitions(spec.Components.Schemas, "components/", visit)
+	}
+}
+
+func walkDefinitions(definitions map[string]models.Definition, prefix string, visit func(key string, definition *models.Definition)) {
+	for name, definition := range definitions {
+		walkDefinition(&definition, prefix+name, visit)
+		definitions[name] = definition
+	}
+}
+
+func walkDefinition(definition *models.Definition, key string, visit func(key string, definition *models.Definition)) {
+	visit(key, definition)
+	for kind, parts := range map[string][]models.Definition{"allOf": definition.AllOf, "oneOf": definition.OneOf, "anyOf": definition.AnyOf} {
+		for i := range parts {
+			walkDefinition(&parts[i], fmt.Sprintf("%s/%s/%d", key, kind, i), visit)
+		}
+	}
+}
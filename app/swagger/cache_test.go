@@ -0,0 +1,96 @@
+package swagger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const cacheTestDocument = `{
+	"swagger": "2.0",
+	"host": "api.example.com",
+	"security": [],
+	"paths": {
+		"/users": {
+			"post": {
+				"security": [],
+				"parameters": [{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/User"}}],
+				"responses": {"200": {"description": "ok"}}
+			},
+			"get": {"responses": {"200": {"description": "ok"}}}
+		}
+	},
+	"definitions": {
+		"User": {
+			"type": "object",
+			"properties": {"zip": {"type": "string"}, "name": {"type": "string"}, "age": {"type": "integer"}}
+		},
+		"Admin": {
+			"allOf": [{"$ref": "#/definitions/User"}, {"type": "object", "properties": {"role": {"type": "string"}, "level": {"type": "integer"}}}]
+		}
+	}
+}`
+
+func TestSpecCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	SetSpecCache(dir)
+	t.Cleanup(func() { SetSpecCache("") })
+	body := []byte(cacheTestDocument)
+
+	want, err := ParseSwagger(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parseCached(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, specCacheKey(body)+".json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("no cache entry was written: %v", err)
+	}
+	cached, err := parseCached(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, spec := range map[string]interface{}{"parsed": parsed, "cached": cached} {
+		if !reflect.DeepEqual(spec, want) {
+			t.Errorf("the %s spec differs from ParseSwagger:\n%#v\nwant\n%#v", name, spec, want)
+		}
+	}
+	if got := cached.Definitions["User"].PropertyOrder; !reflect.DeepEqual(got, []string{"zip", "name", "age"}) {
+		t.Errorf("User property order = %v", got)
+	}
+	if cached.Security == nil || len(cached.Security) != 0 {
+		t.Errorf("spec security = %#v, want declared empty", cached.Security)
+	}
+	if post := cached.Paths["/users"]["post"]; post.Security == nil || len(post.Security) != 0 {
+		t.Errorf("post security = %#v, want declared empty", post.Security)
+	}
+	if get := cached.Paths["/users"]["get"]; get.Security != nil {
+		t.Errorf("get security = %#v, want not declared", get.Security)
+	}
+}
+
+func TestSpecCacheUnreadableEntry(t *testing.T) {
+	dir := t.TempDir()
+	SetSpecCache(dir)
+	t.Cleanup(func() { SetSpecCache("") })
+	body := []byte(cacheTestDocument)
+	path := filepath.Join(dir, specCacheKey(body)+".json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := parseCached(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Paths["/users"]["get"].Responses == nil {
+		t.Errorf("the document was not parsed: %#v", spec.Paths)
+	}
+	if _, err := parseCached(body); err != nil {
+		t.Fatalf("the rewritten entry is unreadable: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package swagger
+
+import (
+	"strings"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+const exampleRefPrefix = "#/components/examples/"
+
+// resolveExampleRefs replaces the named examples of parameters, request bodies
+// and responses that point into components/examples with the example itself.
+// A summary or description next to the $ref takes precedence over the
+// component's own.
+func resolveExampleRefs(swaggerSpec models.SwaggerSpec) {
+	if swaggerSpec.Components == nil || len(swaggerSpec.Components.Examples) == 0 {
+		return
+	}
+	for _, methods := range swaggerSpec.Paths {
+		for _, details := range methods {
+			for _, param := range details.Parameters {
+				resolveNamedExamples(swaggerSpec, param.Examples)
+			}
+			if details.RequestBody != nil {
+				for _, mediaType := range details.RequestBody.Content {
+					resolveNamedExamples(swaggerSpec, mediaType.Examples)
+				}
+			}
+			for _, resp := range details.Responses {
+				for _, mediaType := range resp.Content {
+					resolveNamedExamples(swaggerSpec, mediaType.Examples)
+				}
+			}
+		}
+	}
+}
+
+func resolveNamedExamples(swaggerSpec models.SwaggerSpec, examples map[string]interface{}) {
+	for name, named := range examples {
+		if resolved, ok := resolveExample(swaggerSpec, named, 0); ok {
+			examples[name] = resolved
+		}
+	}
+}
+
+// resolveExample follows the $ref of a named example, components may point to
+// other components.
+func resolveExample(swaggerSpec models.SwaggerSpec, named interface{}, depth int) (map[string]interface{}, bool) {
+	example, ok := named.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	ref, _ := example["$ref"].(string)
+	if !strings.HasPrefix(ref, exampleRefPrefix) || depth > 5 {
+		return nil, false
+	}
+	target, found := swaggerSpec.Components.Examples[strings.TrimPrefix(ref, exampleRefPrefix)]
+	if !found {
+		return nil, false
+	}
+	if next, ok := resolveExample(swaggerSpec, target, depth+1); ok {
+		target = next
+	}
+	component, ok := target.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	resolved := make(map[string]interface{}, len(component))
+	for key, value := range component {
+		resolved[key] = value
+	}
+	for _, key := range []string{"summary", "description"} {
+		if value, ok := example[key]; ok {
+			resolved[key] = value
+		}
+	}
+	return resolved, true
+}
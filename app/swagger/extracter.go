@@ -15,6 +15,19 @@ func ExtractSchemaName(ref, schemaType string) string {
 	return schemaType
 }
 
+// namedSchema returns the schema of a name from the Swagger 2.0 definitions or
+// the OpenAPI 3.0 components, which a spec inlining all its schemas may omit.
+func namedSchema(swaggerSpec models.SwaggerSpec, name string) (models.Definition, bool) {
+	if definition, found := swaggerSpec.Definitions[name]; found {
+		return definition, true
+	}
+	if swaggerSpec.Components != nil {
+		definition, found := swaggerSpec.Components.Schemas[name]
+		return definition, found
+	}
+	return models.Definition{}, false
+}
+
 func getBaseURL(swaggerSpec models.SwaggerSpec) string {
 	// For OpenAPI 3.0
 	if swaggerSpec.OpenAPI != "" && len(swaggerSpec.Servers) > 0 {
@@ -50,6 +63,13 @@ func ExtractSwagger(swaggerSpec models.SwaggerSpec) {
 				}
 			}
 
+			fmt.Println("\nCookies:")
+			for _, param := range details.Parameters {
+				if param.In == "cookie" {
+					fmt.Printf("  - %s (Required: %t)\n", param.Name, param.Required)
+				}
+			}
+
 			fmt.Println("\nPath Parameters:")
 			for _, param := range details.Parameters {
 				if param.In == "path" {
@@ -62,10 +82,10 @@ func ExtractSwagger(swaggerSpec models.SwaggerSpec) {
 
 			fmt.Println("\nRequest Body:")
 			for _, param := range details.Parameters {
-				if param.In == "body" {
+				if param.In == "body" && param.Schema != nil {
 					schemaName := ExtractSchemaName(param.Schema.Ref, param.Type)
 					fmt.Printf("  Schema: %s\n", schemaName)
-					if definition, found := swaggerSpec.Components.Schemas[schemaName]; found {
+					if definition, found := namedSchema(swaggerSpec, schemaName); found {
 						for propName, prop := range definition.Properties {
 							fmt.Printf("    - %s: %s\n", propName, prop.Type)
 						}
@@ -79,17 +99,21 @@ func ExtractSwagger(swaggerSpec models.SwaggerSpec) {
 			if details.RequestBody != nil {
 				for contentType, mediaType := range details.RequestBody.Content {
 					fmt.Printf("  content type: %s\n", contentType)
+					if mediaType.Schema == nil {
+						continue
+					}
 					schemaName := ExtractSchemaName(mediaType.Schema.Ref, mediaType.Schema.Type)
 					fmt.Printf("  Schema: %s\n", schemaName)
-					if definition, found := swaggerSpec.Components.Schemas[schemaName]; found {
+					if definition, found := namedSchema(swaggerSpec, schemaName); found {
 						for propName, prop := range definition.Properties {
 							fmt.Printf("    - %s: %s\n", propName, prop.Type)
 						}
 						for propName, schemaProp := range mediaType.Schema.Properties {
 							fmt.Printf("    - %s: %s\n", propName, schemaProp.Type)
-							items := schemaProp.Items
-							for propName, prop := range items.Properties {
-								fmt.Printf("    - %s: %s\n", propName, prop.Type)
+							if items := schemaProp.Items; items != nil {
+								for propName, prop := range items.Properties {
+									fmt.Printf("    - %s: %s\n", propName, prop.Type)
+								}
 							}
 						}
 					} else if schemaName != "" {
@@ -103,7 +127,7 @@ func ExtractSwagger(swaggerSpec models.SwaggerSpec) {
 				fmt.Printf("  Status %s:\n", status)
 				if resp.Schema != nil {
 					schemaName := ExtractSchemaName(resp.Schema.Ref, resp.Schema.Type)
-					if definition, found := swaggerSpec.Components.Schemas[schemaName]; found {
+					if definition, found := namedSchema(swaggerSpec, schemaName); found {
 						fmt.Printf("    Schema: %s\n", schemaName)
 						for propName, prop := range definition.Properties {
 							fmt.Printf("      - %s: %s\n", propName, prop.Type)
@@ -1,41 +1,50 @@
 package swagger
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"strings"
 
 	"github.com/hrouis/swagger-mcp/app/models"
 )
 
-func LoadSwagger(specUrl string) (models.SwaggerSpec, error) {
-	var body []byte
-	var err error
+// LoadSwaggerRaw returns the spec document read from stdin, the file or URL,
+// converted to JSON when it is written in YAML.
+func LoadSwaggerRaw(specUrl string) ([]byte, error) {
+	body, err := NewSpecProvider(specUrl).(rawSource).fetchRaw(context.Background())
+	if err != nil || isJSON(body) {
+		return body, err
+	}
+	return yamlToJSON(body)
+}
 
-	if strings.HasPrefix(specUrl, "file://") {
-		filePath := strings.TrimPrefix(specUrl, "file://")
-		body, err = os.ReadFile(filePath)
-		if err != nil {
-			return models.SwaggerSpec{}, fmt.Errorf("error reading file: %v", err)
-		}
-	} else {
-		resp, err := http.Get(specUrl)
-		if err != nil {
-			return models.SwaggerSpec{}, fmt.Errorf("error getting spec: %v", err)
-		}
-		defer resp.Body.Close()
+// LoadSwagger loads the spec with the built-in provider for specUrl.
+func LoadSwagger(specUrl string) (models.SwaggerSpec, error) {
+	return NewSpecProvider(specUrl).Fetch(context.Background())
+}
 
-		body, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return models.SwaggerSpec{}, fmt.Errorf("error reading spec: %v", err)
+// ParseSwagger parses a JSON or YAML spec.
+func ParseSwagger(body []byte) (models.SwaggerSpec, error) {
+	document := body
+	if !isJSON(body) {
+		var err error
+		if body, err = yamlToJSON(body); err != nil {
+			return models.SwaggerSpec{}, err
 		}
 	}
+	body, err := downgradeOpenAPI31(body)
+	if err != nil {
+		return models.SwaggerSpec{}, err
+	}
+	if body, err = mergePathItems(body); err != nil {
+		return models.SwaggerSpec{}, err
+	}
 	var swaggerSpec models.SwaggerSpec
 	if err := json.Unmarshal(body, &swaggerSpec); err != nil {
 		return models.SwaggerSpec{}, fmt.Errorf("error parsing JSON:, %v", err.Error())
 	}
+	resolveExampleRefs(swaggerSpec)
+	mergeAllOf(swaggerSpec)
+	swaggerSpec.Document = document
 	return swaggerSpec, nil
 }
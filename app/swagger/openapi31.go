@@ -0,0 +1,170 @@
+package swagger
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaNameMaps are the keys whose mappings are keyed by names rather than
+// keywords, e.g. a property called "const" is not the const keyword.
+var schemaNameMaps = []string{"properties", "patternProperties", "$defs", "definitions", "schemas", "dependentSchemas"}
+
+// instanceKeys hold example or literal values, which are data, not schemas.
+var instanceKeys = []string{"example", "default", "enum", "const", "value"}
+
+// downgradeOpenAPI31 rewrites the JSON Schema keywords of an OpenAPI 3.1
+// document the models read in their OpenAPI 3.0 form, so its parameters and
+// properties are not lost: type arrays such as [string, "null"] become the
+// type plus nullable, anyOf/oneOf with a null member become nullable, the
+// examples array of a schema becomes its example, const becomes a single
+// value enum and contentMediaType/contentEncoding become the binary and byte
+// formats. Other documents are returned unchanged.
+func downgradeOpenAPI31(body []byte) ([]byte, error) {
+	if !bytes.Contains(body, []byte("3.1")) {
+		return body, nil
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(body, &document); err != nil || len(document.Content) == 0 {
+		// not our job to report, the parser will
+		return body, nil
+	}
+	root := document.Content[0]
+	version := mappingValue(root, "openapi")
+	if version == nil || !strings.HasPrefix(version.Value, "3.1") {
+		return body, nil
+	}
+	if err := downgradeNode(root, false, 0); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := writeYAMLNode(&out, root, 0); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// downgradeNode rewrites the schemas under node, innermost first. names tells
+// that node maps names to schemas.
+func downgradeNode(node *yaml.Node, names bool, depth int) error {
+	if depth > maxAliasDepth {
+		return fmt.Errorf("error parsing OpenAPI 3.1 document: nested too deep")
+	}
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := downgradeNode(item, false, depth+1); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, resolveAlias(node.Content[i+1])
+			if !names && (slices.Contains(instanceKeys, key) || strings.HasPrefix(key, "x-") || (key == "examples" && value.Kind == yaml.SequenceNode)) {
+				continue
+			}
+			if err := downgradeNode(value, !names && slices.Contains(schemaNameMaps, key), depth+1); err != nil {
+				return err
+			}
+		}
+		if !names {
+			downgradeSchema(node)
+		}
+	}
+	return nil
+}
+
+// downgradeSchema rewrites the 3.1 keywords of one schema.
+func downgradeSchema(schema *yaml.Node) {
+	for _, key := range []string{"anyOf", "oneOf"} {
+		variants := mappingValue(schema, key)
+		if variants == nil || variants.Kind != yaml.SequenceNode {
+			continue
+		}
+		kept := slices.DeleteFunc(slices.Clone(variants.Content), func(variant *yaml.Node) bool {
+			schemaType := mappingValue(variant, "type")
+			return schemaType != nil && schemaType.Kind == yaml.ScalarNode && schemaType.Value == "null"
+		})
+		if len(kept) == len(variants.Content) {
+			continue
+		}
+		setScalar(schema, "nullable", "true", "!!bool")
+		if len(kept) != 1 {
+			variants.Content = kept
+			continue
+		}
+		// a single variant left, e.g. anyOf: [{$ref: Pet}, {type: null}]
+		removeKey(schema, key)
+		only := resolveAlias(kept[0])
+		for i := 0; i+1 < len(only.Content); i += 2 {
+			if mappingValue(schema, only.Content[i].Value) == nil {
+				schema.Content = append(schema.Content, only.Content[i], only.Content[i+1])
+			}
+		}
+	}
+
+	if schemaType := mappingValue(schema, "type"); schemaType != nil && schemaType.Kind == yaml.SequenceNode {
+		types := []string{}
+		for _, item := range schemaType.Content {
+			if item.Value == "null" {
+				setScalar(schema, "nullable", "true", "!!bool")
+			} else {
+				types = append(types, item.Value)
+			}
+		}
+		if len(types) == 0 {
+			removeKey(schema, "type")
+		} else {
+			// the models hold one type, the first one is used
+			setScalar(schema, "type", types[0], "!!str")
+		}
+	}
+
+	if examples := mappingValue(schema, "examples"); examples != nil && examples.Kind == yaml.SequenceNode {
+		if len(examples.Content) > 0 && mappingValue(schema, "example") == nil {
+			schema.Content = append(schema.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "example"}, examples.Content[0])
+		}
+		removeKey(schema, "examples")
+	}
+
+	if constant := mappingValue(schema, "const"); constant != nil && mappingValue(schema, "enum") == nil {
+		schema.Content = append(schema.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "enum"},
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{constant}},
+		)
+	}
+
+	if mappingValue(schema, "format") == nil {
+		if encoding := mappingValue(schema, "contentEncoding"); encoding != nil && encoding.Value == "base64" {
+			setScalar(schema, "format", "byte", "!!str")
+		} else if mappingValue(schema, "contentMediaType") != nil {
+			setScalar(schema, "format", "binary", "!!str")
+		}
+	}
+}
+
+// setScalar sets key of a mapping node to a scalar value, adding it when missing.
+func setScalar(node *yaml.Node, key, value, tag string) {
+	scalar := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = scalar
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, scalar)
+}
+
+// removeKey deletes key from a mapping node.
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package swagger
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// mergePathItems moves what path items declare for all their operations into
+// each operation, so the models only see methods under a path: the shared
+// parameters, which an operation overrides by name and location, and the
+// servers of operations declaring none. Other path item fields (summary,
+// description) are dropped. Documents without such fields are returned
+// unchanged.
+func mergePathItems(body []byte) ([]byte, error) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil {
+		// not our job to report, the parser will
+		return body, nil
+	}
+	var paths map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(document["paths"], &paths); err != nil {
+		return body, nil
+	}
+	changed := false
+	for path, item := range paths {
+		shared := item["parameters"]
+		servers := item["servers"]
+		for key, value := range item {
+			if !httpMethods[strings.ToLower(key)] {
+				delete(item, key)
+				changed = true
+				continue
+			}
+			if shared == nil && servers == nil {
+				continue
+			}
+			operation, err := mergeOperation(value, shared, servers)
+			if err != nil {
+				return nil, err
+			}
+			item[key] = operation
+		}
+		paths[path] = item
+	}
+	if !changed {
+		return body, nil
+	}
+	var err error
+	if document["paths"], err = json.Marshal(paths); err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+// mergeOperation adds the shared parameters the operation does not override,
+// and the servers when it declares none.
+func mergeOperation(raw, shared, servers json.RawMessage) (json.RawMessage, error) {
+	var operation map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &operation); err != nil {
+		return raw, nil
+	}
+	if shared != nil {
+		var sharedParams, params []json.RawMessage
+		if err := json.Unmarshal(shared, &sharedParams); err != nil {
+			return raw, nil
+		}
+		if operation["parameters"] != nil {
+			if err := json.Unmarshal(operation["parameters"], &params); err != nil {
+				return raw, nil
+			}
+		}
+		declared := map[string]bool{}
+		for _, param := range params {
+			declared[parameterKey(param)] = true
+		}
+		for _, param := range sharedParams {
+			if !declared[parameterKey(param)] {
+				params = append(params, param)
+			}
+		}
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		operation["parameters"] = encoded
+	}
+	if servers != nil && operation["servers"] == nil {
+		operation["servers"] = servers
+	}
+	return json.Marshal(operation)
+}
+
+// parameterKey identifies a parameter by location and name, or by its $ref.
+func parameterKey(raw json.RawMessage) string {
+	var param struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+		Ref  string `json:"$ref"`
+	}
+	if err := json.Unmarshal(raw, &param); err != nil {
+		return string(raw)
+	}
+	if param.Ref != "" {
+		return param.Ref
+	}
+	return param.In + ":" + param.Name
+}
@@ -0,0 +1,177 @@
+package swagger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hrouis/swagger-mcp/app/models"
+)
+
+// StdinSpec is the specUrl that reads the spec from standard input.
+const StdinSpec = "-"
+
+const defaultWatchInterval = 30 * time.Second
+
+// SpecProvider supplies the spec. Embedders can implement it to load specs
+// from a database, object storage or a service registry.
+type SpecProvider interface {
+	// Fetch returns the current spec.
+	Fetch(ctx context.Context) (models.SwaggerSpec, error)
+	// Watch sends the spec to ch whenever it changes. It blocks, so run it in
+	// its own goroutine. Providers that cannot detect changes return right away.
+	Watch(ch chan<- models.SwaggerSpec)
+}
+
+// rawSource is implemented by the built-in providers, which read the spec
+// document before parsing it.
+type rawSource interface {
+	fetchRaw(ctx context.Context) ([]byte, error)
+}
+
+// NewSpecProvider returns the built-in provider for specUrl: stdin for "-",
+// a file for file:// paths and HTTP otherwise.
+func NewSpecProvider(specUrl string) SpecProvider {
+	if specUrl == StdinSpec {
+		return &StdinProvider{}
+	}
+	if strings.HasPrefix(specUrl, "file://") {
+		return &FileProvider{Path: strings.TrimPrefix(specUrl, "file://")}
+	}
+	return &HTTPProvider{URL: specUrl}
+}
+
+// HTTPProvider downloads the spec from URL and polls it every Interval when watched.
+type HTTPProvider struct {
+	URL      string
+	Client   *http.Client  // defaults to http.DefaultClient
+	Interval time.Duration // defaults to 30 seconds
+}
+
+func (p *HTTPProvider) fetchRaw(ctx context.Context) ([]byte, error) {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spec: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spec: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spec: %v", err)
+	}
+	if body, err = normalizeSpec(body, p.URL); err != nil {
+		return nil, err
+	}
+	return resolveExternalRefs(body, p.URL, client)
+}
+
+func (p *HTTPProvider) Fetch(ctx context.Context) (models.SwaggerSpec, error) {
+	return fetchSpec(ctx, p)
+}
+
+func (p *HTTPProvider) Watch(ch chan<- models.SwaggerSpec) {
+	pollSpec(p, p.Interval, ch)
+}
+
+// FileProvider reads the spec from Path and checks it every Interval when watched.
+type FileProvider struct {
+	Path     string
+	Interval time.Duration // defaults to 30 seconds
+}
+
+func (p *FileProvider) fetchRaw(ctx context.Context) ([]byte, error) {
+	body, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	if body, err = normalizeSpec(body, p.Path); err != nil {
+		return nil, err
+	}
+	return resolveExternalRefs(body, p.Path, nil)
+}
+
+func (p *FileProvider) Fetch(ctx context.Context) (models.SwaggerSpec, error) {
+	return fetchSpec(ctx, p)
+}
+
+func (p *FileProvider) Watch(ch chan<- models.SwaggerSpec) {
+	pollSpec(p, p.Interval, ch)
+}
+
+// StdinProvider reads the spec from standard input once. It never changes.
+type StdinProvider struct {
+	once sync.Once
+	body []byte
+	err  error
+}
+
+func (p *StdinProvider) fetchRaw(ctx context.Context) ([]byte, error) {
+	p.once.Do(func() {
+		p.body, p.err = io.ReadAll(os.Stdin)
+		if p.err != nil {
+			p.err = fmt.Errorf("error reading spec from stdin: %v", p.err)
+			return
+		}
+		if p.body, p.err = normalizeSpec(p.body, "stdin"); p.err == nil {
+			// relative refs are read from the working directory
+			p.body, p.err = resolveExternalRefs(p.body, "", nil)
+		}
+	})
+	return p.body, p.err
+}
+
+func (p *StdinProvider) Fetch(ctx context.Context) (models.SwaggerSpec, error) {
+	return fetchSpec(ctx, p)
+}
+
+func (p *StdinProvider) Watch(ch chan<- models.SwaggerSpec) {}
+
+func fetchSpec(ctx context.Context, source rawSource) (models.SwaggerSpec, error) {
+	body, err := source.fetchRaw(ctx)
+	if err != nil {
+		return models.SwaggerSpec{}, err
+	}
+	return parseCached(body)
+}
+
+// pollSpec reads the spec every interval and sends it to ch when the document changed.
+func pollSpec(source rawSource, interval time.Duration, ch chan<- models.SwaggerSpec) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	last, _ := source.fetchRaw(context.Background())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		body, err := source.fetchRaw(context.Background())
+		if err != nil {
+			log.Printf("Failed to poll spec: %v", err)
+			continue
+		}
+		if bytes.Equal(body, last) {
+			continue
+		}
+		spec, err := parseCached(body)
+		if err != nil {
+			log.Printf("Ignoring invalid spec update: %v", err)
+			continue
+		}
+		last = body
+		ch <- spec
+	}
+}
@@ -0,0 +1,358 @@
+package swagger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaKeys hold a schema, schemaListKeys a list of schemas.
+var (
+	schemaKeys     = map[string]bool{"schema": true, "items": true, "additionalProperties": true, "not": true}
+	schemaListKeys = map[string]bool{"allOf": true, "oneOf": true, "anyOf": true}
+)
+
+// refResolver bundles the $ref targets found in other files and at remote URLs
+// into the root document. Schemas are copied into its definitions or
+// components/schemas and referenced locally, so recursive schemas keep
+// working; other targets (paths, parameters, responses) are inlined.
+type refResolver struct {
+	client    *http.Client
+	root      string
+	documents map[string]*yaml.Node
+	bundled   map[string]string // absolute ref => local ref
+	schemas   *yaml.Node        // mapping node the schemas are bundled into
+	schemaRef string            // local ref prefix of the bundled schemas
+	inlining  []string          // targets being inlined, to detect cycles
+}
+
+// resolveExternalRefs returns the spec read from location with the $ref
+// targets of other documents bundled in. Specs without such refs are returned
+// unchanged.
+func resolveExternalRefs(body []byte, location string, client *http.Client) ([]byte, error) {
+	if !bytes.Contains(body, []byte("$ref")) {
+		return body, nil
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(body, &document); err != nil || len(document.Content) == 0 {
+		// not our job to report, the parser will
+		return body, nil
+	}
+	if !hasExternalRef(&document) {
+		return body, nil
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	root := document.Content[0]
+	r := &refResolver{
+		client:    client,
+		root:      location,
+		documents: map[string]*yaml.Node{location: root},
+		bundled:   map[string]string{},
+	}
+	if mappingValue(root, "swagger") != nil {
+		r.schemas, r.schemaRef = ensureMapping(root, "definitions"), "#/definitions/"
+	} else {
+		r.schemas, r.schemaRef = ensureMapping(ensureMapping(root, "components"), "schemas"), "#/components/schemas/"
+	}
+
+	// schemas declared as a whole file keep their name
+	for i := 0; i+1 < len(r.schemas.Content); i += 2 {
+		if target, ok := refTarget(r.schemas.Content[i+1], location); ok && !strings.HasPrefix(target, location+"#") {
+			r.bundled[target] = r.schemaRef + r.schemas.Content[i].Value
+		}
+	}
+	if err := r.walk(root, location, false); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := writeYAMLNode(&out, root, 0); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// hasExternalRef reports whether the document references another document.
+func hasExternalRef(node *yaml.Node) bool {
+	if node.Kind == yaml.MappingNode {
+		if ref := mappingValue(node, "$ref"); ref != nil && !strings.HasPrefix(ref.Value, "#") {
+			return true
+		}
+	}
+	for _, child := range node.Content {
+		if hasExternalRef(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// refTarget returns the absolute target of a $ref node read from base, as
+// location#fragment. ok is false when node is not a $ref.
+func refTarget(node *yaml.Node, base string) (target string, ok bool) {
+	ref := mappingValue(node, "$ref")
+	if ref == nil || ref.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	file, fragment, _ := strings.Cut(ref.Value, "#")
+	location := base
+	if file != "" {
+		location = resolveLocation(base, file)
+	}
+	return location + "#" + fragment, true
+}
+
+// walk bundles or inlines the external refs below node, which was read from base.
+func (r *refResolver) walk(node *yaml.Node, base string, schema bool) error {
+	node = resolveAlias(node)
+	if target, ok := refTarget(node, base); ok {
+		if fragment, local := strings.CutPrefix(target, r.root+"#"); local {
+			// refs of other documents may point back into the root document
+			setRef(node, "#"+fragment)
+			return nil
+		}
+		return r.resolve(node, target, schema)
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, resolveAlias(node.Content[i+1])
+			if key == "properties" && value.Kind == yaml.MappingNode {
+				for j := 1; j < len(value.Content); j += 2 {
+					if err := r.walk(value.Content[j], base, true); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if schemaListKeys[key] && value.Kind == yaml.SequenceNode {
+				for _, item := range value.Content {
+					if err := r.walk(item, base, true); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := r.walk(value, base, schemaKeys[key] || node == r.schemas); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, item := range node.Content {
+			if err := r.walk(item, base, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve replaces the $ref node with a local ref to the bundled schema, or
+// with the target itself.
+func (r *refResolver) resolve(node *yaml.Node, target string, schema bool) error {
+	location, fragment, _ := strings.Cut(target, "#")
+	if local, ok := r.bundled[target]; ok && !r.isEntry(node, local) {
+		setRef(node, local)
+		return nil
+	}
+	bundle := schema || strings.Contains(fragment, "/schemas/") || strings.Contains(fragment, "/definitions/")
+
+	for _, inlining := range r.inlining {
+		if inlining == target {
+			return fmt.Errorf("error resolving $ref %s: circular reference", target)
+		}
+	}
+	resolved, err := r.target(location, fragment)
+	if err != nil {
+		return err
+	}
+	copied := copyNode(resolved, 0)
+
+	if bundle && !r.isBundledEntry(node, target) {
+		name := r.schemaName(location, fragment)
+		local := r.schemaRef + name
+		r.bundled[target] = local
+		r.schemas.Content = append(r.schemas.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, copied)
+		setRef(node, local)
+		return r.walk(copied, location, true)
+	}
+
+	r.inlining = append(r.inlining, target)
+	defer func() { r.inlining = r.inlining[:len(r.inlining)-1] }()
+	if err := r.walk(copied, location, schema); err != nil {
+		return err
+	}
+	*node = *copied
+	return nil
+}
+
+// isEntry reports whether node is the schemas entry the local ref points to,
+// which must be filled in rather than point to itself.
+func (r *refResolver) isEntry(node *yaml.Node, local string) bool {
+	name := strings.TrimPrefix(local, r.schemaRef)
+	return mappingValue(r.schemas, name) == node
+}
+
+// isBundledEntry reports whether node is the schemas entry target was registered under.
+func (r *refResolver) isBundledEntry(node *yaml.Node, target string) bool {
+	local, ok := r.bundled[target]
+	return ok && r.isEntry(node, local)
+}
+
+// schemaName returns an unused schema name for a bundled target, from the last
+// segment of its fragment or the name of its file.
+func (r *refResolver) schemaName(location, fragment string) string {
+	name := path.Base(fragment)
+	if fragment == "" || name == "/" || name == "." {
+		name = strings.TrimSuffix(path.Base(location), path.Ext(location))
+	}
+	unique := name
+	for i := 2; mappingValue(r.schemas, unique) != nil; i++ {
+		unique = name + "_" + strconv.Itoa(i)
+	}
+	return unique
+}
+
+// target loads the document at location and returns the node at the JSON pointer fragment.
+func (r *refResolver) target(location, fragment string) (*yaml.Node, error) {
+	document, ok := r.documents[location]
+	if !ok {
+		body, err := r.fetch(location)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving $ref %s: %v", location, err)
+		}
+		var parsed yaml.Node
+		if err := yaml.Unmarshal(body, &parsed); err != nil {
+			return nil, fmt.Errorf("error resolving $ref %s: %v", location, err)
+		}
+		if len(parsed.Content) == 0 {
+			return nil, fmt.Errorf("error resolving $ref %s: empty document", location)
+		}
+		document = parsed.Content[0]
+		r.documents[location] = document
+	}
+	node := document
+	for _, segment := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		node = resolveAlias(node)
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, segment)
+		case yaml.SequenceNode:
+			if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("error resolving $ref %s#%s: %s not found", location, fragment, segment)
+		}
+		node = next
+	}
+	return resolveAlias(node), nil
+}
+
+// fetch reads a referenced document from a URL or a file.
+func (r *refResolver) fetch(location string) ([]byte, error) {
+	var body []byte
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		resp, err := r.client.Get(location)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if body, err = os.ReadFile(location); err != nil {
+			return nil, err
+		}
+	}
+	return normalizeSpec(body, location)
+}
+
+// resolveLocation resolves a referenced file against the location of the
+// document referencing it, a URL or a file path.
+func resolveLocation(base, ref string) string {
+	if refURL, err := url.Parse(ref); err == nil && refURL.IsAbs() {
+		return ref
+	}
+	if baseURL, err := url.Parse(base); err == nil && (baseURL.Scheme == "http" || baseURL.Scheme == "https") {
+		if refURL, err := url.Parse(ref); err == nil {
+			return baseURL.ResolveReference(refURL).String()
+		}
+	}
+	if filepath.IsAbs(ref) {
+		return filepath.Clean(ref)
+	}
+	return filepath.Join(filepath.Dir(base), ref)
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// ensureMapping returns the mapping under key, adding an empty one when missing.
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// setRef turns node into a {"$ref": ref} mapping.
+func setRef(node *yaml.Node, ref string) {
+	*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "$ref"},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: ref},
+	}}
+}
+
+// copyNode deep copies node with its aliases expanded, so a target bundled or
+// inlined twice is never shared.
+func copyNode(node *yaml.Node, depth int) *yaml.Node {
+	node = resolveAlias(node)
+	copied := *node
+	if depth > maxAliasDepth {
+		copied.Content = nil
+		return &copied
+	}
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyNode(child, depth+1)
+	}
+	return &copied
+}
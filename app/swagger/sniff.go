@@ -0,0 +1,86 @@
+package swagger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	gzipMagic  = []byte{0x1f, 0x8b}
+	htmlPage   = regexp.MustCompile(`(?i)^\s*(<!doctype\s+html|<html|<head|<body)`)
+	pageSpecRe = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)spec-url\s*=\s*["']([^"']+)["']`),     // ReDoc
+		regexp.MustCompile(`(?i)\burl\s*:\s*["']([^"']+)["']`),        // Swagger UI, FastAPI
+		regexp.MustCompile(`(?i)\bspecUrl\s*[:=]\s*["']([^"']+)["']`), // RapiDoc and others
+	}
+	// usualSpecPaths are suggested when the page does not reference its spec.
+	usualSpecPaths = []string{"/openapi.json", "/swagger.json", "/v3/api-docs", "/v2/api-docs"}
+)
+
+// normalizeSpec returns the spec document read from location, gunzipped and
+// without byte order mark. It fails with a pointer to the actual spec when
+// location serves an HTML page, such as Swagger UI, instead of the spec.
+func normalizeSpec(body []byte, location string) ([]byte, error) {
+	if bytes.HasPrefix(body, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing gzipped spec: %v", err)
+		}
+		if body, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("error decompressing gzipped spec: %v", err)
+		}
+	}
+	switch {
+	case bytes.HasPrefix(body, utf8BOM):
+		body = body[len(utf8BOM):]
+	case bytes.HasPrefix(body, []byte{0xff, 0xfe}), bytes.HasPrefix(body, []byte{0xfe, 0xff}):
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder().Bytes(body)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding UTF-16 spec: %v", err)
+		}
+		body = decoded
+	}
+	if htmlPage.Match(body) {
+		return nil, htmlSpecError(body, location)
+	}
+	return body, nil
+}
+
+// htmlSpecError tells where the spec of a documentation page is, from the URL
+// the page loads or, failing that, the usual spec paths.
+func htmlSpecError(page []byte, location string) error {
+	base, _ := url.Parse(location)
+	resolve := func(ref string) string {
+		if base == nil || (base.Scheme != "http" && base.Scheme != "https") {
+			return ref
+		}
+		refURL, err := url.Parse(ref)
+		if err != nil {
+			return ref
+		}
+		return base.ResolveReference(refURL).String()
+	}
+
+	for _, re := range pageSpecRe {
+		for _, match := range re.FindAllSubmatch(page, -1) {
+			ref := string(match[1])
+			if strings.HasSuffix(ref, ".html") || strings.HasSuffix(ref, ".js") || strings.HasSuffix(ref, ".css") {
+				continue
+			}
+			return fmt.Errorf("%s is an HTML documentation page, not a spec. The page loads its spec from %s, use that as --specUrl", location, resolve(ref))
+		}
+	}
+	suggestions := make([]string, len(usualSpecPaths))
+	for i, path := range usualSpecPaths {
+		suggestions[i] = resolve(path)
+	}
+	return fmt.Errorf("%s is an HTML page, not a JSON or YAML spec. Point --specUrl at the document the page loads, often one of %s", location, strings.Join(suggestions, ", "))
+}
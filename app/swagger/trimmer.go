@@ -0,0 +1,176 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// TrimSpec returns a minimized copy of the raw spec document keeping only the
+// operations accepted by keep, the definitions and components they reference
+// and the tags they use.
+func TrimSpec(raw []byte, keep func(path, method string) bool) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	usedTags := map[string]bool{}
+	if paths, ok := doc["paths"].(map[string]interface{}); ok {
+		for path, item := range paths {
+			pathItem, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			remaining := 0
+			for method, op := range pathItem {
+				if !httpMethods[strings.ToLower(method)] {
+					continue
+				}
+				if !keep(path, method) {
+					delete(pathItem, method)
+					continue
+				}
+				remaining++
+				if operation, ok := op.(map[string]interface{}); ok {
+					if tags, ok := operation["tags"].([]interface{}); ok {
+						for _, tag := range tags {
+							if name, ok := tag.(string); ok {
+								usedTags[name] = true
+							}
+						}
+					}
+				}
+			}
+			if remaining == 0 {
+				delete(paths, path)
+			}
+		}
+	}
+
+	// collect the references reachable from everything but the reusable sections
+	refs := map[string]bool{}
+	for key, value := range doc {
+		if key != "definitions" && key != "components" {
+			collectRefs(value, refs)
+		}
+	}
+	pending := make([]string, 0, len(refs))
+	for ref := range refs {
+		pending = append(pending, ref)
+	}
+	for len(pending) > 0 {
+		ref := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		target := resolveLocalRef(doc, ref)
+		if target == nil {
+			continue
+		}
+		found := map[string]bool{}
+		collectRefs(target, found)
+		for next := range found {
+			if !refs[next] {
+				refs[next] = true
+				pending = append(pending, next)
+			}
+		}
+	}
+
+	if definitions, ok := doc["definitions"].(map[string]interface{}); ok {
+		for name := range definitions {
+			if !isReferenced(refs, "#/definitions/"+escapeRefToken(name)) {
+				delete(definitions, name)
+			}
+		}
+	}
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		for kind, section := range components {
+			// security schemes are referenced by name from security requirements
+			if kind == "securitySchemes" {
+				continue
+			}
+			entries, ok := section.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for name := range entries {
+				if !isReferenced(refs, "#/components/"+kind+"/"+escapeRefToken(name)) {
+					delete(entries, name)
+				}
+			}
+			if len(entries) == 0 {
+				delete(components, kind)
+			}
+		}
+	}
+
+	if tags, ok := doc["tags"].([]interface{}); ok {
+		kept := []interface{}{}
+		for _, tag := range tags {
+			if t, ok := tag.(map[string]interface{}); ok {
+				if name, _ := t["name"].(string); !usedTags[name] {
+					continue
+				}
+			}
+			kept = append(kept, tag)
+		}
+		doc["tags"] = kept
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func collectRefs(value interface{}, refs map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" && strings.HasPrefix(ref, "#/") {
+				refs[ref] = true
+				continue
+			}
+			collectRefs(child, refs)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+// resolveLocalRef follows a local JSON pointer such as #/components/schemas/User.
+func resolveLocalRef(doc map[string]interface{}, ref string) interface{} {
+	var current interface{} = doc
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if current, ok = obj[token]; !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+// isReferenced reports whether pointer or anything below it is referenced.
+func isReferenced(refs map[string]bool, pointer string) bool {
+	if refs[pointer] {
+		return true
+	}
+	for ref := range refs {
+		if strings.HasPrefix(ref, pointer+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeRefToken(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,149 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxAliasDepth stops recursive anchors from expanding forever.
+const maxAliasDepth = 100
+
+// isJSON reports whether the spec document is JSON rather than YAML.
+func isJSON(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// yamlToJSON converts a YAML spec to JSON, resolving anchors, aliases and
+// merge keys. Mapping keys keep their order so schema properties stay ordered.
+func yamlToJSON(body []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	var out bytes.Buffer
+	if err := writeYAMLNode(&out, &document, 0); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// yamlPair is a mapping entry after merge keys are applied.
+type yamlPair struct {
+	key   string
+	value *yaml.Node
+}
+
+// mappingPairs returns the entries of a mapping node. Entries merged with <<
+// take the place of the merge key and never override the explicit entries;
+// of several merged mappings the first one wins.
+func mappingPairs(node *yaml.Node, depth int) ([]yamlPair, error) {
+	if depth > maxAliasDepth {
+		return nil, fmt.Errorf("error parsing YAML: anchors nested too deep")
+	}
+	explicit := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag != "!!merge" {
+			explicit[node.Content[i].Value] = true
+		}
+	}
+	pairs := []yamlPair{}
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], resolveAlias(node.Content[i+1])
+		if key.Tag != "!!merge" {
+			pairs = append(pairs, yamlPair{key: key.Value, value: value})
+			seen[key.Value] = true
+			continue
+		}
+		merged := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			merged = value.Content
+		}
+		for _, mapping := range merged {
+			mapping = resolveAlias(mapping)
+			if mapping.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("error parsing YAML: line %d: merge key needs a mapping", mapping.Line)
+			}
+			mergedPairs, err := mappingPairs(mapping, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			for _, pair := range mergedPairs {
+				if !explicit[pair.key] && !seen[pair.key] {
+					pairs = append(pairs, pair)
+					seen[pair.key] = true
+				}
+			}
+		}
+	}
+	return pairs, nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func writeYAMLNode(out *bytes.Buffer, node *yaml.Node, depth int) error {
+	if depth > maxAliasDepth {
+		return fmt.Errorf("error parsing YAML: document nested too deep")
+	}
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			out.WriteString("null")
+			return nil
+		}
+		return writeYAMLNode(out, node.Content[0], depth+1)
+	case yaml.MappingNode:
+		pairs, err := mappingPairs(node, 0)
+		if err != nil {
+			return err
+		}
+		out.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			key, _ := json.Marshal(pair.key)
+			out.Write(key)
+			out.WriteByte(':')
+			if err := writeYAMLNode(out, pair.value, depth+1); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	case yaml.SequenceNode:
+		out.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeYAMLNode(out, item, depth+1); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case yaml.ScalarNode:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("error parsing YAML: line %d: %v", node.Line, err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			// e.g. timestamps and binary data, keep them as written
+			data, _ = json.Marshal(node.Value)
+		}
+		out.Write(data)
+	default:
+		out.WriteString("null")
+	}
+	return nil
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	mcpserver "github.com/hrouis/swagger-mcp/app/mcp-server"
+	"github.com/hrouis/swagger-mcp/app/mock"
 	"github.com/hrouis/swagger-mcp/app/models"
 	"github.com/hrouis/swagger-mcp/app/swagger"
 )
@@ -58,23 +61,216 @@ func getSseUrlAddr(sseUrl, sseAddr string) (string, string) {
 	return "", ""
 }
 
+// loadRoutes reads the base URL routing table from a JSON file
+func loadRoutes(routesFile string) []models.RouteConfig {
+	if routesFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(routesFile)
+	if err != nil {
+		log.Fatalf("Failed to read routes file: %v", err)
+	}
+	problems, err := models.ValidateRoutes(data)
+	if err != nil {
+		log.Fatalf("Failed to validate routes file: %v", err)
+	}
+	if len(problems) > 0 {
+		log.Fatalf("Invalid routes file %s:\n  %s", routesFile, strings.Join(problems, "\n  "))
+	}
+	var routes []models.RouteConfig
+	if err := json.Unmarshal(data, &routes); err != nil {
+		log.Fatalf("Invalid routes file: %v", err)
+	}
+	return routes
+}
+
+// runExportSpec writes the spec trimmed down to the operations that survive filtering
+func runExportSpec(config models.Config, out string) {
+	raw, err := swagger.LoadSwaggerRaw(config.SpecUrl)
+	if err != nil {
+		log.Fatalf("Failed to load Swagger spec: %v", err)
+	}
+	swaggerSpec, err := swagger.ParseSwagger(raw)
+	if err != nil {
+		log.Fatalf("Failed to parse Swagger spec: %v", err)
+	}
+	trimmed, err := swagger.TrimSpec(raw, mcpserver.OperationFilter(config.ApiCfg, swaggerSpec))
+	if err != nil {
+		log.Fatalf("Failed to trim Swagger spec: %v", err)
+	}
+	writeOutput(out, trimmed)
+}
+
+// writeOutput writes data to the out file, or to stdout when out is empty
+func writeOutput(out string, data []byte) {
+	if out == "" {
+		os.Stdout.Write(append(data, '\n'))
+		return
+	}
+	if err := os.WriteFile(out, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", out, err)
+	}
+}
+
+// runStats prints the usage stats file.
+func runStats(path string, anonymize bool) {
+	if path == "" {
+		log.Fatal("Please provide the stats file using the --statsFile flag")
+	}
+	stats, err := mcpserver.ReadUsageStats(path)
+	if err != nil {
+		log.Fatalf("Failed to read usage stats: %v", err)
+	}
+	mcpserver.WriteUsageStats(os.Stdout, stats, anonymize)
+}
+
+// runKeys issues, revokes or lists the client keys in the key file.
+func runKeys(action, path, client string, args []string) {
+	if path == "" {
+		log.Fatal("Please provide the client key file using the --clientKeys flag")
+	}
+	switch action {
+	case "issue":
+		key, secret, err := mcpserver.IssueClientKey(path, client)
+		if err != nil {
+			log.Fatalf("Failed to issue client key: %v", err)
+		}
+		fmt.Printf("Issued key %s for client %s. It is only shown once:\n%s\n", key.ID, key.Client, secret)
+	case "revoke":
+		if len(args) != 1 {
+			log.Fatal("Usage: keys revoke --clientKeys=<file> <key id or client name>")
+		}
+		revoked, err := mcpserver.RevokeClientKeys(path, args[0])
+		if err != nil {
+			log.Fatalf("Failed to revoke client key: %v", err)
+		}
+		for _, key := range revoked {
+			fmt.Printf("Revoked key %s of client %s\n", key.ID, key.Client)
+		}
+	case "list":
+		keys, err := mcpserver.ListClientKeys(path)
+		if err != nil {
+			log.Fatalf("Failed to list client keys: %v", err)
+		}
+		for _, key := range keys {
+			status := "active"
+			if key.Revoked != nil {
+				status = "revoked " + key.Revoked.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", key.ID, key.Client, key.Created.Format(time.RFC3339), status)
+		}
+	default:
+		log.Fatalf("Unknown keys command %q, use issue, revoke or list", action)
+	}
+}
+
 func main() {
 	var finalSseUrl, finalSseAddr string
-	specUrl := flag.String("specUrl", "", "URL of the Swagger JSON specification")
+	specUrl := flag.String("specUrl", "", "URL of the Swagger JSON specification, file:// path, or - to read it from stdin")
 	sseMode := flag.Bool("sse", false, "Run in SSE mode instead of stdio mode")
 	sseAddr := flag.String("sseAddr", "", "SSE server listen address in :Port or IP:Port format")
 	sseUrl := flag.String("sseUrl", "", "Base URL for the SSE server")
+	splitScopes := flag.Bool("splitScopes", false, "In SSE mode, serve read-only tools on /mcp/read and mutating tools on /mcp/write")
+	adminToken := flag.String("adminToken", "", "In SSE mode, serve the /admin/tools API switching tools on and off, authenticated with this bearer token")
+	clientKeys := flag.String("clientKeys", "", "In SSE mode, file of the per-client keys every request must carry as a bearer token or X-API-Key; manage it with the keys command")
+	keyClient := flag.String("client", "", "Client name of the key issued by keys issue")
 	baseUrl := flag.String("baseUrl", "", "Base URL for API requests")
 	includePaths := flag.String("includePaths", "", "Comma-separated list of paths or regex to include")
 	excludePaths := flag.String("excludePaths", "", "Comma-separated list of paths or regex to exclude")
 	includeMethods := flag.String("includeMethods", "", "Comma-separated list of HTTP methods to include")
 	excludeMethods := flag.String("excludeMethods", "", "Comma-separated list of HTTP methods to exclude")
-	security := flag.String("security", "", "API security type: basic, apiKey, or bearer")
+	includeOperations := flag.String("includeOperations", "", "Comma-separated list of operationId regex to include")
+	excludeOperations := flag.String("excludeOperations", "", "Comma-separated list of operationId regex to exclude")
+	includeTools := flag.String("includeTools", "", "Comma-separated list of exact tool names to always include")
+	excludeTools := flag.String("excludeTools", "", "Comma-separated list of exact tool names to always exclude")
+	security := flag.String("security", "", "API security type: basic, apiKey, bearer, negotiate, or ntlm; comma-separate types sent together, e.g. apiKey,bearer")
 	basicAuth := flag.String("basicAuth", "", "Basic auth credentials in user:password format, used in Authorization header")
 	bearerAuth := flag.String("bearerAuth", "", "Bearer token for Authorization header")
+	negotiateSpn := flag.String("negotiateSpn", "", "Service principal name for negotiate (Kerberos/SPNEGO) auth, e.g. HTTP/api.corp.example.com")
+	ntlmAuth := flag.String("ntlmAuth", "", "NTLM credentials in DOMAIN\\user:password format")
 	apiKeyAuth := flag.String("apiKeyAuth", "", "API key auth, format: 'passAs:name=value', passAs=header/query/cookie, multiple by comma")
 	headers := flag.String("headers", "", "Additional headers to include in requests (format: name1=value1,name2=value2)")
 	sseHeaders := flag.String("sseHeaders", "", "Read headers from sse request, and pass to API request (format: name1,name2)")
+	orderedBody := flag.Bool("orderedBody", false, "Send request body fields in the order they are declared in the schema")
+	omitEmptyBody := flag.Bool("omitEmptyBody", false, "Omit empty or null optional request body fields instead of sending them")
+	vendorBackends := flag.Bool("vendorBackends", false, "Send requests to the backend declared by x-google-backend or x-amazon-apigateway-integration")
+	historyDb := flag.String("historyDb", "", "Bolt database file recording tool results, enables the query_history tool")
+	sessionVariables := flag.Bool("sessionVariables", false, "Add set_variable/get_variable tools, saved values are referenced as {{name}} in tool arguments")
+	captureRules := flag.String("captureRules", "", "Save values from tool results as session variables (format: [tool:]$.path=name, comma separated)")
+	maxCalls := flag.Int("maxCalls", 0, "Maximum number of tool calls per MCP session (0 for unlimited)")
+	maxMutatingCalls := flag.Int("maxMutatingCalls", 0, "Maximum number of POST/PUT/PATCH/DELETE tool calls per MCP session (0 for unlimited)")
+	scrubResponses := flag.Bool("scrubResponses", false, "Remove instruction-like content (e.g. \"ignore previous instructions\") from API responses")
+	scrubPatterns := flag.String("scrubPatterns", "", "Extra comma-separated regexes to remove from API responses, implies --scrubResponses")
+	etagCache := flag.Bool("etagCache", false, "Cache GET responses with ETag/Last-Modified and revalidate them with conditional requests")
+	downloadRoots := flag.String("downloadRoots", "", "Comma-separated directories or file:// roots shared with the client, binary responses are saved there")
+	downloadThreshold := flag.Int("downloadThreshold", 0, "Also save responses larger than this many bytes to the download roots (0 for binary only)")
+	headerCaptures := flag.String("headerCaptures", "", "Response headers to copy into tool results or session variables (format: [pathRegex:]Header[=variable], comma separated)")
+	paramAliases := flag.String("paramAliases", "", "Tool argument names for awkward parameter names (format: X-Correlation-ID=correlation_id, comma separated)")
+	progressInterval := flag.Int("progressInterval", 2, "Seconds between progress notifications sent while a tool call is running (0 to disable)")
+	toolManifest := flag.String("toolManifest", "", "Approved tool manifest from export-manifest, other tools or changed definitions are refused")
+	manifestKey := flag.String("manifestKey", "", "HMAC key to sign the tool manifest with (export-manifest) or to verify it against")
+	apiVersion := flag.String("apiVersion", "", "Only expose operations under this version path segment (e.g. v2), unversioned paths are kept")
+	versionedTools := flag.Bool("versionedTools", false, "Suffix tool names with the version path segment and link the other versions of each operation")
+	derivedFields := flag.String("derivedFields", "", "Fields computed from JSON responses, e.g. count=len($.items) (format: [tool:]name=expression, comma separated)")
+	validateBody := flag.Bool("validateBody", false, "Check request bodies against the schema types, required fields, enums and patterns before sending them")
+	maintenanceWindows := flag.String("maintenanceWindows", "", "Freeze periods refusing mutating tools, e.g. \"0 18 * * 5 60h\" (format: [CRON_TZ=zone] minute hour day month weekday duration, semicolon separated)")
+	sensitiveParams := flag.String("sensitiveParams", "", "Comma-separated parameter and body field names holding secrets, on top of those of format password")
+	pollingDiff := flag.Bool("pollingDiff", false, "Answer repeated identical GET calls with \"unchanged\" or the changed fields instead of the whole result")
+	docResources := flag.Bool("docResources", false, "Serve the documentation of each operation, with example responses, as an MCP resource")
+	playbooks := flag.String("playbooks", "", "Directory of <tag>.md markdown playbooks (call order, pagination, gotchas) served as MCP prompts and resources")
+	latencySlo := flag.Int("latencySlo", 0, "p95 latency target of the tools in milliseconds, results warn when a backend consistently exceeds it (0 to disable)")
+	urlRewrites := flag.String("urlRewrites", "", "Regex rewrites of the request URL applied in order, e.g. \"^(https?://[^/]+)/=>$1/api/\" (format: pattern=>replacement, semicolon separated)")
+	elevatedTools := flag.String("elevatedTools", "", "Comma-separated regexes of tool names only available to sessions an admin elevates through the admin API, e.g. ^(post|delete)__admin")
+	serializeCalls := flag.String("serializeCalls", "", "Operations whose calls must run one at a time, e.g. \"PUT /config,/accounts/{id}\" (format: [METHOD ]path[=key], comma separated, {name} in the key is replaced with the argument)")
+	provenance := flag.Bool("provenance", false, "Attach provenance metadata (source URL, timestamp, status, cache hit or live, spec version) to every tool result")
+	requestTimeout := flag.Int("requestTimeout", 0, "Seconds an API call may take; when a response is still streaming then, the part received so far is returned marked partial (0 to disable)")
+	metricsAddr := flag.String("metricsAddr", "", "Serve OpenMetrics counters of the tool calls by backend host and response status class on this address at /metrics, e.g. :9090")
+	maxBodySize := flag.String("maxBodySize", "", "Largest request body a tool may send, e.g. 512KB or 10MB; larger bodies are refused before the request (no limit when empty)")
+	bodySizeLimits := flag.String("bodySizeLimits", "", "Per operation body size limits overriding --maxBodySize, e.g. \"POST /uploads=50MB,/comments=4KB\" (format: [METHOD ]path=size, comma separated, 0 for no limit)")
+	timezone := flag.String("timezone", "", "IANA timezone, e.g. Europe/Paris, of date and date-time arguments given without an offset or as epoch seconds (default UTC)")
+	statsFile := flag.String("statsFile", "", "Opt-in local usage stats file: tool call counts, outcomes, latency and spec size, never arguments or responses; print it with the stats command")
+	anonymize := flag.Bool("anonymize", false, "Replace tool names with hashes in the output of the stats command, to share it")
+	operationIdNames := flag.Bool("operationIdNames", false, "Name tools after the operationId of their operation (sanitized, at most 64 characters), method_path when it has none")
+	toolNameTemplate := flag.String("toolNameTemplate", "", "Go template of the tool names, e.g. \"{{.Tag}}_{{.OperationId}}\", over .Method, .Path, .OperationId, .Tag, .Version and .Default (the method_path name)")
+	failoverUrls := flag.String("failoverUrls", "", "Base URLs to fail over to, in order, when a connection to the base URL fails, e.g. https://eu.api.example.com,https://us.api.example.com, or servers for all the servers of the spec")
+	requestLog := flag.Int("requestLog", 0, "Serve the last N requests and responses of the tools, redacted, as the swagger-mcp://requests resource (0 to disable)")
+	specCache := flag.String("specCache", "", "Directory keeping parsed specs by document hash, so restarts with an unchanged spec skip parsing it")
+	schemaResources := flag.Bool("schemaResources", false, "Serve each schema of the spec as a swagger-mcp://schemas/<name> resource and point the tool descriptions to the ones they use")
+	contentHash := flag.Bool("contentHash", false, "Add a content_hash of the response to tool results, and the watch_endpoint tool notifying the client when the response of a GET tool changes")
+	specResources := flag.Bool("specResources", false, "Serve the spec document the tools were generated from, and the spec as parsed, as the swagger-mcp://spec and swagger-mcp://spec/resolved resources")
+	examplePrompts := flag.Bool("examplePrompts", false, "Serve an example_<tool> prompt for each operation documenting request body examples, with the tool arguments pre-filled from them")
+	existenceCheck := flag.Bool("existenceCheck", false, "GET the resource before a DELETE or PUT on the same path and fail when it does not exist")
+	csrfTokenUrl := flag.String("csrfTokenUrl", "", "Endpoint returning the anti-CSRF token sent with mutating calls")
+	csrfCookie := flag.String("csrfCookie", "", "Cookie holding the anti-CSRF token")
+	csrfHeader := flag.String("csrfHeader", "", "Header to send the anti-CSRF token in (default X-CSRF-Token)")
+	csrfBodyField := flag.String("csrfBodyField", "", "Request body field to also send the anti-CSRF token in")
+	routesFile := flag.String("routesFile", "", "JSON file mapping path prefixes or tags to base URLs and credentials")
+
+	exportOut := flag.String("out", "", "Output file for export-spec and export-manifest (default stdout)")
+	testSuite := flag.String("suite", "", "YAML test suite file for the test command")
+	mockAddr := flag.String("mockAddr", "localhost:8081", "Listen address for the mock-backend command")
+
+	// subcommands: export-spec writes the filtered spec, test runs a test suite against the tools,
+	// mock-backend serves example responses for the spec, export-manifest writes the tool manifest,
+	// keys issue|revoke|list manages the client keys of --clientKeys, stats prints the --statsFile
+	command := ""
+	if len(os.Args) > 1 && (os.Args[1] == "export-spec" || os.Args[1] == "test" || os.Args[1] == "mock-backend" || os.Args[1] == "export-manifest") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		flag.Parse()
+		runStats(*statsFile, *anonymize)
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "keys" {
+		action := os.Args[2]
+		os.Args = append(os.Args[:1], os.Args[3:]...)
+		flag.Parse()
+		runKeys(action, *clientKeys, *keyClient, flag.Args())
+		return
+	}
 
 	flag.Parse()
 
@@ -93,49 +289,162 @@ func main() {
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
 			log.Fatalf("Spec file does not exist: %v", err)
 		}
+	} else if *specUrl == swagger.StdinSpec {
+		if command == "" && !*sseMode {
+			log.Fatal("Reading the spec from stdin (--specUrl=-) requires --sse, stdio mode uses stdin for MCP messages")
+		}
 	} else {
-		log.Fatal("Invalid specUrl format. Must be a valid HTTP URL or file:// path")
+		log.Fatal("Invalid specUrl format. Must be a valid HTTP URL, file:// path or - for stdin")
 	}
 
 	// Validate baseUrl
 	if *baseUrl != "" {
-		if !strings.HasPrefix(*baseUrl, "http://") && !strings.HasPrefix(*baseUrl, "https://") {
-			log.Fatal("baseUrl must start with http:// or https://")
+		if !strings.HasPrefix(*baseUrl, "http://") && !strings.HasPrefix(*baseUrl, "https://") && !mcpserver.IsDiscoveryURL(*baseUrl) {
+			log.Fatal("baseUrl must start with http://, https://, k8s:// or consul://")
 		}
 	}
 
 	if *sseMode { // get final sseAddr and sseUrl
 		finalSseUrl, finalSseAddr = getSseUrlAddr(*sseUrl, *sseAddr)
 	}
-	swaggerSpec, err := swagger.LoadSwagger(*specUrl)
-	if err != nil {
-		log.Fatalf("Failed to load Swagger spec: %v", err)
-	}
-	swagger.ExtractSwagger(swaggerSpec)
-
 	config := models.Config{
 		SpecUrl: *specUrl,
 		SseCfg: models.SseConfig{
 			SseMode: *sseMode,
 			SseAddr: finalSseAddr,
 			SseUrl:  finalSseUrl,
+
+			SplitScopes: *splitScopes,
+			AdminToken:  *adminToken,
+			ClientKeys:  *clientKeys,
 		},
 		ApiCfg: models.ApiConfig{
-			BaseUrl:        *baseUrl,
-			IncludePaths:   *includePaths,
-			ExcludePaths:   *excludePaths,
-			IncludeMethods: *includeMethods,
-			ExcludeMethods: *excludeMethods,
-			Security:       *security,
-			BasicAuth:      *basicAuth,
-			ApiKeyAuth:     *apiKeyAuth,
-			BearerAuth:     *bearerAuth,
-			Headers:        *headers,
-			SseHeaders:     *sseHeaders,
+			BaseUrl:            *baseUrl,
+			IncludePaths:       *includePaths,
+			ExcludePaths:       *excludePaths,
+			IncludeMethods:     *includeMethods,
+			ExcludeMethods:     *excludeMethods,
+			IncludeOperations:  *includeOperations,
+			ExcludeOperations:  *excludeOperations,
+			IncludeTools:       *includeTools,
+			ExcludeTools:       *excludeTools,
+			Security:           *security,
+			BasicAuth:          *basicAuth,
+			ApiKeyAuth:         *apiKeyAuth,
+			BearerAuth:         *bearerAuth,
+			NegotiateSpn:       *negotiateSpn,
+			NtlmAuth:           *ntlmAuth,
+			Headers:            *headers,
+			SseHeaders:         *sseHeaders,
+			OrderedBody:        *orderedBody,
+			OmitEmptyBody:      *omitEmptyBody,
+			VendorBackends:     *vendorBackends,
+			HistoryDb:          *historyDb,
+			SessionVariables:   *sessionVariables,
+			CaptureRules:       *captureRules,
+			MaxCalls:           *maxCalls,
+			MaxMutatingCalls:   *maxMutatingCalls,
+			ScrubResponses:     *scrubResponses || *scrubPatterns != "",
+			ScrubPatterns:      *scrubPatterns,
+			EtagCache:          *etagCache,
+			DownloadRoots:      *downloadRoots,
+			DownloadThreshold:  *downloadThreshold,
+			HeaderCaptures:     *headerCaptures,
+			ParamAliases:       *paramAliases,
+			ProgressInterval:   *progressInterval,
+			ToolManifest:       *toolManifest,
+			ManifestKey:        *manifestKey,
+			ApiVersion:         *apiVersion,
+			VersionedTools:     *versionedTools,
+			ExistenceCheck:     *existenceCheck,
+			DerivedFields:      *derivedFields,
+			ValidateBody:       *validateBody,
+			SensitiveParams:    *sensitiveParams,
+			PollingDiff:        *pollingDiff,
+			DocResources:       *docResources,
+			Playbooks:          *playbooks,
+			LatencySlo:         *latencySlo,
+			UrlRewrites:        *urlRewrites,
+			ElevatedTools:      *elevatedTools,
+			SerializeCalls:     *serializeCalls,
+			Provenance:         *provenance,
+			RequestTimeout:     *requestTimeout,
+			MetricsAddr:        *metricsAddr,
+			MaintenanceWindows: *maintenanceWindows,
+			MaxBodySize:        *maxBodySize,
+			BodySizeLimits:     *bodySizeLimits,
+			StatsFile:          *statsFile,
+			Timezone:           *timezone,
+			OperationIdNames:   *operationIdNames,
+			ToolNameTemplate:   *toolNameTemplate,
+			RequestLog:         *requestLog,
+			FailoverUrls:       *failoverUrls,
+			SchemaResources:    *schemaResources,
+			ContentHash:        *contentHash,
+			SpecResources:      *specResources,
+			ExamplePrompts:     *examplePrompts,
+			Routes:             loadRoutes(*routesFile),
+			CsrfTokenUrl:       *csrfTokenUrl,
+			CsrfCookie:         *csrfCookie,
+			CsrfHeader:         *csrfHeader,
+			CsrfBodyField:      *csrfBodyField,
 		},
 	}
 
+	if command == "export-spec" {
+		runExportSpec(config, *exportOut)
+		return
+	}
+
+	swagger.SetSpecCache(*specCache)
+	swaggerSpec, err := swagger.LoadSwagger(*specUrl)
+	if err != nil {
+		log.Fatalf("Failed to load Swagger spec: %v", err)
+	}
+
+	if command == "mock-backend" {
+		fmt.Printf("Mock backend base URL: %s\n", mock.URL(swaggerSpec, *mockAddr))
+		if err := mock.Serve(swaggerSpec, *mockAddr); err != nil {
+			log.Fatalf("Mock backend error: %v", err)
+		}
+		return
+	}
+
+	if command == "export-manifest" {
+		// keep the tool loading output out of the manifest written to stdout
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		manifest, err := mcpserver.ExportToolManifest(swaggerSpec, config.ApiCfg, *manifestKey)
+		os.Stdout = stdout
+		if err != nil {
+			log.Fatalf("Failed to export tool manifest: %v", err)
+		}
+		writeOutput(*exportOut, manifest)
+		return
+	}
+
+	if command == "test" {
+		if *testSuite == "" {
+			log.Fatal("Please provide the test suite file using the --suite flag")
+		}
+		failed, err := mcpserver.RunTestSuite(swaggerSpec, config.ApiCfg, *testSuite, os.Stdout)
+		if err != nil {
+			log.Fatalf("Failed to run test suite: %v", err)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	if !config.SseCfg.SseMode {
+		// the stdio transport owns stdout from here on
+		if err := mcpserver.GuardStdout(); err != nil {
+			log.Fatalf("Error starting stdio server: %v", err)
+		}
+	}
+	swagger.ExtractSwagger(swaggerSpec)
+
 	fmt.Printf("Starting server with specUrl: %s, SSE mode: %v, SSE URL: %s, SSE Addr: %s, Base URL: %s, Include Paths: %s, Exclude Paths: %s, Include Methods: %s, Exclude Methods: %s, Security: %s, BasicAuth: %s, ApiKeyAuth: %s, BearerAuth: %s, Headers: %s, SSE Headers: %s\n",
-		config.SpecUrl, config.SseCfg.SseMode, config.SseCfg.SseUrl, config.SseCfg.SseAddr, config.ApiCfg.BaseUrl, config.ApiCfg.IncludePaths, config.ApiCfg.ExcludePaths, config.ApiCfg.IncludeMethods, config.ApiCfg.ExcludeMethods, config.ApiCfg.Security, config.ApiCfg.BasicAuth, config.ApiCfg.ApiKeyAuth, config.ApiCfg.BearerAuth, config.ApiCfg.Headers, config.ApiCfg.SseHeaders)
+		config.SpecUrl, config.SseCfg.SseMode, config.SseCfg.SseUrl, config.SseCfg.SseAddr, config.ApiCfg.BaseUrl, config.ApiCfg.IncludePaths, config.ApiCfg.ExcludePaths, config.ApiCfg.IncludeMethods, config.ApiCfg.ExcludeMethods, config.ApiCfg.Security, mcpserver.RedactCredential(config.ApiCfg.BasicAuth), mcpserver.RedactPairs(config.ApiCfg.ApiKeyAuth), mcpserver.RedactCredential(config.ApiCfg.BearerAuth), mcpserver.RedactPairs(config.ApiCfg.Headers), config.ApiCfg.SseHeaders)
 	mcpserver.CreateServer(swaggerSpec, config)
 }